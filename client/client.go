@@ -0,0 +1,270 @@
+// Package client is a Go client of the orders REST API.
+//
+// Reads, updates and deletes are retried with backoff when the request
+// fails in transit or the server is unavailable. Creates and patches are
+// retried too, because every one of them carries an Idempotency-Key the
+// server uses to run it at most once; pass your own key with
+// WithIdempotencyKey to make a retry of your own safe as well.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/pkg"
+)
+
+// Config describes how to reach the API.
+type Config struct {
+	// BaseURL is the root of the API, such as http://localhost:3000/api/v1.
+	BaseURL string
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+	// Tenant is sent as the X-Tenant-ID header when set. Keys bound to a
+	// tenant need none.
+	Tenant string
+	// HTTPClient defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+	// MaxRetries is how many times a failed request is retried, 3 by
+	// default. A negative value disables retries.
+	MaxRetries int
+	// Backoff spaces retries, from 200ms up to 5s by default.
+	Backoff pkg.Backoff
+}
+
+type Client interface {
+	// List returns an iterator over the orders that match opts.
+	List(ctx context.Context, opts ListOptions) *OrderIterator
+	Get(ctx context.Context, id uint64) (Order, error)
+	Create(ctx context.Context, order Order) (Order, error)
+	// Update replaces an order. Items without an ID are added, and stored
+	// items left out are removed.
+	Update(ctx context.Context, id uint64, order Order) (Order, error)
+	Patch(ctx context.Context, id uint64, patch []byte, typ PatchType) (Order, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+type clientImpl struct {
+	baseURL    string
+	apiKey     string
+	tenant     string
+	http       *http.Client
+	maxRetries int
+	backoff    pkg.Backoff
+}
+
+func New(cfg Config) Client {
+	c := &clientImpl{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:     cfg.APIKey,
+		tenant:     cfg.Tenant,
+		http:       cfg.HTTPClient,
+		maxRetries: cfg.MaxRetries,
+		backoff:    cfg.Backoff,
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: 30 * time.Second}
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = 3
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	if c.backoff == (pkg.Backoff{}) {
+		c.backoff = pkg.Backoff{Initial: 200 * time.Millisecond, Max: 5 * time.Second}
+	}
+	return c
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes the create or patch called with ctx send key as
+// its Idempotency-Key. Calls with the same key run at most once within the
+// server's retention period, so a call that failed with an unknown outcome
+// can be repeated safely. Without a key, each call gets a random one.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+func (c *clientImpl) List(ctx context.Context, opts ListOptions) *OrderIterator {
+	return &OrderIterator{ctx: ctx, c: c, opts: opts}
+}
+
+func (c *clientImpl) Get(ctx context.Context, id uint64) (Order, error) {
+	order := Order{}
+	err := c.do(ctx, request{method: "GET", path: orderPath(id)}, &order)
+	return order, err
+}
+
+func (c *clientImpl) Create(ctx context.Context, order Order) (Order, error) {
+	body, err := json.Marshal(order)
+	if err != nil {
+		return Order{}, err
+	}
+	res := Order{}
+	err = c.do(ctx, request{method: "POST", path: "/orders", body: body, contentType: "application/json", idempotencyKey: keyFrom(ctx)}, &res)
+	return res, err
+}
+
+func (c *clientImpl) Update(ctx context.Context, id uint64, order Order) (Order, error) {
+	body, err := json.Marshal(order)
+	if err != nil {
+		return Order{}, err
+	}
+	res := Order{}
+	err = c.do(ctx, request{method: "PUT", path: orderPath(id), body: body, contentType: "application/json"}, &res)
+	return res, err
+}
+
+func (c *clientImpl) Patch(ctx context.Context, id uint64, patch []byte, typ PatchType) (Order, error) {
+	res := Order{}
+	err := c.do(ctx, request{method: "PATCH", path: orderPath(id), body: patch, contentType: string(typ), idempotencyKey: keyFrom(ctx)}, &res)
+	return res, err
+}
+
+func (c *clientImpl) Delete(ctx context.Context, id uint64) error {
+	return c.do(ctx, request{method: "DELETE", path: orderPath(id)}, nil)
+}
+
+func orderPath(id uint64) string {
+	return "/orders/" + strconv.FormatUint(id, 10)
+}
+
+func keyFrom(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKey{}).(string); ok && key != "" {
+		return key
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type request struct {
+	method      string
+	path        string
+	body        []byte
+	contentType string
+	// idempotencyKey makes a POST or PATCH safe to retry.
+	idempotencyKey string
+}
+
+// retryable reports whether r may be sent again after an unknown outcome.
+func (r request) retryable() bool {
+	switch r.method {
+	case "GET", "PUT", "DELETE":
+		return true
+	default:
+		return r.idempotencyKey != ""
+	}
+}
+
+// do sends r, retrying when allowed, and decodes a successful response
+// into dst unless dst is nil.
+func (c *clientImpl) do(ctx context.Context, r request, dst any) error {
+	for attempt := 0; ; attempt++ {
+		res, err := c.send(ctx, r)
+		if err == nil && res.StatusCode < 300 {
+			defer res.Body.Close()
+			if dst == nil {
+				return nil
+			}
+			if err := json.NewDecoder(res.Body).Decode(dst); err != nil {
+				return fmt.Errorf("orders api: decode response: %w", err)
+			}
+			return nil
+		}
+
+		wait := c.backoff.Duration(attempt)
+		if err == nil {
+			err = decodeError(res)
+			if !retryStatus(res.StatusCode) {
+				return err
+			}
+			if d := retryAfter(res); d > wait {
+				wait = d
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= c.maxRetries || !r.retryable() {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *clientImpl) send(ctx context.Context, r request) (*http.Response, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, c.baseURL+r.path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if r.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", r.idempotencyKey)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.tenant != "" {
+		req.Header.Set("X-Tenant-ID", c.tenant)
+	}
+	return c.http.Do(req)
+}
+
+func retryStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads the delay of a Retry-After header given in seconds.
+func retryAfter(res *http.Response) time.Duration {
+	secs, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// decodeError reads the pkg.ErrorResponse of a failed request and closes
+// the body.
+func decodeError(res *http.Response) error {
+	defer res.Body.Close()
+	apiErr := &APIError{StatusCode: res.StatusCode}
+	body := pkg.ErrorResponse{}
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err := json.Unmarshal(data, &body); err == nil && body.Message != "" {
+		apiErr.Message = body.Message
+		apiErr.Errors = body.Errors
+	} else {
+		apiErr.Message = http.StatusText(res.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/client"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastBackoff = pkg.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+func newClient(t *testing.T, h http.HandlerFunc) client.Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return client.New(client.Config{BaseURL: srv.URL + "/api/v1", APIKey: "k1", Tenant: "shop-a", Backoff: fastBackoff})
+}
+
+func TestList(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/orders", r.URL.Path)
+		assert.Equal(t, "paid", r.URL.Query().Get("status"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		after, _ := strconv.Atoi(r.URL.Query().Get("after_id"))
+		page := []client.Order{}
+		for id := after + 1; id <= 5 && len(page) < 2; id++ {
+			page = append(page, client.Order{ID: uint64(id)})
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+
+	it := c.List(context.Background(), client.ListOptions{Status: client.OrderStatusPaid, PageSize: 2})
+	ids := []uint64{}
+	for it.Next() {
+		ids = append(ids, it.Order().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, ids)
+}
+
+func TestErrors(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer k1", r.Header.Get("Authorization"))
+		assert.Equal(t, "shop-a", r.Header.Get("X-Tenant-ID"))
+		switch r.Method {
+		case "GET":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(pkg.ErrorResponse{Message: "Order Not Found"})
+		case "PUT":
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(pkg.ErrorResponse{Message: "invalid order", Errors: []string{"customer_name is required"}})
+		}
+	})
+
+	_, err := c.Get(context.Background(), 1)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+
+	_, err = c.Update(context.Background(), 1, client.Order{})
+	assert.True(t, errors.Is(err, client.ErrInvalid))
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, []string{"customer_name is required"}, apiErr.Errors)
+}
+
+func TestCreateRetriesWithTheSameKey(t *testing.T) {
+	var mu sync.Mutex
+	keys := []string{}
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		order := client.Order{}
+		json.Unmarshal(body, &order)
+		order.ID = 7
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(order)
+	})
+
+	ctx := client.WithIdempotencyKey(context.Background(), "order-42")
+	order, err := c.Create(ctx, client.Order{CustomerName: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), order.ID)
+	assert.Equal(t, []string{"order-42", "order-42", "order-42"}, keys)
+}
+
+func TestRetriesStop(t *testing.T) {
+	calls := 0
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	err := c.Delete(context.Background(), 1)
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, 4, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Get(ctx, 1)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultPageSize = 100
+
+// OrderIterator walks a listing by ascending ID, fetching a page at a time.
+//
+//	it := c.List(ctx, client.ListOptions{Status: client.OrderStatusPaid})
+//	for it.Next() {
+//		order := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type OrderIterator struct {
+	ctx     context.Context
+	c       *clientImpl
+	opts    ListOptions
+	page    []Order
+	index   int
+	afterID uint64
+	done    bool
+	err     error
+}
+
+// Next advances to the next order, fetching the next page when needed. It
+// returns false when the listing is exhausted or a request failed.
+func (it *OrderIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if err := it.fetch(); err != nil {
+		it.err = err
+		return false
+	}
+	it.index = 0
+	return len(it.page) > 0
+}
+
+// Order returns the current order.
+func (it *OrderIterator) Order() Order {
+	return it.page[it.index]
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *OrderIterator) Err() error {
+	return it.err
+}
+
+func (it *OrderIterator) fetch() error {
+	size := it.opts.PageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(size))
+	if it.afterID > 0 {
+		q.Set("after_id", strconv.FormatUint(it.afterID, 10))
+	}
+	if it.opts.CustomerName != "" {
+		q.Set("customer_name", it.opts.CustomerName)
+	}
+	if it.opts.Status != "" {
+		q.Set("status", it.opts.Status)
+	}
+	if !it.opts.OrderedFrom.IsZero() {
+		q.Set("ordered_from", it.opts.OrderedFrom.Format(time.RFC3339Nano))
+	}
+	if !it.opts.OrderedTo.IsZero() {
+		q.Set("ordered_to", it.opts.OrderedTo.Format(time.RFC3339Nano))
+	}
+
+	page := []Order{}
+	if err := it.c.do(it.ctx, request{method: "GET", path: "/orders?" + q.Encode()}, &page); err != nil {
+		return err
+	}
+	it.page = page
+	it.done = len(page) < size
+	if len(page) > 0 {
+		it.afterID = page[len(page)-1].ID
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package client
+
+import "time"
+
+// Order statuses. New orders start as OrderStatusPending.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+)
+
+// Order is an order as the API sends and accepts it.
+type Order struct {
+	ID           uint64    `json:"order_id"`
+	CustomerName string    `json:"customer_name"`
+	Status       string    `json:"status"`
+	OrderedAt    time.Time `json:"ordered_at"`
+	Items        []Item    `json:"items"`
+	// PromoCode is only read when the order is created. The amounts are
+	// computed by the server and in minor units.
+	PromoCode string    `json:"promo_code,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	Subtotal  uint64    `json:"subtotal"`
+	Discount  uint64    `json:"discount"`
+	Tax       uint64    `json:"tax"`
+	Total     uint64    `json:"total"`
+	TaxLines  []TaxLine `json:"tax_lines"`
+}
+
+type Item struct {
+	ID          uint64 `json:"item_id"`
+	ItemCode    string `json:"item_code"`
+	Description string `json:"description"`
+	Quantity    uint64 `json:"quantity"`
+	UnitPrice   uint64 `json:"unit_price"`
+	Discount    uint64 `json:"discount"`
+	TaxCategory string `json:"tax_category,omitempty"`
+	OrderID     uint64 `json:"order_id"`
+}
+
+// TaxLine is the tax of one rate over the items it applies to. Rate is a
+// percentage; Inclusive taxes are part of the item prices.
+type TaxLine struct {
+	Region    string `json:"region"`
+	Category  string `json:"category"`
+	Rate      string `json:"rate"`
+	Inclusive bool   `json:"inclusive"`
+	Taxable   uint64 `json:"taxable"`
+	Amount    uint64 `json:"amount"`
+}
+
+// ListOptions narrows a listing. Zero fields do not filter.
+type ListOptions struct {
+	CustomerName string
+	Status       string
+	// OrderedFrom and OrderedTo bound OrderedAt, inclusive and exclusive.
+	OrderedFrom time.Time
+	OrderedTo   time.Time
+	// PageSize is how many orders are fetched per request, 100 by default.
+	PageSize int
+}
+
+// PatchType is the media type of a patch document.
+type PatchType string
+
+const (
+	// MergePatch is a JSON Merge Patch (RFC 7396).
+	MergePatch PatchType = "application/merge-patch+json"
+	// JSONPatch is a JSON Patch (RFC 6902).
+	JSONPatch PatchType = "application/json-patch+json"
+)
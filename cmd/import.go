@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MidnightHelix/assignment-2/internal/importer"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// runImport implements the import command:
+//
+//	import [-tenant id] [-format csv|ndjson] [-dry-run] [-batch-size n] FILE
+//
+// FILE may be "-" for standard input. The orders are imported for the
+// tenant of -tenant, defaultTenant when it is not given. The report is
+// printed as JSON. It returns an error when the import stopped early or any
+// order failed.
+func runImport(ctx context.Context, imp importer.Importer, defaultTenant string, defaultBatchSize int, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	tenantID := fs.String("tenant", defaultTenant, "tenant the orders are imported for")
+	format := fs.String("format", "", "csv or ndjson; guessed from the file extension when empty")
+	dryRun := fs.Bool("dry-run", false, "validate the file without storing anything")
+	batchSize := fs.Int("batch-size", defaultBatchSize, "orders committed per transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: import [-tenant id] [-format csv|ndjson] [-dry-run] [-batch-size n] FILE")
+	}
+	switch {
+	case *tenantID == "":
+		return tenant.ErrMissing
+	case !tenant.Valid(*tenantID):
+		return fmt.Errorf("tenant %q: %w", *tenantID, tenant.ErrInvalid)
+	}
+	ctx = tenant.With(ctx, *tenantID)
+
+	name := fs.Arg(0)
+	var r io.Reader = stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	opts := importer.Options{Format: importer.Format(*format), BatchSize: *batchSize, DryRun: *dryRun}
+	if opts.Format == "" {
+		opts.Format = importer.DetectFormat("", name)
+	}
+
+	rep, err := imp.Import(ctx, r, opts)
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(rep); encErr != nil && err == nil {
+		err = encErr
+	}
+	if err != nil {
+		return err
+	}
+	if rep.Failed > 0 {
+		return fmt.Errorf("%d of %d orders failed", rep.Failed, rep.Orders)
+	}
+	return nil
+}
@@ -1,11 +1,41 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/cache"
+	"github.com/MidnightHelix/assignment-2/internal/config"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/filestore"
+	"github.com/MidnightHelix/assignment-2/internal/graph"
+	"github.com/MidnightHelix/assignment-2/internal/grpcserver"
 	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/importer"
 	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/job"
+	"github.com/MidnightHelix/assignment-2/internal/middleware"
+	"github.com/MidnightHelix/assignment-2/internal/openapi"
+	"github.com/MidnightHelix/assignment-2/internal/outbox"
 	"github.com/MidnightHelix/assignment-2/internal/repository"
 	"github.com/MidnightHelix/assignment-2/internal/router"
+	"github.com/MidnightHelix/assignment-2/internal/secretbox"
 	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/internal/tax"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/MidnightHelix/assignment-2/internal/webhook"
+	"github.com/MidnightHelix/assignment-2/internal/ws"
+	"github.com/MidnightHelix/assignment-2/pkg"
 
 	"github.com/gin-gonic/gin"
 
@@ -26,22 +56,336 @@ import (
 // @host			localhost:3000
 // @BasePath		/api/v1
 // @schemes		http
+// @security		ApiKeyAuth
+//
+// @securityDefinitions.apikey	ApiKeyAuth
+// @in							header
+// @name						X-API-Key
+// @description				Required when the server runs with API_KEYS. The key may also be sent as "Authorization: Bearer <key>".
 func main() {
-	g := gin.Default()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("startup failed: %v", err)
+	}
+
+	// gorm stays nil with the memory backend, which leaves out the features
+	// that need a database
+	var gorm infrastructure.GormDB
+	var orderQuery repository.OrderQuery
+	var orderCommand repository.OrderCommand
+	var txManager repository.TxManager
+	var outboxRepo repository.OutboxRepository
+	var auditRepo repository.AuditRepository
+	var promotionRepo repository.PromotionRepository
+	if cfg.StorageBackend == "memory" {
+		log.Println("STORAGE_BACKEND=memory: orders are lost on exit; search, reports, GraphQL, webhooks, jobs, promotions and idempotency keys are disabled")
+		store := repository.NewMemoryStore()
+		orderQuery = repository.NewMemoryOrderQuery(store)
+		orderCommand = repository.NewMemoryOrderCommand(store)
+		txManager = repository.NewMemoryTxManager(store)
+		outboxRepo = repository.NewMemoryOutboxRepository(store)
+		auditRepo = repository.NewMemoryAuditRepository(store)
+	} else {
+		gorm, err = infrastructure.NewGormDB(cfg.Database)
+		if err != nil {
+			log.Fatalf("startup failed: %v", err)
+		}
+		if cfg.StorageBackend != "postgres" {
+			log.Printf("STORAGE_BACKEND=%s: search, reports and GraphQL are disabled", cfg.StorageBackend)
+		}
+		orderQuery = repository.NewOrderQuery(gorm)
+		orderCommand = repository.NewOrderCommand(gorm)
+		txManager = repository.NewTxManager(gorm)
+		outboxRepo = repository.NewOutboxRepository(gorm)
+		auditRepo = repository.NewAuditRepository(gorm)
+		promotionRepo = repository.NewPromotionRepository(gorm)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.OrderCache.Size > 0 {
+		orderCache := cache.NewLRU(cfg.OrderCache.Size)
+		stats := &cache.Stats{}
+		expvar.Publish("order_cache", expvar.Func(func() any { return stats.Snapshot() }))
+		orderQuery = repository.NewCachedOrderQuery(orderQuery, orderCache, cfg.OrderCache.TTL, stats)
+		orderCommand = repository.NewInvalidatingOrderCommand(orderCommand, orderCache)
+	}
+	// orders are not taxed without a tax table
+	var taxes service.TaxCalculator
+	if cfg.Tax.TableFile != "" {
+		table, err := tax.LoadTable(cfg.Tax.TableFile)
+		if err != nil {
+			log.Fatalf("startup failed: %v", err)
+		}
+		if taxes, err = tax.NewTableCalculator(table); err != nil {
+			log.Fatalf("startup failed: invalid tax table %s: %v", cfg.Tax.TableFile, err)
+		}
+	}
+	orderSvc := service.NewOrderService(orderQuery, orderCommand, txManager, outboxRepo, auditRepo, promotionRepo, taxes, cfg.MaxBatchOperations)
+	orderImporter := importer.NewImporter(orderSvc)
+
+	// "import FILE" loads orders from a file instead of serving the API
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(ctx, orderImporter, cfg.Tenancy.Default, cfg.ImportBatchSize, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("import: %v", err)
+		}
+		return
+	}
+
+	g := gin.New()
+	g.Use(middleware.Logger(gin.DefaultWriter), gin.Recovery())
+	// let services read values the middlewares store on the request context
+	g.ContextWithFallback = true
+	g.Use(middleware.RequestID())
 
 	v := g.Group("/api/v1")
+	// the same credentials are accepted by the REST and gRPC APIs
+	var authn auth.Authenticator
+	if len(cfg.APIKeys) > 0 {
+		admins := map[string]bool{}
+		for _, name := range cfg.APIKeyAdmins {
+			admins[name] = true
+		}
+		keys := map[string]auth.Principal{}
+		for key, name := range cfg.APIKeys {
+			keys[key] = auth.Principal{Name: name, Tenant: cfg.Tenancy.KeyTenants[name], Admin: admins[name]}
+		}
+		authn = auth.NewStaticAuthenticator(keys)
+		// browsers cannot set headers on WebSockets and event streams
+		v.Use(middleware.Auth(authn, "/api/v1/ws", "/api/v1/orders/stream"))
+	} else {
+		log.Println("API_KEYS is not set, the API is unauthenticated")
+	}
+	tenants := tenant.Resolver{BaseDomain: cfg.Tenancy.BaseDomain, Default: cfg.Tenancy.Default, AllowUnbound: cfg.Tenancy.AllowUnbound}
+	v.Use(middleware.Tenant(tenants))
+	apiDoc, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("startup failed: %v", err)
+	}
+	if cfg.OpenAPIValidation != "off" {
+		validate, err := middleware.OpenAPIValidation(apiDoc, cfg.OpenAPIValidation == "strict")
+		if err != nil {
+			log.Fatalf("startup failed: %v", err)
+		}
+		v.Use(validate)
+	}
 	usersGroup := v.Group("/orders")
+	batchGroup := v.Group("")
+	var idempotencyRepo repository.IdempotencyRepository
+	idempotencyCfg := middleware.IdempotencyConfig{
+		TTL:          cfg.Idempotency.KeyTTL,
+		LockTimeout:  cfg.Idempotency.LockTimeout,
+		MaxBodyBytes: cfg.Idempotency.MaxBodyBytes,
+	}
+	if gorm != nil {
+		// order writes sent with an Idempotency-Key are safe to retry
+		idempotencyRepo = repository.NewIdempotencyRepository(gorm)
+		idempotency := middleware.Idempotency(idempotencyRepo, idempotencyCfg)
+		usersGroup.Use(idempotency)
+		batchGroup.Use(idempotency)
+	}
 
-	gorm := infrastructure.NewGormPostgres()
-	orderRepo := repository.NewOrderQuery(gorm)
-	orderSvc := service.NewOrderService(orderRepo)
 	orderHdl := handler.NewOrderHandler(orderSvc)
 	orderRouter := router.NewOrderRouter(usersGroup, orderHdl)
+	orderBatchRouter := router.NewOrderBatchRouter(batchGroup, orderHdl)
+	importHdl := handler.NewOrderImportHandler(orderImporter, cfg.ImportBatchSize, cfg.MaxBatchOperations)
+	importRouter := router.NewOrderImportRouter(usersGroup, importHdl)
+
+	broker := event.NewBroker(cfg.Stream.Buffer)
+	eventSvc := service.NewEventService(outboxRepo, broker)
+	streamHdl := handler.NewOrderStreamHandler(eventSvc, cfg.Stream.Heartbeat, cfg.Stream.WriteTimeout)
+	streamRouter := router.NewOrderStreamRouter(usersGroup, streamHdl)
+
+	hub := ws.NewHub(broker, ws.Config{
+		SendBuffer:       cfg.WebSocket.SendBuffer,
+		MaxSubscriptions: cfg.WebSocket.MaxSubscriptions,
+		PingInterval:     cfg.WebSocket.PingInterval,
+		WriteTimeout:     cfg.WebSocket.WriteTimeout,
+	})
+	socketHdl := handler.NewOrderSocketHandler(hub)
+	socketRouter := router.NewOrderSocketRouter(v, socketHdl)
 
 	// mount
 	orderRouter.Mount()
+	orderBatchRouter.Mount()
+	importRouter.Mount()
+	streamRouter.Mount()
+	socketRouter.Mount()
+	var webhookRepo repository.WebhookRepository
+	var jobRepo repository.JobRepository
+	var jobTypes job.Registry
+	var jobFiles filestore.Store
+	if cfg.StorageBackend == "postgres" {
+		searchSvc := service.NewOrderSearchService(repository.NewOrderSearch(gorm), cfg.MaxSearchLimit)
+		searchHdl := handler.NewOrderSearchHandler(searchSvc, cfg.SearchLimit)
+		router.NewOrderSearchRouter(usersGroup, searchHdl).Mount()
+
+		reportSvc := service.NewReportService(repository.NewReportRepository(gorm), cfg.ReportTimeZone)
+		reportHdl := handler.NewReportHandler(reportSvc)
+		router.NewReportRouter(v.Group("/reports"), reportHdl).Mount()
+
+		graphHdl := handler.NewGraphQLHandler(graph.NewHandler(orderSvc, reportSvc, graph.Limits{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		}))
+		router.NewGraphQLRouter(v.Group("/graphql"), graphHdl).Mount()
+	}
+	if gorm != nil {
+		var secrets *secretbox.Box
+		if len(cfg.Webhooks.SecretKey) > 0 {
+			if secrets, err = secretbox.New(cfg.Webhooks.SecretKey); err != nil {
+				log.Fatalf("startup failed: %v", err)
+			}
+		} else {
+			log.Println("WEBHOOK_SECRET_KEY is not set, webhook signing secrets are stored in plaintext")
+		}
+		webhookRepo = repository.NewWebhookRepository(gorm, secrets)
+		// webhooks and promotions are managed by administrators only
+		webhookGroup := v.Group("/webhooks")
+		promotionGroup := v.Group("/promotions")
+		if authn != nil {
+			webhookGroup.Use(middleware.Admin())
+			promotionGroup.Use(middleware.Admin())
+		}
+		webhookHdl := handler.NewWebhookHandler(service.NewWebhookService(webhookRepo))
+		router.NewWebhookRouter(webhookGroup, webhookHdl).Mount()
+
+		promotionHdl := handler.NewPromotionHandler(service.NewPromotionService(promotionRepo))
+		router.NewPromotionRouter(promotionGroup, promotionHdl).Mount()
+
+		jobRepo = repository.NewJobRepository(gorm)
+		if jobFiles, err = filestore.NewDirStore(cfg.Jobs.OutputDir); err != nil {
+			log.Fatalf("startup failed: %v", err)
+		}
+		jobTypes = job.NewRegistry(orderSvc, orderImporter, jobFiles, cfg.ImportBatchSize, cfg.MaxBatchOperations, cfg.Jobs.MaxAttempts)
+		jobHdl := handler.NewJobHandler(service.NewJobService(jobRepo, jobFiles, jobTypes), cfg.Jobs.MaxInputBytes)
+		router.NewJobRouter(v.Group("/jobs"), jobHdl).Mount()
+	}
 	// swagger
 	g.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	serveDoc, err := openapi.Handler(apiDoc)
+	if err != nil {
+		log.Fatalf("startup failed: %v", err)
+	}
+	g.GET("/openapi.json", serveDoc)
+
+	// background workers act for every tenant
+	var workers sync.WaitGroup
+	background := tenant.WithAllTenants(ctx)
+	publishers := []event.Publisher{newPublisher(cfg.Outbox)}
+	if gorm != nil {
+		publishers = append(publishers, webhook.NewPublisher(webhookRepo))
+		deliverer := webhook.NewDeliverer(webhookRepo, webhook.NewClient(cfg.Webhooks.Timeout), webhook.Config{
+			Interval:     cfg.Webhooks.PollInterval,
+			BatchSize:    cfg.Webhooks.BatchSize,
+			MaxAttempts:  cfg.Webhooks.MaxAttempts,
+			DisableAfter: cfg.Webhooks.DisableAfter,
+			Backoff:      pkg.Backoff{Initial: cfg.Webhooks.RetryInitialBackoff, Max: cfg.Webhooks.RetryMaxBackoff},
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			deliverer.Run(background)
+		}()
+		jobPool := job.NewPool(jobRepo, jobFiles, jobTypes, job.Config{
+			Concurrency:       cfg.Jobs.Concurrency,
+			PollInterval:      cfg.Jobs.PollInterval,
+			VisibilityTimeout: cfg.Jobs.VisibilityTimeout,
+			Backoff:           pkg.Backoff{Initial: cfg.Jobs.RetryInitialBackoff, Max: cfg.Jobs.RetryMaxBackoff},
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			jobPool.Run(background)
+		}()
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			middleware.SweepIdempotencyKeys(background, idempotencyRepo, idempotencyCfg, cfg.Idempotency.SweepInterval)
+		}()
+	}
+	publisher := event.NewMultiPublisher(publishers...)
+	dispatcher := outbox.NewDispatcher(outboxRepo, publisher, broker, outbox.Config{
+		Interval:  cfg.Outbox.PollInterval,
+		BatchSize: cfg.Outbox.BatchSize,
+		Backoff:   pkg.Backoff{Initial: cfg.Outbox.RetryInitialBackoff, Max: cfg.Outbox.RetryMaxBackoff},
+	})
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(background)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		hub.Run(ctx)
+	}()
+
+	srv := &http.Server{Addr: cfg.HTTPAddr, Handler: g}
+	// end event streams so Shutdown does not wait on them
+	srv.RegisterOnShutdown(broker.Close)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server stopped: %v", err)
+		}
+	}()
+	// runtime and cache metrics, off the API listener
+	var debugSrv *http.Server
+	if cfg.DebugAddr != "" {
+		d := gin.New()
+		d.Use(gin.Recovery())
+		d.GET("/debug/vars", handler.DebugVars)
+		debugSrv = &http.Server{Addr: cfg.DebugAddr, Handler: d}
+		go func() {
+			if err := debugSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("debug server stopped: %v", err)
+			}
+		}()
+	}
+	grpcSrv := grpcserver.NewServer(orderSvc, eventSvc, authn, tenants)
+	grpcLis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		log.Fatalf("startup failed: %v", err)
+	}
+	go func() {
+		if err := grpcSrv.Serve(grpcLis); err != nil {
+			log.Fatalf("grpc server stopped: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v", err)
+	}
+	if debugSrv != nil {
+		if err := debugSrv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("debug shutdown: %v", err)
+		}
+	}
+	// the broker is closed by now, which ends watch streams; calls still
+	// running when the shutdown timeout expires are cut off
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+	workers.Wait()
+}
 
-	g.Run(":3000")
+func newPublisher(cfg config.Outbox) event.Publisher {
+	switch cfg.Publisher {
+	case "http":
+		return event.NewHTTPPublisher(cfg.WebhookURL, &http.Client{Timeout: 10 * time.Second})
+	default:
+		return event.NewLogPublisher(log.Default())
+	}
 }
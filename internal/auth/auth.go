@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+)
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+	ErrForbidden       = errors.New("administrator credentials are required")
+)
+
+// Principal is the authenticated caller of an API.
+type Principal struct {
+	Name string `json:"name"`
+	// Tenant is the tenant the credentials are bound to, or "" when they
+	// may act for any tenant.
+	Tenant string `json:"tenant,omitempty"`
+	// Admin lets the caller manage the deployment, such as its webhooks and
+	// promotions, on top of orders.
+	Admin bool `json:"admin,omitempty"`
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller stored by the authentication middleware.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticator resolves a bearer token to a principal. It is shared by every
+// API surface so the same credentials work everywhere.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Principal, error)
+}
+
+type staticAuthenticatorImpl struct {
+	keys map[string]Principal
+}
+
+// NewStaticAuthenticator accepts a fixed set of API keys.
+func NewStaticAuthenticator(keys map[string]Principal) Authenticator {
+	return &staticAuthenticatorImpl{keys: keys}
+}
+
+func (a *staticAuthenticatorImpl) Authenticate(ctx context.Context, token string) (Principal, error) {
+	if token == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+	for key, p := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			return p, nil
+		}
+	}
+	return Principal{}, ErrUnauthenticated
+}
@@ -0,0 +1,41 @@
+// Package cache holds byte values under string keys, in process or in a
+// store shared by every instance of the API.
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores values for a limited time. Implementations must be safe for
+// concurrent use. A shared cache, such as Redis or Memcached, implements
+// Cache over its client; values are already serialized for that reason.
+type Cache interface {
+	// Get reports whether key holds a value that has not expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl. A ttl of zero keeps the value until
+	// it is evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Stats counts the outcomes of cache lookups.
+type Stats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func (s *Stats) Hit()   { s.hits.Add(1) }
+func (s *Stats) Miss()  { s.misses.Add(1) }
+func (s *Stats) Error() { s.errors.Add(1) }
+
+// Snapshot returns the counters, for example to publish with expvar.
+func (s *Stats) Snapshot() map[string]int64 {
+	return map[string]int64{
+		"hits":   s.hits.Load(),
+		"misses": s.misses.Load(),
+		"errors": s.errors.Load(),
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("evicts the least recently used value", func(t *testing.T) {
+		c := NewLRU(2)
+		c.Set(ctx, "a", []byte("1"), 0)
+		c.Set(ctx, "b", []byte("2"), 0)
+		c.Get(ctx, "a")
+		c.Set(ctx, "c", []byte("3"), 0)
+
+		_, ok, _ := c.Get(ctx, "b")
+		assert.False(t, ok)
+		v, ok, _ := c.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, "1", string(v))
+	})
+
+	t.Run("expires values", func(t *testing.T) {
+		now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		c := NewLRU(2).(*lruImpl)
+		c.now = func() time.Time { return now }
+		c.Set(ctx, "a", []byte("1"), time.Minute)
+		c.Set(ctx, "b", []byte("2"), 0)
+
+		now = now.Add(time.Minute)
+		_, ok, _ := c.Get(ctx, "a")
+		assert.False(t, ok)
+		_, ok, _ = c.Get(ctx, "b")
+		assert.True(t, ok)
+		assert.Equal(t, 1, c.order.Len())
+	})
+
+	t.Run("replaces and deletes values", func(t *testing.T) {
+		c := NewLRU(2)
+		c.Set(ctx, "a", []byte("1"), 0)
+		c.Set(ctx, "a", []byte("2"), 0)
+		v, _, _ := c.Get(ctx, "a")
+		assert.Equal(t, "2", string(v))
+
+		c.Delete(ctx, "a", "missing")
+		_, ok, _ := c.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,512 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/secretbox"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// Config holds every setting the application reads from the environment.
+type Config struct {
+	HTTPAddr string
+	// GRPCAddr is where the gRPC API listens, next to the REST API.
+	GRPCAddr string
+	// DebugAddr is where runtime metrics are served at /debug/vars, on a
+	// listener of their own so it can stay internal. Empty disables it.
+	DebugAddr       string
+	ShutdownTimeout time.Duration
+	// StorageBackend is "postgres", "sqlite", or "memory" to keep orders in
+	// process for demos. The memory backend serves the order API only:
+	// webhooks, jobs, promotions and idempotency keys need a database, and
+	// search and reports need Postgres.
+	StorageBackend string
+	Database       Database
+	Outbox         Outbox
+	Webhooks       Webhooks
+	Stream         Stream
+	WebSocket      WebSocket
+	Jobs           Jobs
+	OrderCache     OrderCache
+	GraphQL        GraphQL
+	Tenancy        Tenancy
+	Tax            Tax
+	Idempotency    Idempotency
+	// MaxBatchOperations caps the number of operations in one batch request.
+	MaxBatchOperations int
+	// ImportBatchSize is how many imported orders are committed together by
+	// default. It cannot exceed MaxBatchOperations.
+	ImportBatchSize int
+	// SearchLimit is the default number of search hits, and MaxSearchLimit
+	// the most a request may ask for.
+	SearchLimit    int
+	MaxSearchLimit int
+	// ReportTimeZone is the IANA time zone of report periods when a request
+	// names none.
+	ReportTimeZone string
+	// OpenAPIValidation is "off", "requests" to reject requests that do not
+	// match the OpenAPI document, or "strict" to check responses as well.
+	OpenAPIValidation string
+	// APIKeys maps accepted API keys to the name of their owner. When empty,
+	// authentication is disabled.
+	APIKeys map[string]string
+	// APIKeyAdmins names the owners of API keys that may manage webhooks
+	// and promotions. Without API_KEYS, every caller may.
+	APIKeyAdmins []string
+}
+
+// Database describes how to reach the database and how to size its pool.
+type Database struct {
+	// Driver is "postgres" or "sqlite".
+	Driver string
+	// URL is the connection string of the database. When empty, Postgres is
+	// reached through Host, Port and the like, and SQLite opens DBName.db.
+	URL      string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// RowLevelSecurity adds Postgres row-level security policies that keep
+	// each tenant to its rows, on top of the scoping done by the
+	// repositories.
+	RowLevelSecurity bool
+
+	// ConnectDeadline bounds how long startup keeps retrying before giving up.
+	ConnectDeadline     time.Duration
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+}
+
+// DSN returns the connection string understood by the driver.
+func (p Database) DSN() string {
+	switch {
+	case p.URL != "":
+		return p.URL
+	case p.Driver == "sqlite":
+		return p.DBName + ".db"
+	}
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=5",
+		p.Host, p.Port, p.User, p.Password, p.DBName, p.SSLMode)
+}
+
+// Address names the database in logs, without credentials.
+func (p Database) Address() string {
+	switch {
+	case p.Driver == "sqlite":
+		return p.DSN()
+	case p.URL != "":
+		if u, err := url.Parse(p.URL); err == nil && u.Host != "" {
+			return u.Host
+		}
+		return "from DB_URL"
+	}
+	return p.Host + ":" + p.Port
+}
+
+// Outbox controls how stored order events are published.
+type Outbox struct {
+	PollInterval        time.Duration
+	BatchSize           int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+	// Publisher is "log" or "http".
+	Publisher  string
+	WebhookURL string
+}
+
+// Webhooks controls delivery of order events to partner subscriptions.
+type Webhooks struct {
+	PollInterval        time.Duration
+	BatchSize           int
+	Timeout             time.Duration
+	MaxAttempts         int
+	DisableAfter        int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+	// SecretKey encrypts the signing secrets of subscriptions in the
+	// database. Without one they are stored in plaintext; once set, it has
+	// to be kept to read them back.
+	SecretKey []byte
+}
+
+// Stream controls live order event streams.
+type Stream struct {
+	// Buffer is how many events a client may fall behind before it is
+	// disconnected and has to resume from the outbox.
+	Buffer       int
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+}
+
+// WebSocket controls the live order tracking endpoint.
+type WebSocket struct {
+	SendBuffer       int
+	MaxSubscriptions int
+	PingInterval     time.Duration
+	WriteTimeout     time.Duration
+}
+
+// OrderCache controls the in-process cache of order reads.
+type OrderCache struct {
+	// Size is how many reads are cached; zero disables the cache.
+	Size int
+	TTL  time.Duration
+}
+
+// GraphQL bounds the operations the GraphQL endpoint accepts.
+type GraphQL struct {
+	MaxDepth int
+	// MaxComplexity caps the estimated number of fields an operation
+	// resolves, lists counted at their page size.
+	MaxComplexity int
+}
+
+// Tenancy controls how requests are assigned to tenants, the storefronts
+// sharing the deployment. See tenant.Resolver.
+type Tenancy struct {
+	BaseDomain string
+	// Default is the tenant of callers bound to none. When empty, they are
+	// rejected.
+	Default string
+	// KeyTenants binds the owners of API keys to the tenant they act for.
+	// Owners left out act for Default.
+	KeyTenants map[string]string
+	// AllowUnbound lets owners left out of KeyTenants, and every caller
+	// without API_KEYS, pick their tenant by header or subdomain.
+	AllowUnbound bool
+}
+
+// Idempotency controls how requests sent with an Idempotency-Key are made
+// safe to retry.
+type Idempotency struct {
+	// KeyTTL is how long the response to a request is kept for retries.
+	KeyTTL time.Duration
+	// LockTimeout is how long a request may be served before a retry with
+	// its key presumes it lost and runs again.
+	LockTimeout time.Duration
+	// SweepInterval is how often expired keys are deleted.
+	SweepInterval time.Duration
+	// MaxBodyBytes caps the request bodies kept to recognize retries.
+	// Larger requests sent with a key are rejected.
+	MaxBodyBytes int64
+}
+
+// Tax configures the taxes of orders.
+type Tax struct {
+	// TableFile is the JSON tax table, see tax.Table. Orders are not taxed
+	// without one.
+	TableFile string
+}
+
+// Jobs controls the background job workers.
+type Jobs struct {
+	Concurrency  int
+	PollInterval time.Duration
+	// VisibilityTimeout is how long a job stays claimed by a worker that
+	// stopped reporting, for example because its process died.
+	VisibilityTimeout time.Duration
+	// MaxAttempts applies to job types that are safe to retry.
+	MaxAttempts         int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+	// MaxInputBytes caps the size of a job request, uploaded file included.
+	MaxInputBytes int64
+	// OutputDir keeps the files jobs produce, such as exports. Processes
+	// sharing a database must share it too.
+	OutputDir string
+}
+
+// Load reads the configuration from environment variables, falling back to
+// defaults suitable for local development.
+func Load() (Config, error) {
+	l := loader{}
+	cfg := Config{
+		HTTPAddr:        l.string("HTTP_ADDR", ":3000"),
+		GRPCAddr:        l.string("GRPC_ADDR", ":3001"),
+		DebugAddr:       l.string("DEBUG_ADDR", ""),
+		ShutdownTimeout: l.duration("SHUTDOWN_TIMEOUT", 15*time.Second),
+		StorageBackend:  l.string("STORAGE_BACKEND", storageBackendOf(os.Getenv("DB_URL"))),
+		Database: Database{
+			URL:      l.string("DB_URL", ""),
+			Host:     l.string("DB_HOST", "127.0.0.1"),
+			Port:     l.string("DB_PORT", "5432"),
+			User:     l.string("DB_USER", "midnight"),
+			Password: l.string("DB_PASSWORD", "midnight"),
+			DBName:   l.string("DB_NAME", "orders_by"),
+			SSLMode:  l.string("DB_SSLMODE", "disable"),
+
+			RowLevelSecurity: l.bool("DB_ROW_LEVEL_SECURITY", false),
+
+			MaxOpenConns:    l.int("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    l.int("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: l.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime: l.duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+
+			ConnectDeadline:     l.duration("DB_CONNECT_DEADLINE", time.Minute),
+			RetryInitialBackoff: l.duration("DB_RETRY_INITIAL_BACKOFF", 500*time.Millisecond),
+			RetryMaxBackoff:     l.duration("DB_RETRY_MAX_BACKOFF", 10*time.Second),
+		},
+		Outbox: Outbox{
+			PollInterval:        l.duration("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:           l.int("OUTBOX_BATCH_SIZE", 100),
+			RetryInitialBackoff: l.duration("OUTBOX_RETRY_INITIAL_BACKOFF", time.Second),
+			RetryMaxBackoff:     l.duration("OUTBOX_RETRY_MAX_BACKOFF", 5*time.Minute),
+			Publisher:           l.string("OUTBOX_PUBLISHER", "log"),
+			WebhookURL:          l.string("OUTBOX_WEBHOOK_URL", ""),
+		},
+		Webhooks: Webhooks{
+			PollInterval:        l.duration("WEBHOOK_POLL_INTERVAL", time.Second),
+			BatchSize:           l.int("WEBHOOK_BATCH_SIZE", 50),
+			Timeout:             l.duration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:         l.int("WEBHOOK_MAX_ATTEMPTS", 8),
+			DisableAfter:        l.int("WEBHOOK_DISABLE_AFTER", 20),
+			RetryInitialBackoff: l.duration("WEBHOOK_RETRY_INITIAL_BACKOFF", 10*time.Second),
+			RetryMaxBackoff:     l.duration("WEBHOOK_RETRY_MAX_BACKOFF", time.Hour),
+		},
+		Stream: Stream{
+			Buffer:       l.int("STREAM_BUFFER", 256),
+			Heartbeat:    l.duration("STREAM_HEARTBEAT", 15*time.Second),
+			WriteTimeout: l.duration("STREAM_WRITE_TIMEOUT", 10*time.Second),
+		},
+		WebSocket: WebSocket{
+			SendBuffer:       l.int("WS_SEND_BUFFER", 64),
+			MaxSubscriptions: l.int("WS_MAX_SUBSCRIPTIONS", 100),
+			PingInterval:     l.duration("WS_PING_INTERVAL", 30*time.Second),
+			WriteTimeout:     l.duration("WS_WRITE_TIMEOUT", 10*time.Second),
+		},
+		OrderCache: OrderCache{
+			Size: l.int("ORDER_CACHE_SIZE", 0),
+			TTL:  l.duration("ORDER_CACHE_TTL", time.Minute),
+		},
+		GraphQL: GraphQL{
+			MaxDepth:      l.int("GRAPHQL_MAX_DEPTH", 8),
+			MaxComplexity: l.int("GRAPHQL_MAX_COMPLEXITY", 5000),
+		},
+		Tenancy: Tenancy{
+			BaseDomain:   l.string("TENANT_BASE_DOMAIN", ""),
+			Default:      l.string("TENANT_DEFAULT", tenant.Default),
+			KeyTenants:   l.pairs("API_KEY_TENANTS"),
+			AllowUnbound: l.bool("TENANT_ALLOW_UNBOUND", false),
+		},
+		Tax: Tax{
+			TableFile: l.string("TAX_TABLE_FILE", ""),
+		},
+		Jobs: Jobs{
+			Concurrency:         l.int("JOB_CONCURRENCY", 2),
+			PollInterval:        l.duration("JOB_POLL_INTERVAL", time.Second),
+			VisibilityTimeout:   l.duration("JOB_VISIBILITY_TIMEOUT", time.Minute),
+			MaxAttempts:         l.int("JOB_MAX_ATTEMPTS", 3),
+			RetryInitialBackoff: l.duration("JOB_RETRY_INITIAL_BACKOFF", 10*time.Second),
+			RetryMaxBackoff:     l.duration("JOB_RETRY_MAX_BACKOFF", 10*time.Minute),
+			MaxInputBytes:       int64(l.int("JOB_MAX_INPUT_BYTES", 64<<20)),
+			OutputDir:           l.string("JOB_OUTPUT_DIR", filepath.Join(os.TempDir(), "order-jobs")),
+		},
+		Idempotency: Idempotency{
+			KeyTTL:        l.duration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			LockTimeout:   l.duration("IDEMPOTENCY_LOCK_TIMEOUT", 5*time.Minute),
+			SweepInterval: l.duration("IDEMPOTENCY_SWEEP_INTERVAL", 10*time.Minute),
+			MaxBodyBytes:  int64(l.int("IDEMPOTENCY_MAX_BODY_BYTES", 8<<20)),
+		},
+		OpenAPIValidation:  l.string("OPENAPI_VALIDATION", "off"),
+		APIKeys:            l.pairs("API_KEYS"),
+		APIKeyAdmins:       l.list("API_KEY_ADMINS"),
+		MaxBatchOperations: l.int("MAX_BATCH_OPERATIONS", 500),
+		ImportBatchSize:    l.int("IMPORT_BATCH_SIZE", 100),
+		SearchLimit:        l.int("SEARCH_LIMIT", 20),
+		MaxSearchLimit:     l.int("MAX_SEARCH_LIMIT", 100),
+		ReportTimeZone:     l.string("REPORT_TIME_ZONE", "UTC"),
+	}
+	switch cfg.StorageBackend {
+	case "postgres", "sqlite":
+		cfg.Database.Driver = cfg.StorageBackend
+	case "memory":
+	default:
+		l.fail("STORAGE_BACKEND", cfg.StorageBackend, errors.New(`must be "postgres", "sqlite" or "memory"`))
+	}
+	if cfg.Database.RowLevelSecurity && cfg.StorageBackend != "postgres" {
+		l.fail("DB_ROW_LEVEL_SECURITY", "true", errors.New("requires STORAGE_BACKEND=postgres"))
+	}
+	if cfg.Tenancy.Default != "" && !tenant.Valid(cfg.Tenancy.Default) {
+		l.fail("TENANT_DEFAULT", cfg.Tenancy.Default, tenant.ErrInvalid)
+	}
+	for owner, id := range cfg.Tenancy.KeyTenants {
+		if !tenant.Valid(id) {
+			l.fail("API_KEY_TENANTS", owner+"="+id, tenant.ErrInvalid)
+		}
+	}
+	for _, owner := range cfg.APIKeyAdmins {
+		if !ownsKey(cfg.APIKeys, owner) {
+			l.fail("API_KEY_ADMINS", owner, errors.New("is not an owner in API_KEYS"))
+		}
+	}
+	if v := l.string("WEBHOOK_SECRET_KEY", ""); v != "" {
+		key, err := secretbox.ParseKey(v)
+		if err != nil {
+			l.fail("WEBHOOK_SECRET_KEY", "<redacted>", err)
+		}
+		cfg.Webhooks.SecretKey = key
+	}
+	if cfg.Outbox.Publisher == "http" && cfg.Outbox.WebhookURL == "" {
+		l.fail("OUTBOX_WEBHOOK_URL", "", errors.New("required when OUTBOX_PUBLISHER=http"))
+	}
+	if cfg.ImportBatchSize < 1 || cfg.ImportBatchSize > cfg.MaxBatchOperations {
+		l.fail("IMPORT_BATCH_SIZE", strconv.Itoa(cfg.ImportBatchSize), errors.New("must be between 1 and MAX_BATCH_OPERATIONS"))
+	}
+	if cfg.SearchLimit < 1 || cfg.SearchLimit > cfg.MaxSearchLimit {
+		l.fail("SEARCH_LIMIT", strconv.Itoa(cfg.SearchLimit), errors.New("must be between 1 and MAX_SEARCH_LIMIT"))
+	}
+	if _, err := time.LoadLocation(cfg.ReportTimeZone); err != nil {
+		l.fail("REPORT_TIME_ZONE", cfg.ReportTimeZone, err)
+	}
+	switch cfg.OpenAPIValidation {
+	case "off", "requests", "strict":
+	default:
+		l.fail("OPENAPI_VALIDATION", cfg.OpenAPIValidation, errors.New(`must be "off", "requests" or "strict"`))
+	}
+	for _, b := range []struct {
+		prefix       string
+		initial, max time.Duration
+	}{
+		{"DB", cfg.Database.RetryInitialBackoff, cfg.Database.RetryMaxBackoff},
+		{"OUTBOX", cfg.Outbox.RetryInitialBackoff, cfg.Outbox.RetryMaxBackoff},
+		{"WEBHOOK", cfg.Webhooks.RetryInitialBackoff, cfg.Webhooks.RetryMaxBackoff},
+		{"JOB", cfg.Jobs.RetryInitialBackoff, cfg.Jobs.RetryMaxBackoff},
+	} {
+		if b.initial <= 0 {
+			l.fail(b.prefix+"_RETRY_INITIAL_BACKOFF", b.initial.String(), errors.New("must be positive"))
+		}
+		if b.max < b.initial {
+			l.fail(b.prefix+"_RETRY_MAX_BACKOFF", b.max.String(), errors.New("must not be below the initial backoff"))
+		}
+	}
+	if cfg.Jobs.Concurrency < 1 {
+		l.fail("JOB_CONCURRENCY", strconv.Itoa(cfg.Jobs.Concurrency), errors.New("must be at least 1"))
+	}
+	if l.err != nil {
+		return Config{}, l.err
+	}
+	return cfg, nil
+}
+
+// storageBackendOf guesses the backend from the scheme of a connection
+// string: SQLite is opened with a file: URI.
+func storageBackendOf(url string) string {
+	if strings.HasPrefix(url, "file:") {
+		return "sqlite"
+	}
+	return "postgres"
+}
+
+func ownsKey(keys map[string]string, owner string) bool {
+	for _, name := range keys {
+		if name == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// loader remembers the first parse error so Load can report it once.
+type loader struct {
+	err error
+}
+
+func (l *loader) string(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func (l *loader) int(key string, def int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return n
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return b
+}
+
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return d
+}
+
+// list parses "value1,value2".
+func (l *loader) list(key string) []string {
+	res := []string{}
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return res
+	}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// pairs parses "key1=value1,key2=value2".
+func (l *loader) pairs(key string) map[string]string {
+	res := map[string]string{}
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return res
+	}
+	for _, pair := range strings.Split(v, ",") {
+		k, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || k == "" {
+			l.fail(key, "<redacted>", errors.New("expected comma-separated key=value pairs"))
+			return res
+		}
+		res[k] = val
+	}
+	return res
+}
+
+func (l *loader) fail(key, value string, err error) {
+	if l.err == nil {
+		l.err = fmt.Errorf("invalid %s=%q: %w", key, value, err)
+	}
+}
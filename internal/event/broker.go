@@ -0,0 +1,103 @@
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+// Broker fans published events out to in-process subscribers such as
+// streaming clients. It never blocks the publisher: a subscriber whose
+// buffer is full is dropped and must resubscribe, catching up from the
+// outbox.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+}
+
+func NewBroker(buffer int) *Broker {
+	return &Broker{subs: map[*Subscription]struct{}{}, buffer: buffer}
+}
+
+func (b *Broker) Publish(ctx context.Context, e Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		if s.tenant != "" && s.tenant != e.TenantID {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.lagged = true
+			b.remove(s)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber to the events of every tenant. Callers
+// must Close it when done.
+func (b *Broker) Subscribe() *Subscription {
+	return b.SubscribeTenant("")
+}
+
+// SubscribeTenant registers a subscriber to the events of one tenant, or of
+// every tenant when tenantID is empty.
+func (b *Broker) SubscribeTenant(tenantID string) *Subscription {
+	s := &Subscription{broker: b, ch: make(chan Event, b.buffer), tenant: tenantID}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.ch)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Close ends every subscription, letting long-lived streams finish during
+// shutdown. Later subscriptions are closed immediately.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.remove(s)
+	}
+}
+
+// remove must be called with b.mu held.
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
+
+type Subscription struct {
+	broker *Broker
+	ch     chan Event
+	tenant string
+	lagged bool
+}
+
+// Events is closed when the subscription is closed or dropped.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Lagged reports whether the broker dropped the subscription because it
+// fell too far behind.
+func (s *Subscription) Lagged() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.lagged
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
@@ -0,0 +1,72 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker(t *testing.T) {
+	t.Run("fans out to every subscriber", func(t *testing.T) {
+		b := NewBroker(4)
+		first := b.Subscribe()
+		defer first.Close()
+		second := b.Subscribe()
+		defer second.Close()
+
+		e := New(OrderCreated, model.Order{ID: 1})
+		assert.Nil(t, b.Publish(context.Background(), e))
+
+		assert.Equal(t, e, <-first.Events())
+		assert.Equal(t, e, <-second.Events())
+	})
+
+	t.Run("keeps tenants apart", func(t *testing.T) {
+		b := NewBroker(4)
+		shopA := b.SubscribeTenant("shop-a")
+		defer shopA.Close()
+		all := b.Subscribe()
+		defer all.Close()
+
+		b.Publish(context.Background(), New(OrderCreated, model.Order{ID: 1, TenantID: "shop-b"}))
+		e := New(OrderCreated, model.Order{ID: 2, TenantID: "shop-a"})
+		b.Publish(context.Background(), e)
+
+		assert.Equal(t, e, <-shopA.Events())
+		assert.Equal(t, uint64(1), (<-all.Events()).OrderID)
+		assert.Equal(t, uint64(2), (<-all.Events()).OrderID)
+	})
+
+	t.Run("drops slow subscriber", func(t *testing.T) {
+		b := NewBroker(1)
+		slow := b.Subscribe()
+		fast := b.Subscribe()
+		defer fast.Close()
+
+		b.Publish(context.Background(), New(OrderCreated, model.Order{ID: 1}))
+		<-fast.Events()
+		b.Publish(context.Background(), New(OrderUpdated, model.Order{ID: 1}))
+
+		assert.True(t, slow.Lagged())
+		<-slow.Events()
+		_, open := <-slow.Events()
+		assert.False(t, open)
+
+		_, open = <-fast.Events()
+		assert.True(t, open)
+		assert.False(t, fast.Lagged())
+		slow.Close()
+	})
+}
+
+func TestFilter(t *testing.T) {
+	e := New(OrderStatusChanged, model.Order{ID: 1, CustomerName: "Alice", Status: model.OrderStatusPaid})
+
+	assert.True(t, Filter{}.Match(e))
+	assert.True(t, Filter{CustomerName: "alice"}.Match(e))
+	assert.False(t, Filter{CustomerName: "bob"}.Match(e))
+	assert.True(t, Filter{Statuses: []string{model.OrderStatusShipped, model.OrderStatusPaid}}.Match(e))
+	assert.False(t, Filter{CustomerName: "alice", Statuses: []string{model.OrderStatusShipped}}.Match(e))
+}
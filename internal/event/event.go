@@ -0,0 +1,67 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+type Type string
+
+const (
+	OrderCreated       Type = "order.created"
+	OrderUpdated       Type = "order.updated"
+	OrderStatusChanged Type = "order.status_changed"
+	OrderDeleted       Type = "order.deleted"
+)
+
+// Event describes a change to an order. For OrderDeleted, Order holds the
+// order as it was before deletion.
+type Event struct {
+	// ID identifies the event once it is stored. It stays the same when the
+	// event is published again, so consumers can drop duplicates with it.
+	ID uint64 `json:"id"`
+	// Sequence orders events by when they were published and is what
+	// streams resume from. It is zero until the event is published.
+	Sequence       uint64      `json:"sequence,omitempty"`
+	Type           Type        `json:"type"`
+	OrderID        uint64      `json:"order_id"`
+	Order          model.Order `json:"order"`
+	PreviousStatus string      `json:"previous_status,omitempty"`
+	OccurredAt     time.Time   `json:"occurred_at"`
+	// TenantID is stored with the outbox row rather than in the payload.
+	TenantID string `json:"-"`
+}
+
+func New(t Type, order model.Order) Event {
+	return Event{
+		Type:       t,
+		OrderID:    order.ID,
+		Order:      order,
+		OccurredAt: time.Now().UTC(),
+		TenantID:   order.TenantID,
+	}
+}
+
+// FromOutbox decodes a stored event.
+func FromOutbox(row model.OutboxEvent) (Event, error) {
+	e := Event{}
+	if err := json.Unmarshal([]byte(row.Payload), &e); err != nil {
+		return Event{}, err
+	}
+	e.ID = row.ID
+	if row.Sequence != nil {
+		e.Sequence = *row.Sequence
+	}
+	e.TenantID = row.TenantID
+	return e, nil
+}
+
+// Publisher delivers events to a downstream system. Delivery is
+// at-least-once, so implementations and their consumers must tolerate
+// duplicates.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
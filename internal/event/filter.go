@@ -0,0 +1,25 @@
+package event
+
+import "strings"
+
+// Filter selects events by the state of the order they carry. Empty fields
+// match everything.
+type Filter struct {
+	CustomerName string
+	Statuses     []string
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.CustomerName != "" && !strings.EqualFold(f.CustomerName, e.Order.CustomerName) {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, s := range f.Statuses {
+		if s == e.Order.Status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+type logPublisher struct {
+	logger *log.Logger
+}
+
+// NewLogPublisher writes every event to logger.
+func NewLogPublisher(logger *log.Logger) Publisher {
+	return &logPublisher{logger: logger}
+}
+
+func (p *logPublisher) Publish(ctx context.Context, e Event) error {
+	p.logger.Printf("event %d %s order=%d", e.ID, e.Type, e.OrderID)
+	return nil
+}
+
+type httpPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher POSTs every event as JSON to url. Any non-2xx response is
+// treated as a failure so the event is retried.
+func NewHTTPPublisher(url string, client *http.Client) Publisher {
+	return &httpPublisher{url: url, client: client}
+}
+
+func (p *httpPublisher) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", string(e.Type))
+	if e.TenantID != "" {
+		req.Header.Set("X-Tenant-ID", e.TenantID)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("publish event %d: unexpected status %d", e.ID, res.StatusCode)
+	}
+	return nil
+}
+
+// MemoryPublisher keeps published events in memory. It is meant for tests.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+	return nil
+}
+
+// Events returns a copy of everything published so far.
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Event(nil), p.events...)
+}
+
+type multiPublisher struct {
+	publishers []Publisher
+
+	mu sync.Mutex
+	// delivered tracks, by event ID, which publishers already have an
+	// event that failed on some other publisher.
+	delivered map[uint64][]bool
+}
+
+// NewMultiPublisher publishes each event to every publisher in turn. It
+// fails if any of them fails; when the event is retried, only the
+// publishers that have not taken it yet see it again. That progress is
+// kept in memory, so after a restart a failed event goes to all of them.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return &multiPublisher{publishers: publishers, delivered: map[uint64][]bool{}}
+}
+
+func (p *multiPublisher) Publish(ctx context.Context, e Event) error {
+	p.mu.Lock()
+	delivered, ok := p.delivered[e.ID]
+	p.mu.Unlock()
+	if !ok {
+		delivered = make([]bool, len(p.publishers))
+	}
+
+	var errs []error
+	for i, pub := range p.publishers {
+		if delivered[i] {
+			continue
+		}
+		if err := pub.Publish(ctx, e); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delivered[i] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(errs) > 0 {
+		p.delivered[e.ID] = delivered
+	} else {
+		delete(p.delivered, e.ID)
+	}
+	return errors.Join(errs...)
+}
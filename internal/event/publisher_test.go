@@ -0,0 +1,48 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingPublisher fails until healed.
+type failingPublisher struct {
+	*MemoryPublisher
+	healed bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, e Event) error {
+	if !p.healed {
+		return errors.New("downstream unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, e)
+}
+
+func TestMultiPublisher(t *testing.T) {
+	first := NewMemoryPublisher()
+	flaky := &failingPublisher{MemoryPublisher: NewMemoryPublisher()}
+	last := NewMemoryPublisher()
+	pub := NewMultiPublisher(first, flaky, last)
+
+	e := New(OrderCreated, model.Order{ID: 1})
+	e.ID = 7
+	assert.NotNil(t, pub.Publish(context.Background(), e))
+	assert.Equal(t, 1, len(first.Events()))
+	assert.Equal(t, 1, len(last.Events()))
+
+	// the retry only goes to the publisher that failed
+	assert.NotNil(t, pub.Publish(context.Background(), e))
+	flaky.healed = true
+	assert.Nil(t, pub.Publish(context.Background(), e))
+	assert.Equal(t, 1, len(first.Events()))
+	assert.Equal(t, 1, len(flaky.Events()))
+	assert.Equal(t, 1, len(last.Events()))
+
+	// once delivered everywhere the event is forgotten
+	assert.Nil(t, pub.Publish(context.Background(), e))
+	assert.Equal(t, 2, len(first.Events()))
+}
@@ -0,0 +1,98 @@
+// Package exporter writes orders as CSV, one row per item, or as NDJSON, one
+// order per line.
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// ContentType returns the media type of f, or "" for an unknown format.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	}
+	return ""
+}
+
+var csvHeader = []string{"order_id", "customer_name", "status", "ordered_at", "item_id", "item_code", "description", "quantity"}
+
+// Writer encodes orders onto an io.Writer. The CSV header row is written with
+// the first call to Write.
+type Writer struct {
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+func NewWriter(w io.Writer, format Format) (*Writer, error) {
+	switch format {
+	case CSV:
+		return &Writer{csv: csv.NewWriter(w)}, nil
+	case NDJSON:
+		return &Writer{json: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// Write encodes orders and flushes them to the underlying writer.
+func (e *Writer) Write(orders []model.Order) error {
+	if !e.started && e.csv != nil {
+		e.csv.Write(csvHeader)
+	}
+	e.started = true
+	for _, order := range orders {
+		if err := e.writeOrder(order); err != nil {
+			return err
+		}
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+func (e *Writer) writeOrder(order model.Order) error {
+	if e.json != nil {
+		return e.json.Encode(order)
+	}
+
+	row := []string{
+		strconv.FormatUint(order.ID, 10),
+		order.CustomerName,
+		order.Status,
+		order.OrderedAt.Format(time.RFC3339),
+	}
+	if len(order.Items) == 0 {
+		return e.csv.Write(append(row, "", "", "", ""))
+	}
+	for _, item := range order.Items {
+		if err := e.csv.Write(append(row[:4:4],
+			strconv.FormatUint(item.ID, 10),
+			item.ItemCode,
+			item.Description,
+			strconv.FormatUint(item.Quantity, 10),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Package filestore keeps files too large for a database column, such as
+// the output of export jobs. Rows refer to them by key.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+type Store interface {
+	// Create opens a new, empty file for writing and returns its key. The
+	// file is complete once the writer is closed without error.
+	Create(ctx context.Context) (string, io.WriteCloser, error)
+	// Open returns the content of the file key, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Remove deletes the file key. Removing a missing file is not an error.
+	Remove(ctx context.Context, key string) error
+}
+
+type dirStoreImpl struct {
+	dir string
+}
+
+// NewDirStore keeps files in dir, which is created when missing. Processes
+// that share a database have to share dir as well, for example on a network
+// volume: a job may be downloaded from another process than the one that
+// ran it.
+func NewDirStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &dirStoreImpl{dir: dir}, nil
+}
+
+func (s *dirStoreImpl) Create(ctx context.Context) (string, io.WriteCloser, error) {
+	f, err := os.CreateTemp(s.dir, "file-*")
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Base(f.Name()), f, nil
+}
+
+func (s *dirStoreImpl) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, ok := s.path(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *dirStoreImpl) Remove(ctx context.Context, key string) error {
+	path, ok := s.path(key)
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns where the file key is kept. Keys are plain file names, so a
+// key read back from the database cannot name a file outside the store.
+func (s *dirStoreImpl) path(key string) (string, bool) {
+	if key == "" || key != filepath.Base(key) || key == "." || key == ".." {
+		return "", false
+	}
+	return filepath.Join(s.dir, key), true
+}
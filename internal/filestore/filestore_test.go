@@ -0,0 +1,39 @@
+package filestore
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDirStore(t.TempDir())
+	require.NoError(t, err)
+
+	key, w, err := store.Create(ctx)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "order_id\n1\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, err := store.Open(ctx, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "order_id\n1\n", string(data))
+
+	require.NoError(t, store.Remove(ctx, key))
+	require.NoError(t, store.Remove(ctx, key))
+	_, err = store.Open(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"", ".", "..", "../etc/passwd", "/etc/passwd"} {
+		_, err := store.Open(ctx, key)
+		assert.ErrorIs(t, err, ErrNotFound, key)
+	}
+}
@@ -0,0 +1,28 @@
+// Package gqlmodel holds the GraphQL types that have no counterpart in the
+// model package.
+package gqlmodel
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// MarshalID writes the uint64 IDs of the model package as GraphQL IDs,
+// which are strings on the wire.
+func MarshalID(id uint64) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(strconv.FormatUint(id, 10)))
+	})
+}
+
+// UnmarshalID accepts IDs sent as strings or integers.
+func UnmarshalID(v any) (uint64, error) {
+	switch v := v.(type) {
+	case string:
+		return strconv.ParseUint(v, 10, 64)
+	default:
+		return graphql.UnmarshalUint64(v)
+	}
+}
@@ -0,0 +1,53 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package gqlmodel
+
+import (
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+type ItemInput struct {
+	ID          *uint64 `json:"id,omitempty"`
+	ItemCode    string  `json:"itemCode"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   *int    `json:"unitPrice,omitempty"`
+	TaxCategory *string `json:"taxCategory,omitempty"`
+}
+
+type Mutation struct {
+}
+
+type OrderConnection struct {
+	Nodes    []model.Order `json:"nodes"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type OrderFilter struct {
+	CustomerName *string `json:"customerName,omitempty"`
+	Status       *string `json:"status,omitempty"`
+	// Inclusive.
+	OrderedFrom *time.Time `json:"orderedFrom,omitempty"`
+	// Exclusive.
+	OrderedTo *time.Time `json:"orderedTo,omitempty"`
+}
+
+type OrderInput struct {
+	CustomerName string     `json:"customerName"`
+	Status       *string    `json:"status,omitempty"`
+	OrderedAt    *time.Time `json:"orderedAt,omitempty"`
+	// Only taken when the order is created.
+	PromoCode *string     `json:"promoCode,omitempty"`
+	Region    *string     `json:"region,omitempty"`
+	Items     []ItemInput `json:"items"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
+}
+
+type Query struct {
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/MidnightHelix/assignment-2/internal/graph/generated"
+	"github.com/MidnightHelix/assignment-2/internal/graph/gqlmodel"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+	// itemsPerOrder is the number of items the complexity of a query assumes
+	// for every order, as it cannot know in advance.
+	itemsPerOrder = 10
+)
+
+// complexity weighs list fields by the number of elements they may return,
+// so a query's complexity approximates the rows it reads.
+func complexity() generated.ComplexityRoot {
+	c := generated.ComplexityRoot{}
+	c.Query.Orders = func(child int, _ *gqlmodel.OrderFilter, first *int, _ *string) int {
+		return 1 + child*pageSize(first)
+	}
+	c.Query.Customers = func(child int, _, _ *time.Time, limit *int) int {
+		n := 10
+		if limit != nil && *limit > 0 {
+			n = *limit
+		}
+		return 1 + child*n
+	}
+	c.Customer.Orders = func(child int, _ *string, first *int, _ *string) int {
+		return 1 + child*pageSize(first)
+	}
+	c.Order.Items = func(child int) int {
+		return 1 + child*itemsPerOrder
+	}
+	return c
+}
+
+// pageSize returns the number of orders a page holds for first.
+func pageSize(first *int) int {
+	switch {
+	case first == nil:
+		return defaultPageSize
+	case *first > maxPageSize:
+		return maxPageSize
+	case *first < 1:
+		return 1
+	default:
+		return *first
+	}
+}
+
+// depthLimit rejects operations that nest selections deeper than max
+// before any of them is resolved.
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+func (d depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d depthLimit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d depthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(rc.Operation.SelectionSet); depth > d.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.max)
+		err.Extensions = map[string]any{"code": "DEPTH_LIMIT_EXCEEDED"}
+		return err
+	}
+	return nil
+}
+
+// selectionDepth counts nested fields; fragments do not add a level of
+// their own.
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+	for _, sel := range set {
+		d := 0
+		switch sel := sel.(type) {
+		case *ast.Field:
+			d = 1 + selectionDepth(sel.SelectionSet)
+		case *ast.InlineFragment:
+			d = selectionDepth(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if sel.Definition != nil {
+				d = selectionDepth(sel.Definition.SelectionSet)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaderWait is how long a loader collects keys before it queries them
+// together. Resolvers of sibling fields run concurrently, so a
+// short wait is enough to gather a whole list.
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+type loaders struct {
+	items *dataloader.Loader[uint64, []model.Item]
+	// customerOrders loads a page of the orders of each customer.
+	customerOrders *dataloader.Loader[customerOrdersKey, []model.Order]
+}
+
+// customerOrdersKey names a page of the orders of a customer. Pages asked
+// with the same filter are loaded by one query.
+type customerOrdersKey struct {
+	name   string
+	filter model.OrderFilter
+}
+
+// withLoaders gives every request its own loaders, so batches and their
+// cached results never outlive the request or leak across callers.
+func withLoaders(orders service.OrderService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := &loaders{
+			items:          dataloader.NewBatchedLoader(itemBatch(orders), dataloader.WithWait[uint64, []model.Item](loaderWait)),
+			customerOrders: dataloader.NewBatchedLoader(customerOrdersBatch(orders), dataloader.WithWait[customerOrdersKey, []model.Order](loaderWait)),
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loadersKey{}, l)))
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// itemBatch loads the items of a batch of orders with one query.
+func itemBatch(orders service.OrderService) dataloader.BatchFunc[uint64, []model.Item] {
+	return func(ctx context.Context, ids []uint64) []*dataloader.Result[[]model.Item] {
+		res := make([]*dataloader.Result[[]model.Item], len(ids))
+		items, err := orders.GetOrderItems(ctx, ids)
+		for i, id := range ids {
+			if err != nil {
+				res[i] = &dataloader.Result[[]model.Item]{Error: err}
+				continue
+			}
+			res[i] = &dataloader.Result[[]model.Item]{Data: items[id]}
+		}
+		return res
+	}
+}
+
+// customerOrdersBatch loads the orders of a batch of customers with one
+// query per distinct filter.
+func customerOrdersBatch(orders service.OrderService) dataloader.BatchFunc[customerOrdersKey, []model.Order] {
+	return func(ctx context.Context, keys []customerOrdersKey) []*dataloader.Result[[]model.Order] {
+		res := make([]*dataloader.Result[[]model.Order], len(keys))
+		names := map[model.OrderFilter][]string{}
+		for _, key := range keys {
+			names[key.filter] = append(names[key.filter], key.name)
+		}
+		for filter, batch := range names {
+			found, err := orders.GetCustomerOrders(ctx, batch, filter)
+			for i, key := range keys {
+				if key.filter != filter {
+					continue
+				}
+				if err != nil {
+					res[i] = &dataloader.Result[[]model.Order]{Error: err}
+					continue
+				}
+				res[i] = &dataloader.Result[[]model.Order]{Data: found[key.name]}
+			}
+		}
+		return res
+	}
+}
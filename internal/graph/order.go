@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/MidnightHelix/assignment-2/internal/graph/gqlmodel"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// orderPage returns up to first orders that match filter, after the order
+// the cursor points at.
+func (r *Resolver) orderPage(ctx context.Context, filter model.OrderFilter, first *int, after *string) (*gqlmodel.OrderConnection, error) {
+	filter, size, err := pageFilter(filter, first, after)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := r.orders.GetOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return orderConnection(orders, size), nil
+}
+
+// pageFilter limits filter to the page that first and after ask for, and
+// returns the size of the page.
+func pageFilter(filter model.OrderFilter, first *int, after *string) (model.OrderFilter, int, error) {
+	size := pageSize(first)
+	if after != nil {
+		id, err := decodeCursor(*after)
+		if err != nil {
+			return filter, 0, err
+		}
+		filter.AfterID = id
+	}
+	// one more than asked tells whether another page follows
+	filter.Limit = size + 1
+	return filter, size, nil
+}
+
+// orderConnection returns a page of size orders out of those loaded by a
+// pageFilter.
+func orderConnection(orders []model.Order, size int) *gqlmodel.OrderConnection {
+	if orders == nil {
+		orders = []model.Order{}
+	}
+	res := &gqlmodel.OrderConnection{Nodes: orders, PageInfo: &gqlmodel.PageInfo{}}
+	if len(orders) > size {
+		res.Nodes = orders[:size]
+		res.PageInfo.HasNextPage = true
+	}
+	if len(res.Nodes) > 0 {
+		cursor := encodeCursor(res.Nodes[len(res.Nodes)-1].ID)
+		res.PageInfo.EndCursor = &cursor
+	}
+	return res
+}
+
+// Cursors are opaque to clients; they hold the ID of an order.
+func encodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+func decodeCursor(cursor string) (uint64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
+
+// orderFromInput converts an OrderInput; the service validates the rest.
+func orderFromInput(input gqlmodel.OrderInput) (model.Order, error) {
+	order := model.Order{CustomerName: input.CustomerName, Items: []model.Item{}}
+	if input.Status != nil {
+		order.Status = *input.Status
+	}
+	if input.OrderedAt != nil {
+		order.OrderedAt = *input.OrderedAt
+	}
+	if input.PromoCode != nil {
+		order.PromoCode = *input.PromoCode
+	}
+	if input.Region != nil {
+		order.Region = *input.Region
+	}
+	for _, item := range input.Items {
+		if item.Quantity < 0 {
+			return model.Order{}, &service.ValidationError{Errors: []string{"items.quantity must not be negative"}}
+		}
+		if item.UnitPrice != nil && *item.UnitPrice < 0 {
+			return model.Order{}, &service.ValidationError{Errors: []string{"items.unit_price must not be negative"}}
+		}
+		i := model.Item{ItemCode: item.ItemCode, Description: item.Description, Quantity: uint64(item.Quantity)}
+		if item.ID != nil {
+			i.ID = *item.ID
+		}
+		if item.UnitPrice != nil {
+			i.UnitPrice = uint64(*item.UnitPrice)
+		}
+		if item.TaxCategory != nil {
+			i.TaxCategory = *item.TaxCategory
+		}
+		order.Items = append(order.Items, i)
+	}
+	return order, nil
+}
@@ -0,0 +1,15 @@
+// Package graph serves the orders API over GraphQL. The schema is in
+// schema.graphqls; run "gqlgen generate" from the repository root after
+// changing it.
+package graph
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/service"
+)
+
+// Resolver is the root resolver. It holds the services shared by every
+// request; per-request state such as the item loader lives in the context.
+type Resolver struct {
+	orders  service.OrderService
+	reports service.ReportService
+}
@@ -0,0 +1,164 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.45
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/graph/generated"
+	"github.com/MidnightHelix/assignment-2/internal/graph/gqlmodel"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+)
+
+// OrderCount is the resolver for the orderCount field.
+func (r *customerResolver) OrderCount(ctx context.Context, obj *model.CustomerOrders) (int, error) {
+	return int(obj.Orders), nil
+}
+
+// Orders is the resolver for the orders field.
+func (r *customerResolver) Orders(ctx context.Context, obj *model.CustomerOrders, status *string, first *int, after *string) (*gqlmodel.OrderConnection, error) {
+	filter := model.OrderFilter{}
+	if status != nil {
+		filter.Status = *status
+	}
+	filter, size, err := pageFilter(filter, first, after)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := loadersFrom(ctx).customerOrders.Load(ctx, customerOrdersKey{name: obj.CustomerName, filter: filter})()
+	if err != nil {
+		return nil, err
+	}
+	return orderConnection(orders, size), nil
+}
+
+// CreateOrder is the resolver for the createOrder field.
+func (r *mutationResolver) CreateOrder(ctx context.Context, input gqlmodel.OrderInput) (*model.Order, error) {
+	order, err := orderFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	order, err = r.orders.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateOrder is the resolver for the updateOrder field.
+func (r *mutationResolver) UpdateOrder(ctx context.Context, id uint64, input gqlmodel.OrderInput) (*model.Order, error) {
+	order, err := orderFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	order, err = r.orders.UpdateOrder(ctx, order, id)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// DeleteOrder is the resolver for the deleteOrder field.
+func (r *mutationResolver) DeleteOrder(ctx context.Context, id uint64) (uint64, error) {
+	if err := r.orders.DeleteOrder(ctx, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Items is the resolver for the items field.
+func (r *orderResolver) Items(ctx context.Context, obj *model.Order) ([]model.Item, error) {
+	// listings load the items with their orders; single orders do not
+	if obj.Items != nil {
+		return obj.Items, nil
+	}
+	items, err := loadersFrom(ctx).items.Load(ctx, obj.ID)()
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []model.Item{}
+	}
+	return items, nil
+}
+
+// TaxLines is the resolver for the taxLines field.
+func (r *orderResolver) TaxLines(ctx context.Context, obj *model.Order) ([]model.TaxLine, error) {
+	if obj.TaxLines == nil {
+		return []model.TaxLine{}, nil
+	}
+	return obj.TaxLines, nil
+}
+
+// Order is the resolver for the order field.
+func (r *queryResolver) Order(ctx context.Context, id uint64) (*model.Order, error) {
+	order, err := r.orders.GetOrdersById(ctx, id)
+	if errors.Is(err, service.ErrOrderNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// Orders is the resolver for the orders field.
+func (r *queryResolver) Orders(ctx context.Context, filter *gqlmodel.OrderFilter, first *int, after *string) (*gqlmodel.OrderConnection, error) {
+	f := model.OrderFilter{}
+	if filter != nil {
+		if filter.CustomerName != nil {
+			f.CustomerName = *filter.CustomerName
+		}
+		if filter.Status != nil {
+			f.Status = *filter.Status
+		}
+		if filter.OrderedFrom != nil {
+			f.OrderedFrom = *filter.OrderedFrom
+		}
+		if filter.OrderedTo != nil {
+			f.OrderedTo = *filter.OrderedTo
+		}
+	}
+	return r.orderPage(ctx, f, first, after)
+}
+
+// Customers is the resolver for the customers field.
+func (r *queryResolver) Customers(ctx context.Context, from *time.Time, to *time.Time, limit *int) ([]model.CustomerOrders, error) {
+	req := model.ReportRequest{}
+	if from != nil {
+		req.From = *from
+	}
+	if to != nil {
+		req.To = *to
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+	rep, err := r.reports.CustomerOrders(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return rep.Customers, nil
+}
+
+// Customer returns generated.CustomerResolver implementation.
+func (r *Resolver) Customer() generated.CustomerResolver { return &customerResolver{r} }
+
+// Mutation returns generated.MutationResolver implementation.
+func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
+
+// Order returns generated.OrderResolver implementation.
+func (r *Resolver) Order() generated.OrderResolver { return &orderResolver{r} }
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+type customerResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/MidnightHelix/assignment-2/internal/graph/generated"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Limits bounds the work a single operation may ask for.
+type Limits struct {
+	// MaxDepth is how deeply selections may nest.
+	MaxDepth int
+	// MaxComplexity caps the estimated number of fields resolved, lists
+	// counted at their page size.
+	MaxComplexity int
+}
+
+// NewHandler serves GraphQL queries and mutations sent with GET or POST.
+func NewHandler(orders service.OrderService, reports service.ReportService, limits Limits) http.Handler {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{
+		Resolvers:  &Resolver{orders: orders, reports: reports},
+		Complexity: complexity(),
+	}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New(1000))
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New(100)})
+	srv.Use(depthLimit{max: limits.MaxDepth})
+	srv.Use(extension.FixedComplexityLimit(limits.MaxComplexity))
+	srv.SetErrorPresenter(presentError)
+	return withLoaders(orders, srv)
+}
+
+// presentError reports service errors with a machine-readable code, as the
+// REST API does with its status codes.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		gqlErr.Message = "invalid input"
+		gqlErr.Extensions = map[string]any{"code": "BAD_USER_INPUT", "errors": validationErr.Errors}
+	case errors.Is(err, service.ErrOrderNotFound):
+		gqlErr.Message = "order not found"
+		gqlErr.Extensions = map[string]any{"code": "NOT_FOUND"}
+	}
+	return gqlErr
+}
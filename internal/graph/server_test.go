@@ -0,0 +1,189 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/graph"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type response struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func run(t *testing.T, srv http.Handler, query string) response {
+	body, err := json.Marshal(map[string]any{"query": query})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(w, req)
+
+	res := response{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res), w.Body.String())
+	return res
+}
+
+var limits = graph.Limits{MaxDepth: 8, MaxComplexity: 5000}
+
+func TestOrdersPage(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetOrders", mock.Anything, model.OrderFilter{Status: "paid", Limit: 2}).Return([]model.Order{
+		{ID: 1, CustomerName: "alice", Items: []model.Item{{ID: 1, ItemCode: "A1", Quantity: 2}}},
+		{ID: 3, CustomerName: "bob", Items: []model.Item{}},
+	}, nil)
+	srv := graph.NewHandler(mockSvc, &mocks.ReportService{}, limits)
+
+	res := run(t, srv, `{ orders(filter: {status: "paid"}, first: 1) { nodes { id customerName items { itemCode quantity } } pageInfo { hasNextPage endCursor } } }`)
+	require.Empty(t, res.Errors)
+	orders := res.Data["orders"].(map[string]any)
+	nodes := orders["nodes"].([]any)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "1", nodes[0].(map[string]any)["id"])
+	assert.Equal(t, []any{map[string]any{"itemCode": "A1", "quantity": float64(2)}}, nodes[0].(map[string]any)["items"])
+	assert.Equal(t, true, orders["pageInfo"].(map[string]any)["hasNextPage"])
+	// items came with the listing, not from the loader
+	mockSvc.AssertNotCalled(t, "GetOrderItems", mock.Anything, mock.Anything)
+}
+
+func TestOrderItemsAreBatched(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(1)).Return(model.Order{ID: 1}, nil)
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(2)).Return(model.Order{ID: 2}, nil)
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(3)).Return(model.Order{}, service.ErrOrderNotFound)
+	mockSvc.On("GetOrderItems", mock.Anything, mock.MatchedBy(func(ids []uint64) bool { return len(ids) == 2 })).
+		Return(map[uint64][]model.Item{1: {{ID: 7, OrderID: 1, ItemCode: "A1"}}}, nil).Once()
+	srv := graph.NewHandler(mockSvc, &mocks.ReportService{}, limits)
+
+	res := run(t, srv, `{ a: order(id: 1) { items { itemCode } } b: order(id: 2) { items { itemCode } } c: order(id: 3) { id } }`)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, []any{map[string]any{"itemCode": "A1"}}, res.Data["a"].(map[string]any)["items"])
+	assert.Equal(t, []any{}, res.Data["b"].(map[string]any)["items"])
+	assert.Nil(t, res.Data["c"])
+	mockSvc.AssertNumberOfCalls(t, "GetOrderItems", 1)
+}
+
+func TestCustomerOrdersAreBatched(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetCustomerOrders", mock.Anything, mock.MatchedBy(func(names []string) bool { return len(names) == 2 }), model.OrderFilter{Status: "paid", Limit: 2}).
+		Return(map[string][]model.Order{
+			"alice": {{ID: 1, CustomerName: "alice", Items: []model.Item{}}, {ID: 4, CustomerName: "alice", Items: []model.Item{}}},
+		}, nil).Once()
+	mockReports := &mocks.ReportService{}
+	mockReports.On("CustomerOrders", mock.Anything, model.ReportRequest{Limit: 10}).Return(model.CustomerOrdersReport{
+		Customers: []model.CustomerOrders{{CustomerName: "alice", Orders: 2}, {CustomerName: "bob", Orders: 1}},
+	}, nil)
+	srv := graph.NewHandler(mockSvc, mockReports, limits)
+
+	res := run(t, srv, `{ customers { name orders(status: "paid", first: 1) { nodes { id } pageInfo { hasNextPage } } } }`)
+	require.Empty(t, res.Errors)
+	customers := res.Data["customers"].([]any)
+	require.Len(t, customers, 2)
+	alice := customers[0].(map[string]any)["orders"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"id": "1"}}, alice["nodes"])
+	assert.Equal(t, true, alice["pageInfo"].(map[string]any)["hasNextPage"])
+	bob := customers[1].(map[string]any)["orders"].(map[string]any)
+	assert.Equal(t, []any{}, bob["nodes"])
+	mockSvc.AssertNumberOfCalls(t, "GetCustomerOrders", 1)
+}
+
+func TestLimits(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	query := `{ customers { orders { nodes { items { itemCode } } } } }`
+
+	res := run(t, graph.NewHandler(mockSvc, &mocks.ReportService{}, graph.Limits{MaxDepth: 4, MaxComplexity: 1000000}), query)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "DEPTH_LIMIT_EXCEEDED", res.Errors[0].Extensions["code"])
+
+	res = run(t, graph.NewHandler(mockSvc, &mocks.ReportService{}, graph.Limits{MaxDepth: 8, MaxComplexity: 1000}), query)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "COMPLEXITY_LIMIT_EXCEEDED", res.Errors[0].Extensions["code"])
+	assert.Empty(t, mockSvc.Calls)
+}
+
+func TestCreateOrderMutation(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("CreateOrder", mock.Anything, model.Order{CustomerName: "alice", Items: []model.Item{{ItemCode: "A1", Quantity: 2}}}).
+		Return(model.Order{ID: 5, CustomerName: "alice", Status: model.OrderStatusPending, Items: []model.Item{{ID: 9, ItemCode: "A1", Quantity: 2}}}, nil)
+	mockSvc.On("CreateOrder", mock.Anything, model.Order{Items: []model.Item{}}).
+		Return(model.Order{}, &service.ValidationError{Errors: []string{"customer_name is required"}})
+	srv := graph.NewHandler(mockSvc, &mocks.ReportService{}, limits)
+
+	res := run(t, srv, `mutation { createOrder(input: {customerName: "alice", items: [{itemCode: "A1", quantity: 2}]}) { id status items { id } } }`)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, map[string]any{"id": "5", "status": "pending", "items": []any{map[string]any{"id": "9"}}}, res.Data["createOrder"])
+
+	res = run(t, srv, `mutation { createOrder(input: {customerName: "", items: []}) { id } }`)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "BAD_USER_INPUT", res.Errors[0].Extensions["code"])
+	assert.Equal(t, []any{"customer_name is required"}, res.Errors[0].Extensions["errors"])
+}
+
+// newMemoryOrderService returns an order service over an empty memory store.
+func newMemoryOrderService(taxes service.TaxCalculator) service.OrderService {
+	store := repository.NewMemoryStore()
+	return service.NewOrderService(repository.NewMemoryOrderQuery(store), repository.NewMemoryOrderCommand(store), repository.NewMemoryTxManager(store),
+		repository.NewMemoryOutboxRepository(store), repository.NewMemoryAuditRepository(store), nil, taxes, 10)
+}
+
+func TestUpdateOrderMutationKeepsPrices(t *testing.T) {
+	srv := graph.NewHandler(newMemoryOrderService(nil), &mocks.ReportService{}, limits)
+
+	res := run(t, srv, `mutation { createOrder(input: {customerName: "alice", items: [{itemCode: "A1", quantity: 2, unitPrice: 1250}, {itemCode: "B2", quantity: 1, unitPrice: 500}]}) { id subtotal total items { id unitPrice } } }`)
+	require.Empty(t, res.Errors)
+	created := res.Data["createOrder"].(map[string]any)
+	assert.Equal(t, 3000.0, created["subtotal"])
+	assert.Equal(t, 3000.0, created["total"])
+	first := created["items"].([]any)[0].(map[string]any)
+	assert.Equal(t, 1250.0, first["unitPrice"])
+
+	// the update lists the first item without its price and leaves out the other
+	res = run(t, srv, `mutation { updateOrder(id: "`+created["id"].(string)+`", input: {customerName: "alice", items: [{id: "`+first["id"].(string)+`", itemCode: "A1", quantity: 3}]}) { subtotal total items { unitPrice } } }`)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, map[string]any{
+		"subtotal": 4250.0,
+		"total":    4250.0,
+		"items":    []any{map[string]any{"unitPrice": 1250.0}, map[string]any{"unitPrice": 500.0}},
+	}, res.Data["updateOrder"])
+}
+
+func TestUpdateOrderMutationKeepsTaxes(t *testing.T) {
+	taxes, err := tax.NewTableCalculator(tax.Table{Regions: map[string]tax.Region{
+		"US-CA": {Rates: map[string]string{"standard": "10", "food": "0"}},
+	}})
+	require.NoError(t, err)
+	srv := graph.NewHandler(newMemoryOrderService(taxes), &mocks.ReportService{}, limits)
+
+	res := run(t, srv, `mutation { createOrder(input: {customerName: "alice", region: "US-CA", items: [{itemCode: "A1", quantity: 2, unitPrice: 1000, taxCategory: "food"}, {itemCode: "B2", quantity: 1, unitPrice: 1000}]}) { id tax total taxLines { region category amount } items { id } } }`)
+	require.Empty(t, res.Errors)
+	created := res.Data["createOrder"].(map[string]any)
+	assert.Equal(t, 100.0, created["tax"])
+	assert.Equal(t, 3100.0, created["total"])
+	assert.Contains(t, created["taxLines"], map[string]any{"region": "US-CA", "category": "standard", "amount": 100.0})
+	first := created["items"].([]any)[0].(map[string]any)
+
+	// the update names neither the region nor the tax category of the item
+	res = run(t, srv, `mutation { updateOrder(id: "`+created["id"].(string)+`", input: {customerName: "alice", items: [{id: "`+first["id"].(string)+`", itemCode: "A1", quantity: 2}]}) { region tax total items { taxCategory } } }`)
+	require.Empty(t, res.Errors)
+	assert.Equal(t, map[string]any{
+		"region": "US-CA",
+		"tax":    100.0,
+		"total":  3100.0,
+		"items":  []any{map[string]any{"taxCategory": "food"}, map[string]any{"taxCategory": ""}},
+	}, res.Data["updateOrder"])
+}
@@ -0,0 +1,158 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/requestid"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key of the request ID, the gRPC counterpart
+// of the X-Request-ID header.
+const requestIDKey = "x-request-id"
+
+// tenantKey is the metadata key of the tenant, the gRPC counterpart of the
+// X-Tenant-ID header.
+const tenantKey = "x-tenant-id"
+
+// serverStream lets stream interceptors replace the stream context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func unaryRequestID(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := incomingRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return handler(requestid.With(ctx, id), req)
+}
+
+func streamRequestID(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := incomingRequestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(requestIDKey, id))
+	return handler(srv, &serverStream{ServerStream: ss, ctx: requestid.With(ss.Context(), id)})
+}
+
+// incomingRequestID keeps the ID sent by the client when it is valid, and
+// generates one otherwise.
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(requestIDKey); len(ids) > 0 && requestid.Valid(ids[0]) {
+		return ids[0]
+	}
+	return requestid.New()
+}
+
+func unaryLogging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return res, err
+}
+
+func streamLogging(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	log.Printf("[GRPC] %s | %13v | %s | %s", status.Code(err), time.Since(start), requestid.FromContext(ctx), method)
+}
+
+func unaryAuth(a auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(a auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate stores the caller in ctx. As with the REST API, the token is
+// read from "authorization: Bearer" or x-api-key metadata.
+func authenticate(ctx context.Context, a auth.Authenticator) (context.Context, error) {
+	p, err := a.Authenticate(ctx, token(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+func token(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if h := md.Get("authorization"); len(h) > 0 && len(h[0]) > 7 && strings.EqualFold(h[0][:7], "bearer ") {
+		return strings.TrimSpace(h[0][7:])
+	}
+	if k := md.Get("x-api-key"); len(k) > 0 {
+		return k[0]
+	}
+	return ""
+}
+
+func unaryTenant(r tenant.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolveTenant(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamTenant(r tenant.Resolver) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context(), r)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// resolveTenant stores the tenant of the call in ctx, picked like the
+// tenant middleware of the REST API does from the caller, x-tenant-id
+// metadata and the :authority pseudo-header.
+func resolveTenant(ctx context.Context, r tenant.Resolver) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	p, _ := auth.FromContext(ctx)
+	id, err := r.Resolve(p.Tenant, first(tenantKey), first(":authority"))
+	if errors.Is(err, tenant.ErrMismatch) || errors.Is(err, tenant.ErrUnbound) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return tenant.With(ctx, id), nil
+}
@@ -0,0 +1,308 @@
+// Package grpcserver serves the order API over gRPC, next to the REST API
+// and backed by the same services.
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	orderv1 "github.com/MidnightHelix/assignment-2/api/order/v1"
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+	// replayBatch is how many stored events are read per query while a
+	// watcher catches up.
+	replayBatch = 500
+)
+
+// NewServer returns a gRPC server exposing OrderService. Calls are logged
+// and tagged with a request ID; when authn is nil they are not
+// authenticated, as with the REST API when no API keys are configured.
+// Each call acts for the tenant tenants resolves.
+func NewServer(orders service.OrderService, events service.EventService, authn auth.Authenticator, tenants tenant.Resolver) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{unaryRequestID, unaryLogging}
+	stream := []grpc.StreamServerInterceptor{streamRequestID, streamLogging}
+	if authn != nil {
+		unary = append(unary, unaryAuth(authn))
+		stream = append(stream, streamAuth(authn))
+	}
+	unary = append(unary, unaryTenant(tenants))
+	stream = append(stream, streamTenant(tenants))
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	orderv1.RegisterOrderServiceServer(srv, NewOrderServer(orders, events))
+	return srv
+}
+
+type orderServerImpl struct {
+	orderv1.UnimplementedOrderServiceServer
+	orders service.OrderService
+	events service.EventService
+}
+
+func NewOrderServer(orders service.OrderService, events service.EventService) orderv1.OrderServiceServer {
+	return &orderServerImpl{orders: orders, events: events}
+}
+
+func (u *orderServerImpl) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.GetOrderResponse, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	order, err := u.orders.GetOrdersById(ctx, req.GetId())
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return &orderv1.GetOrderResponse{Order: toProto(order)}, nil
+}
+
+func (u *orderServerImpl) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+	afterID, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+
+	filter := model.OrderFilter{
+		CustomerName: req.GetCustomerName(),
+		Status:       req.GetStatus(),
+		AfterID:      afterID,
+		// one more than asked tells whether another page follows
+		Limit: size + 1,
+	}
+	if req.GetOrderedFrom() != nil {
+		filter.OrderedFrom = req.GetOrderedFrom().AsTime()
+	}
+	if req.GetOrderedTo() != nil {
+		filter.OrderedTo = req.GetOrderedTo().AsTime()
+	}
+	orders, err := u.orders.GetOrders(ctx, filter)
+	if err != nil {
+		return nil, orderError(err)
+	}
+
+	res := &orderv1.ListOrdersResponse{}
+	if len(orders) > size {
+		orders = orders[:size]
+		res.NextPageToken = encodePageToken(orders[size-1].ID)
+	}
+	for _, o := range orders {
+		res.Orders = append(res.Orders, toProto(o))
+	}
+	return res, nil
+}
+
+func (u *orderServerImpl) CreateOrder(ctx context.Context, req *orderv1.CreateOrderRequest) (*orderv1.CreateOrderResponse, error) {
+	if req.GetOrder() == nil {
+		return nil, status.Error(codes.InvalidArgument, "order is required")
+	}
+	order, err := u.orders.CreateOrder(ctx, fromProto(req.GetOrder()))
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return &orderv1.CreateOrderResponse{Order: toProto(order)}, nil
+}
+
+func (u *orderServerImpl) UpdateOrder(ctx context.Context, req *orderv1.UpdateOrderRequest) (*orderv1.UpdateOrderResponse, error) {
+	if req.GetOrder().GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order.id is required")
+	}
+	order, err := u.orders.UpdateOrder(ctx, fromProto(req.GetOrder()), req.GetOrder().GetId())
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return &orderv1.UpdateOrderResponse{Order: toProto(order)}, nil
+}
+
+func (u *orderServerImpl) DeleteOrder(ctx context.Context, req *orderv1.DeleteOrderRequest) (*orderv1.DeleteOrderResponse, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := u.orders.DeleteOrder(ctx, req.GetId()); err != nil {
+		return nil, orderError(err)
+	}
+	return &orderv1.DeleteOrderResponse{}, nil
+}
+
+func (u *orderServerImpl) WatchOrders(req *orderv1.WatchOrdersRequest, stream orderv1.OrderService_WatchOrdersServer) error {
+	ctx := stream.Context()
+	filter := event.Filter{CustomerName: req.GetCustomerName(), Statuses: req.GetStatuses()}
+	send := func(e event.Event) error {
+		if !filter.Match(e) {
+			return nil
+		}
+		return stream.Send(&orderv1.WatchOrdersResponse{Event: eventToProto(e)})
+	}
+
+	// subscribe before catching up so nothing published in between is lost
+	sub := u.events.Subscribe(ctx)
+	defer sub.Close()
+
+	// catch up from the outbox; live events already replayed are skipped
+	lastID := req.GetAfterEventId()
+	if lastID > 0 {
+		for {
+			backlog, err := u.events.GetEventsSince(ctx, lastID, replayBatch)
+			if err != nil {
+				return orderError(err)
+			}
+			for _, e := range backlog {
+				lastID = e.Sequence
+				if err := send(e); err != nil {
+					return err
+				}
+			}
+			if len(backlog) < replayBatch {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					return status.Error(codes.ResourceExhausted, "watcher fell behind, resume with after_event_id")
+				}
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if e.Sequence <= lastID {
+				continue
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// orderError maps service errors to gRPC statuses, as orderErrorResponse
+// does to HTTP statuses for the REST API.
+func orderError(err error) error {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, "invalid order: "+strings.Join(validationErr.Errors, "; "))
+	case errors.Is(err, service.ErrOrderNotFound):
+		return status.Error(codes.NotFound, "order not found")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Page tokens are opaque to clients; they hold the ID of the last order of
+// the previous page.
+func encodePageToken(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(id, 10)))
+}
+
+func decodePageToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(b), 10, 64)
+}
+
+func toProto(o model.Order) *orderv1.Order {
+	res := &orderv1.Order{
+		Id:           o.ID,
+		CustomerName: o.CustomerName,
+		Status:       o.Status,
+		OrderedAt:    timestamppb.New(o.OrderedAt),
+		PromoCode:    o.PromoCode,
+		Subtotal:     o.Subtotal,
+		Discount:     o.Discount,
+		Total:        o.Total,
+		Region:       o.Region,
+		Tax:          o.Tax,
+	}
+	for _, line := range o.TaxLines {
+		res.TaxLines = append(res.TaxLines, &orderv1.TaxLine{
+			Region:    line.Region,
+			Category:  line.Category,
+			Rate:      line.Rate,
+			Inclusive: line.Inclusive,
+			Taxable:   line.Taxable,
+			Amount:    line.Amount,
+		})
+	}
+	for _, item := range o.Items {
+		res.Items = append(res.Items, &orderv1.Item{
+			Id:          item.ID,
+			ItemCode:    item.ItemCode,
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			TaxCategory: item.TaxCategory,
+			OrderId:     item.OrderID,
+		})
+	}
+	return res
+}
+
+func fromProto(o *orderv1.Order) model.Order {
+	res := model.Order{
+		ID:           o.GetId(),
+		CustomerName: o.GetCustomerName(),
+		Status:       o.GetStatus(),
+		PromoCode:    o.GetPromoCode(),
+		Region:       o.GetRegion(),
+	}
+	if o.GetOrderedAt() != nil {
+		res.OrderedAt = o.GetOrderedAt().AsTime()
+	}
+	for _, item := range o.GetItems() {
+		res.Items = append(res.Items, model.Item{
+			ID:          item.GetId(),
+			ItemCode:    item.GetItemCode(),
+			Description: item.GetDescription(),
+			Quantity:    item.GetQuantity(),
+			UnitPrice:   item.GetUnitPrice(),
+			TaxCategory: item.GetTaxCategory(),
+			OrderID:     item.GetOrderId(),
+		})
+	}
+	return res
+}
+
+func eventToProto(e event.Event) *orderv1.OrderEvent {
+	return &orderv1.OrderEvent{
+		Id:             e.ID,
+		Sequence:       e.Sequence,
+		Type:           string(e.Type),
+		OrderId:        e.OrderID,
+		Order:          toProto(e.Order),
+		PreviousStatus: e.PreviousStatus,
+		OccurredAt:     timestamppb.New(e.OccurredAt),
+	}
+}
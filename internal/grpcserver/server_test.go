@@ -0,0 +1,268 @@
+package grpcserver_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	orderv1 "github.com/MidnightHelix/assignment-2/api/order/v1"
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/grpcserver"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/tax"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, orders service.OrderService, events service.EventService, authn auth.Authenticator) orderv1.OrderServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpcserver.NewServer(orders, events, authn, tenant.Resolver{Default: tenant.Default})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return orderv1.NewOrderServiceClient(conn)
+}
+
+func TestGetOrder(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(1)).Return(model.Order{ID: 1, CustomerName: "alice", Items: []model.Item{{ID: 2, ItemCode: "A1", Quantity: 3}}}, nil)
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(2)).Return(model.Order{}, service.ErrOrderNotFound)
+	client := newClient(t, mockSvc, &mocks.EventService{}, nil)
+
+	var header metadata.MD
+	res, err := client.GetOrder(context.Background(), &orderv1.GetOrderRequest{Id: 1}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", res.GetOrder().GetCustomerName())
+	assert.Equal(t, "A1", res.GetOrder().GetItems()[0].GetItemCode())
+	assert.Len(t, header.Get("x-request-id"), 1)
+
+	_, err = client.GetOrder(context.Background(), &orderv1.GetOrderRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetOrder(context.Background(), &orderv1.GetOrderRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListOrders(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetOrders", mock.Anything, model.OrderFilter{Status: "paid", Limit: 3}).
+		Return([]model.Order{{ID: 1}, {ID: 4}, {ID: 7}}, nil)
+	mockSvc.On("GetOrders", mock.Anything, model.OrderFilter{Status: "paid", AfterID: 4, Limit: 3}).
+		Return([]model.Order{{ID: 7}}, nil)
+	client := newClient(t, mockSvc, &mocks.EventService{}, nil)
+
+	res, err := client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{Status: "paid", PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, res.GetOrders(), 2)
+	assert.NotEmpty(t, res.GetNextPageToken())
+
+	res, err = client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{Status: "paid", PageSize: 2, PageToken: res.GetNextPageToken()})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), res.GetOrders()[0].GetId())
+	assert.Empty(t, res.GetNextPageToken())
+
+	_, err = client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{PageToken: "!"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCreateOrderInvalid(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("CreateOrder", mock.Anything, model.Order{}).Return(model.Order{}, &service.ValidationError{Errors: []string{"customer_name is required"}})
+	client := newClient(t, mockSvc, &mocks.EventService{}, nil)
+
+	_, err := client.CreateOrder(context.Background(), &orderv1.CreateOrderRequest{Order: &orderv1.Order{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "customer_name is required")
+}
+
+// newMemoryOrderService returns an order service over an empty memory store.
+func newMemoryOrderService(taxes service.TaxCalculator) service.OrderService {
+	store := repository.NewMemoryStore()
+	return service.NewOrderService(repository.NewMemoryOrderQuery(store), repository.NewMemoryOrderCommand(store), repository.NewMemoryTxManager(store),
+		repository.NewMemoryOutboxRepository(store), repository.NewMemoryAuditRepository(store), nil, taxes, 10)
+}
+
+func TestUpdateOrderKeepsPrices(t *testing.T) {
+	client := newClient(t, newMemoryOrderService(nil), &mocks.EventService{}, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateOrder(ctx, &orderv1.CreateOrderRequest{Order: &orderv1.Order{
+		CustomerName: "alice",
+		Items:        []*orderv1.Item{{ItemCode: "A1", Quantity: 2, UnitPrice: 1250}, {ItemCode: "B2", Quantity: 1, UnitPrice: 500}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1250), created.GetOrder().GetItems()[0].GetUnitPrice())
+	assert.Equal(t, uint64(3000), created.GetOrder().GetSubtotal())
+	assert.Equal(t, uint64(3000), created.GetOrder().GetTotal())
+
+	// the update lists the first item without its price and leaves out the other
+	first := created.GetOrder().GetItems()[0]
+	updated, err := client.UpdateOrder(ctx, &orderv1.UpdateOrderRequest{Order: &orderv1.Order{
+		Id:           created.GetOrder().GetId(),
+		CustomerName: "alice",
+		Items:        []*orderv1.Item{{Id: first.GetId(), ItemCode: "A1", Quantity: 3}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, updated.GetOrder().GetItems(), 2)
+	assert.Equal(t, uint64(1250), updated.GetOrder().GetItems()[0].GetUnitPrice())
+	assert.Equal(t, uint64(500), updated.GetOrder().GetItems()[1].GetUnitPrice())
+	assert.Equal(t, uint64(4250), updated.GetOrder().GetSubtotal())
+	assert.Equal(t, uint64(4250), updated.GetOrder().GetTotal())
+}
+
+func TestUpdateOrderKeepsTaxes(t *testing.T) {
+	taxes, err := tax.NewTableCalculator(tax.Table{Regions: map[string]tax.Region{
+		"US-CA": {Rates: map[string]string{"standard": "10", "food": "0"}},
+	}})
+	require.NoError(t, err)
+	client := newClient(t, newMemoryOrderService(taxes), &mocks.EventService{}, nil)
+	ctx := context.Background()
+
+	created, err := client.CreateOrder(ctx, &orderv1.CreateOrderRequest{Order: &orderv1.Order{
+		CustomerName: "alice",
+		Region:       "US-CA",
+		Items: []*orderv1.Item{
+			{ItemCode: "A1", Quantity: 2, UnitPrice: 1000, TaxCategory: "food"},
+			{ItemCode: "B2", Quantity: 1, UnitPrice: 1000},
+		},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "food", created.GetOrder().GetItems()[0].GetTaxCategory())
+	assert.Equal(t, uint64(100), created.GetOrder().GetTax())
+	assert.Equal(t, uint64(3100), created.GetOrder().GetTotal())
+	require.NotEmpty(t, created.GetOrder().GetTaxLines())
+	assert.Equal(t, "US-CA", created.GetOrder().GetTaxLines()[0].GetRegion())
+
+	// the update names neither the region nor the tax category of the item
+	first := created.GetOrder().GetItems()[0]
+	updated, err := client.UpdateOrder(ctx, &orderv1.UpdateOrderRequest{Order: &orderv1.Order{
+		Id:           created.GetOrder().GetId(),
+		CustomerName: "alice",
+		Items:        []*orderv1.Item{{Id: first.GetId(), ItemCode: "A1", Quantity: 2}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "US-CA", updated.GetOrder().GetRegion())
+	assert.Equal(t, "food", updated.GetOrder().GetItems()[0].GetTaxCategory())
+	assert.Equal(t, uint64(100), updated.GetOrder().GetTax())
+	assert.Equal(t, uint64(3100), updated.GetOrder().GetTotal())
+}
+
+func TestAuth(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	isAlice := mock.MatchedBy(func(ctx context.Context) bool {
+		p, ok := auth.FromContext(ctx)
+		return ok && p.Name == "alice"
+	})
+	mockSvc.On("DeleteOrder", isAlice, uint64(1)).Return(nil)
+	authn := auth.NewStaticAuthenticator(map[string]auth.Principal{"secret": {Name: "alice"}})
+	client := newClient(t, mockSvc, &mocks.EventService{}, authn)
+
+	_, err := client.DeleteOrder(context.Background(), &orderv1.DeleteOrderRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 1})
+	assert.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 1})
+	assert.NoError(t, err)
+	mockSvc.AssertNumberOfCalls(t, "DeleteOrder", 2)
+}
+
+func TestTenant(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	inTenant := func(id string) any {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			got, _ := tenant.FromContext(ctx)
+			return got == id
+		})
+	}
+	mockSvc.On("DeleteOrder", inTenant(tenant.Default), uint64(1)).Return(nil)
+	mockSvc.On("DeleteOrder", inTenant("shop-a"), uint64(2)).Return(nil)
+	authn := auth.NewStaticAuthenticator(map[string]auth.Principal{
+		"secret": {Name: "alice"},
+		"shop-a": {Name: "bob", Tenant: "shop-a"},
+	})
+	client := newClient(t, mockSvc, &mocks.EventService{}, authn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	_, err := client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 1})
+	assert.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "shop-a")
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 2})
+	assert.NoError(t, err)
+
+	// unbound keys act for the default tenant only
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret", "x-tenant-id", tenant.Default)
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 1})
+	assert.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret", "x-tenant-id", "shop-a")
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "shop-a", "x-tenant-id", "shop-b")
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret", "x-tenant-id", "Shop_B")
+	_, err = client.DeleteOrder(ctx, &orderv1.DeleteOrderRequest{Id: 2})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockSvc.AssertNumberOfCalls(t, "DeleteOrder", 3)
+}
+
+func TestWatchOrders(t *testing.T) {
+	broker := event.NewBroker(8)
+	events := &mocks.EventService{}
+	events.On("Subscribe", mock.Anything).Return(func(context.Context) *event.Subscription { return broker.Subscribe() })
+	events.On("GetEventsSince", mock.Anything, uint64(5), 500).Return([]event.Event{
+		{ID: 6, Sequence: 6, Type: event.OrderCreated, OrderID: 1, Order: model.Order{ID: 1, CustomerName: "alice"}},
+	}, nil)
+	client := newClient(t, &mocks.OrderService{}, events, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchOrders(ctx, &orderv1.WatchOrdersRequest{CustomerName: "alice", AfterEventId: 5})
+	require.NoError(t, err)
+
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), res.GetEvent().GetSequence())
+
+	// the stream subscribed before replaying; of a replayed, a filtered and a
+	// new event, only the new one arrives, even though it was stored first
+	broker.Publish(ctx, event.Event{ID: 6, Sequence: 6, Type: event.OrderCreated, Order: model.Order{ID: 1, CustomerName: "alice"}})
+	broker.Publish(ctx, event.Event{ID: 7, Sequence: 7, Type: event.OrderCreated, Order: model.Order{ID: 2, CustomerName: "bob"}})
+	broker.Publish(ctx, event.Event{ID: 4, Sequence: 8, Type: event.OrderUpdated, Order: model.Order{ID: 1, CustomerName: "alice"}})
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), res.GetEvent().GetId())
+	assert.Equal(t, uint64(8), res.GetEvent().GetSequence())
+	assert.Equal(t, string(event.OrderUpdated), res.GetEvent().GetType())
+
+	broker.Close()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"expvar"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugVars serves memory statistics, the command line and published
+// counters such as order_cache, as expvar does. It is not part of the API and
+// is mounted on the debug listener only.
+func DebugVars(ctx *gin.Context) {
+	expvar.Handler().ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler interface {
+	Query(ctx *gin.Context)
+}
+
+type graphQLHandlerImpl struct {
+	srv http.Handler
+}
+
+// NewGraphQLHandler serves GraphQL operations with srv, which executes
+// them against the schema.
+func NewGraphQLHandler(srv http.Handler) GraphQLHandler {
+	return &graphQLHandlerImpl{srv: srv}
+}
+
+// Query godoc
+//
+//	@Summary		Run a GraphQL operation
+//	@Description	Run a query or mutation against the orders schema. Operations that nest too deeply or would resolve too many fields are rejected before they run.
+//	@Tags			graphql
+//	@Accept			json
+//	@Produce		json
+//	@Param			query			query		string	false	"Operation, for GET requests"
+//	@Param			variables		query		string	false	"JSON-encoded variables, for GET requests"
+//	@Param			operation		body		object	false	"query, variables and operationName, for POST requests"
+//	@Success		200				{object}	object
+//	@Failure		400				{object}	object
+//	@Failure		422				{object}	object
+//	@Router			/graphql [post]
+//	@Router			/graphql [get]
+func (u *graphQLHandlerImpl) Query(ctx *gin.Context) {
+	u.srv.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler interface {
+	CreateJob(ctx *gin.Context)
+	GetJobByID(ctx *gin.Context)
+	GetJobResult(ctx *gin.Context)
+}
+
+type jobHandlerImpl struct {
+	svc           service.JobService
+	maxInputBytes int64
+}
+
+// NewJobHandler returns a handler that rejects uploaded job files larger
+// than maxInputBytes.
+func NewJobHandler(svc service.JobService, maxInputBytes int64) JobHandler {
+	return &jobHandlerImpl{svc: svc, maxInputBytes: maxInputBytes}
+}
+
+// CreateJob godoc
+//
+//	@Summary		Queue a job
+//	@Description	Queue a long-running import, export or purge. Send a JSON body, or a multipart form with "type", "params" and a "file" for imports. Poll the job at the Location header for its progress.
+//	@Description	Params: import takes format (csv or ndjson), batch_size and dry_run; export takes format and the filters of the order listing; purge takes the filters of the order listing, at least one of them.
+//	@Tags			jobs
+//	@Accept			json,multipart/form-data
+//	@Produce		json
+//	@Param			request	body		model.JobRequest	true	"Job type and params"
+//	@Success		202		{object}	model.Job
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		413		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/jobs [post]
+func (u *jobHandlerImpl) CreateJob(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, u.maxInputBytes)
+	req, input, err := u.jobRequest(ctx)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, pkg.ErrorResponse{Message: fmt.Sprintf("the request may not exceed %d bytes", u.maxInputBytes)})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	job, err := u.svc.CreateJob(ctx, req.Type, req.Params, input)
+	if err != nil {
+		jobError(ctx, err)
+		return
+	}
+	ctx.Header("Location", fmt.Sprintf("%s/%d", ctx.Request.URL.Path, job.ID))
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// jobRequest reads a JSON job request, or a multipart one with an input
+// file.
+func (u *jobHandlerImpl) jobRequest(ctx *gin.Context) (model.JobRequest, *model.JobFile, error) {
+	req := model.JobRequest{}
+	if ctx.ContentType() != "multipart/form-data" {
+		if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+			return model.JobRequest{}, nil, err
+		}
+		return req, nil, nil
+	}
+
+	if err := ctx.Request.ParseMultipartForm(u.maxInputBytes); err != nil {
+		return model.JobRequest{}, nil, err
+	}
+	req.Type = ctx.PostForm("type")
+	if params := ctx.PostForm("params"); params != "" {
+		if !json.Valid([]byte(params)) {
+			return model.JobRequest{}, nil, errors.New("params must be a JSON object")
+		}
+		req.Params = json.RawMessage(params)
+	}
+	fh, err := ctx.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		return req, nil, nil
+	}
+	if err != nil {
+		return model.JobRequest{}, nil, err
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return model.JobRequest{}, nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return model.JobRequest{}, nil, err
+	}
+	return req, &model.JobFile{
+		Name:        fh.Filename,
+		ContentType: fh.Header.Get("Content-Type"),
+		Data:        data,
+	}, nil
+}
+
+// ShowJob godoc
+//
+//	@Summary		Show a job
+//	@Description	Get the status and progress of a job, and the report of a finished one
+//	@Tags			jobs
+//	@Produce		json
+//	@Param			id	path		int	true	"Job ID"
+//	@Success		200	{object}	model.Job
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/jobs/{id} [get]
+func (u *jobHandlerImpl) GetJobByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	job, err := u.svc.GetJobByID(ctx, uint64(id))
+	if err != nil {
+		jobError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+// DownloadJobResult godoc
+//
+//	@Summary		Download the result of a job
+//	@Description	Download the file produced by a job that succeeded, such as an export. The response is gzip encoded when the client accepts it.
+//	@Tags			jobs
+//	@Produce		text/csv,application/x-ndjson,json
+//	@Param			id	path		int	true	"Job ID"
+//	@Success		200	{file}		file
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		409	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/jobs/{id}/result [get]
+func (u *jobHandlerImpl) GetJobResult(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	file, content, err := u.svc.GetJobOutput(ctx, uint64(id))
+	if err != nil {
+		jobError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	// stream the file rather than load it, exports can be large
+	var body io.Reader = content
+	h := ctx.Writer.Header()
+	h.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	h.Add("Vary", "Accept-Encoding")
+	if file.Encoding == "gzip" {
+		if acceptsGzip(ctx.GetHeader("Accept-Encoding")) {
+			h.Set("Content-Encoding", "gzip")
+		} else {
+			gz, err := gzip.NewReader(content)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+				return
+			}
+			defer gz.Close()
+			body = gz
+		}
+	}
+	ctx.DataFromReader(http.StatusOK, -1, file.ContentType, body, nil)
+}
+
+func jobError(ctx *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid job", Errors: validationErr.Errors})
+	case errors.Is(err, service.ErrJobNotFound):
+		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Job Not Found"})
+	case errors.Is(err, service.ErrJobOutputNotReady):
+		ctx.JSON(http.StatusConflict, pkg.ErrorResponse{Message: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+	}
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func newJobRouter(svc service.JobService, maxInputBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	hdl := handler.NewJobHandler(svc, maxInputBytes)
+	router := newRouter()
+	router.POST("/jobs", hdl.CreateJob)
+	router.GET("/jobs/:id", hdl.GetJobByID)
+	router.GET("/jobs/:id/result", hdl.GetJobResult)
+	return router
+}
+
+func TestCreateJob(t *testing.T) {
+	mockSvc := &mocks.JobService{}
+	mockSvc.On("CreateJob", mock.Anything, "export", []byte(`{"format":"ndjson"}`), (*model.JobFile)(nil)).
+		Return(model.Job{ID: 5, Type: "export", Status: model.JobStatusQueued}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"type":"export","params":{"format":"ndjson"}}`))
+	newJobRouter(mockSvc, 1<<20).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/jobs/5", w.Header().Get("Location"))
+	var job model.Job
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, model.JobStatusQueued, job.Status)
+}
+
+func TestCreateJobWithFile(t *testing.T) {
+	mockSvc := &mocks.JobService{}
+	mockSvc.On("CreateJob", mock.Anything, "import", []byte(`{"format":"csv"}`), mock.MatchedBy(func(f *model.JobFile) bool {
+		return f != nil && f.Name == "orders.csv" && string(f.Data) == "order_ref,customer_name\n"
+	})).Return(model.Job{ID: 6, Type: "import"}, nil)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("type", "import")
+	mw.WriteField("params", `{"format":"csv"}`)
+	fw, _ := mw.CreateFormFile("file", "orders.csv")
+	fw.Write([]byte("order_ref,customer_name\n"))
+	mw.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	newJobRouter(mockSvc, 1<<20).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCreateJobTooLarge(t *testing.T) {
+	mockSvc := &mocks.JobService{}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"type":"export","params":{"format":"ndjson"}}`))
+	newJobRouter(mockSvc, 10).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockSvc.AssertNotCalled(t, "CreateJob")
+}
+
+func TestCreateJobInvalid(t *testing.T) {
+	mockSvc := &mocks.JobService{}
+	mockSvc.On("CreateJob", mock.Anything, "nope", mock.Anything, mock.Anything).
+		Return(model.Job{}, &service.ValidationError{Errors: []string{`unknown job type "nope"`}})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"type":"nope"}`))
+	newJobRouter(mockSvc, 1<<20).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetJobByID(t *testing.T) {
+	mockSvc := &mocks.JobService{}
+	mockSvc.On("GetJobByID", mock.Anything, uint64(5)).Return(model.Job{ID: 5, Status: model.JobStatusRunning, Progress: 40}, nil)
+	mockSvc.On("GetJobByID", mock.Anything, uint64(6)).Return(model.Job{}, service.ErrJobNotFound)
+	router := newJobRouter(mockSvc, 1<<20)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/5", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var job model.Job
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 40, job.Progress)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/jobs/6", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetJobResult(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("order_id\n1\n"))
+	gz.Close()
+
+	mockSvc := &mocks.JobService{}
+	file := model.JobFile{
+		Name:        "orders-5.csv",
+		ContentType: "text/csv; charset=utf-8",
+		Encoding:    "gzip",
+		StorageKey:  "file-1",
+	}
+	mockSvc.On("GetJobOutput", mock.Anything, uint64(5)).
+		Return(func(ctx context.Context, id uint64) (model.JobFile, io.ReadCloser, error) {
+			return file, io.NopCloser(bytes.NewReader(compressed.Bytes())), nil
+		})
+	mockSvc.On("GetJobOutput", mock.Anything, uint64(6)).Return(model.JobFile{}, nil, service.ErrJobOutputNotReady)
+	router := newJobRouter(mockSvc, 1<<20)
+
+	t.Run("plain", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs/5/result", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, `attachment; filename="orders-5.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "order_id\n1\n", w.Body.String())
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs/5/result", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, compressed.Bytes(), w.Body.Bytes())
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs/6/result", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
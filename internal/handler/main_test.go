@@ -0,0 +1,39 @@
+package handler_test
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/middleware"
+	"github.com/MidnightHelix/assignment-2/internal/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+var validateOpenAPI gin.HandlerFunc
+
+// TestMain checks every request and response of the handler tests against
+// the OpenAPI document, so handlers and their annotations cannot drift.
+func TestMain(m *testing.M) {
+	doc, err := openapi.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// most tests mount their handler at the root instead of under the
+	// base path
+	doc.Servers = append(doc.Servers, &openapi3.Server{URL: "/"})
+	validateOpenAPI, err = middleware.OpenAPIValidation(doc, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	os.Exit(m.Run())
+}
+
+// newRouter returns an engine that validates its traffic like the API in
+// strict mode.
+func newRouter() *gin.Engine {
+	g := gin.New()
+	g.Use(validateOpenAPI)
+	return g
+}
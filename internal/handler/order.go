@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/MidnightHelix/assignment-2/internal/model"
 	"github.com/MidnightHelix/assignment-2/internal/service"
@@ -12,10 +16,17 @@ import (
 
 type OrderHandler interface {
 	GetOrders(ctx *gin.Context)
+	ExportOrders(ctx *gin.Context)
 	GetOrdersByID(ctx *gin.Context)
 	CreateOrder(ctx *gin.Context)
 	UpdateOrder(ctx *gin.Context)
+	PatchOrder(ctx *gin.Context)
 	DeleteOrder(ctx *gin.Context)
+	CreateOrders(ctx *gin.Context)
+	PatchOrders(ctx *gin.Context)
+	DeleteOrders(ctx *gin.Context)
+	GetOrderSummaries(ctx *gin.Context)
+	GetOrderHistory(ctx *gin.Context)
 }
 
 type orderHandlerImpl struct {
@@ -35,13 +46,24 @@ func NewOrderHandler(svc service.OrderService) OrderHandler {
 //	@Tags			orders
 //	@Accept			json
 //	@Produce		json
+//	@Param			customer_name	query		string	false	"Only orders of this customer"
+//	@Param			status			query		string	false	"Only orders with this status"
+//	@Param			ordered_from	query		string	false	"Only orders placed at or after this RFC 3339 time"
+//	@Param			ordered_to		query		string	false	"Only orders placed before this RFC 3339 time"
+//	@Param			limit			query		int		false	"Return at most this many orders, by ascending ID (1 to 1000)"
+//	@Param			after_id		query		int		false	"Only orders with a greater ID; pass the last ID of the previous page"
 //	@Success		200	{object}	[]model.Order
 //	@Failure		400	{object}	pkg.ErrorResponse
 //	@Failure		404	{object}	pkg.ErrorResponse
 //	@Failure		500	{object}	pkg.ErrorResponse
 //	@Router			/orders [get]
 func (u *orderHandlerImpl) GetOrders(ctx *gin.Context) {
-	orders, err := u.svc.GetOrders(ctx)
+	filter, err := orderFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	orders, err := u.svc.GetOrders(ctx, filter)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
 		return
@@ -49,6 +71,37 @@ func (u *orderHandlerImpl) GetOrders(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, orders)
 }
 
+// ShowOrderSummaries godoc
+//
+//	@Summary		Show order summaries
+//	@Description	Get item counts and quantity totals of every order
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]model.OrderSummary
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/orders/summaries [get]
+func (u *orderHandlerImpl) GetOrderSummaries(ctx *gin.Context) {
+	summaries, err := u.svc.GetOrderSummaries(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, summaries)
+}
+
+// ShowOrder godoc
+//
+//	@Summary		Show an order
+//	@Description	Get one order by ID
+//	@Tags			orders
+//	@Produce		json
+//	@Param			id	path		int	true	"Order ID"
+//	@Success		200	{object}	model.Order
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/orders/{id} [get]
 func (u *orderHandlerImpl) GetOrdersByID(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
@@ -56,6 +109,10 @@ func (u *orderHandlerImpl) GetOrdersByID(ctx *gin.Context) {
 		return
 	}
 	order, err := u.svc.GetOrdersById(ctx, uint64(id))
+	if errors.Is(err, service.ErrOrderNotFound) {
+		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Order Not Found"})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
 		return
@@ -63,19 +120,47 @@ func (u *orderHandlerImpl) GetOrdersByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, order)
 }
 
-//	 CreateOrder godoc
+// ShowOrderHistory godoc
+//
+//	@Summary		Show the history of an order
+//	@Description	Get the audit trail of an order, oldest first: who changed it, in which request, and the order before and after each change. The history of a deleted order remains available.
+//	@Tags			orders
+//	@Produce		json
+//	@Param			id	path		int	true	"Order ID"
+//	@Success		200	{object}	[]model.AuditEntry
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/orders/{id}/history [get]
+func (u *orderHandlerImpl) GetOrderHistory(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	entries, err := u.svc.GetOrderHistory(ctx, uint64(id))
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// CreateOrder godoc
 //
-//		@Summary		Create an order
-//		@Description	Create order with input payload
-//		@Tags			orders
-//		@Accept			json
-//		@Produce		json
-//		@Param order body Order true "Create Order"
-//		@Success		201	{object}	[]model.Order
-//		@Failure		400	{object}	pkg.ErrorResponse
-//		@Failure		404	{object}	pkg.ErrorResponse
-//		@Failure		500	{object}	pkg.ErrorResponse
-//		@Router			/orders [post]
+//	@Summary		Create an order
+//	@Description	Create order with input payload
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			order			body		model.Order	true	"Create Order"
+//	@Param			Idempotency-Key	header		string		false	"Run the request at most once per key"
+//	@Success		201				{object}	model.Order
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		409				{object}	pkg.ErrorResponse
+//	@Failure		422				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/orders [post]
 func (u *orderHandlerImpl) CreateOrder(ctx *gin.Context) {
 
 	order := model.Order{}
@@ -86,52 +171,87 @@ func (u *orderHandlerImpl) CreateOrder(ctx *gin.Context) {
 
 	order, err := u.svc.CreateOrder(ctx, order)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		orderError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, order)
 }
 
-//	 UpdateOrder godoc
+// UpdateOrder godoc
 //
-//		@Summary		Update an order
-//		@Description	Update order with input payload
-//		@Tags			orders
-//		@Accept			json
-//		@Produce		json
-//		@Param order body Order true "Update Order"
-//		@Param        id   path      int  true  "Order ID"
-//		@Success		200	{object}	[]model.Order
-//		@Failure		400	{object}	pkg.ErrorResponse
-//		@Failure		404	{object}	pkg.ErrorResponse
-//		@Failure		500	{object}	pkg.ErrorResponse
-//		@Router			/orders/{id} [put]
+//	@Summary		Update an order
+//	@Description	Update order with input payload. Listed items without a unit_price or tax_category keep the stored ones, and an order without a region keeps the stored one
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			order	body		model.Order	true	"Update Order"
+//	@Param			id		path		int			true	"Order ID"
+//	@Success		200		{object}	model.Order
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/orders/{id} [put]
 func (u *orderHandlerImpl) UpdateOrder(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
 		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
 		return
 	}
-	order, err := u.svc.GetOrdersById(ctx, uint64(id))
+
+	req := model.Order{ID: uint64(id)}
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	order, err := u.svc.UpdateOrder(ctx, req, uint64(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		orderError(ctx, err)
 		return
 	}
-	if order.ID == 0 {
-		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Order Not Found"})
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// PatchOrder godoc
+//
+//	@Summary		Patch an order
+//	@Description	Change part of an order with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902)
+//	@Tags			orders
+//	@Accept			application/merge-patch+json,application/json-patch+json
+//	@Produce		json
+//	@Param			id		path		int		true	"Order ID"
+//	@Param			patch	body		object	true	"Patch document"	extensions(x-any-json)
+//	@Success		200		{object}	model.Order
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		409		{object}	pkg.ErrorResponse
+//	@Failure		415		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/orders/{id} [patch]
+func (u *orderHandlerImpl) PatchOrder(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
 		return
 	}
 
-	req := model.Order{ID: uint64(id)}
-	if err := ctx.Bind(&req); err != nil {
+	typ := service.PatchType(ctx.ContentType())
+	if typ != service.MergePatch && typ != service.JSONPatch {
+		ctx.Header("Accept-Patch", string(service.MergePatch)+", "+string(service.JSONPatch))
+		ctx.JSON(http.StatusUnsupportedMediaType, pkg.ErrorResponse{Message: "unsupported patch content type"})
+		return
+	}
+	patch, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	order, err = u.svc.UpdateOrder(ctx, req, uint64(id))
+	order, err := u.svc.PatchOrder(ctx, uint64(id), patch, typ)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		orderError(ctx, err)
 		return
 	}
 
@@ -140,34 +260,28 @@ func (u *orderHandlerImpl) UpdateOrder(ctx *gin.Context) {
 
 // DeleteOrder godoc
 //
-// @Summary		Delete an order
-// @Description	Delete order with id param
-// @Tags			orders
-// @Accept			json
-// @Produce		json
-// @Param        id   path      int  true  "Order ID"
-// @Success		200	{object}	[]model.Order
-// @Failure		400	{object}	pkg.ErrorResponse
-// @Failure		404	{object}	pkg.ErrorResponse
-// @Failure		500	{object}	pkg.ErrorResponse
-// @Router			/orders/{id} [delete]
+//	@Summary		Delete an order
+//	@Description	Delete order with id param
+//	@Tags			orders
+//	@Produce		json
+//	@Param			id	path		int	true	"Order ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/orders/{id} [delete]
 func (u *orderHandlerImpl) DeleteOrder(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
 		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
 		return
 	}
-	order, err := u.svc.GetOrdersById(ctx, uint64(id))
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
-		return
-	}
-	if order.ID == 0 {
+
+	err = u.svc.DeleteOrder(ctx, uint64(id))
+	if errors.Is(err, service.ErrOrderNotFound) {
 		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Order Not Found"})
 		return
 	}
-
-	err = u.svc.DeleteOrder(ctx, uint64(id))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
 		return
@@ -177,3 +291,66 @@ func (u *orderHandlerImpl) DeleteOrder(ctx *gin.Context) {
 		"message": "Order deleted",
 	})
 }
+
+// maxOrderPageSize caps the limit of an order listing.
+const maxOrderPageSize = 1000
+
+// orderFilter reads the listing filters from the query string.
+func orderFilter(ctx *gin.Context) (model.OrderFilter, error) {
+	filter := model.OrderFilter{
+		CustomerName: ctx.Query("customer_name"),
+		Status:       ctx.Query("status"),
+	}
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"ordered_from", &filter.OrderedFrom}, {"ordered_to", &filter.OrderedTo}} {
+		v := ctx.Query(p.key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return model.OrderFilter{}, fmt.Errorf("invalid %s: expected an RFC 3339 time", p.key)
+		}
+		*p.dst = t
+	}
+	if v := ctx.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxOrderPageSize {
+			return model.OrderFilter{}, fmt.Errorf("invalid limit: expected 1 to %d", maxOrderPageSize)
+		}
+		filter.Limit = limit
+	}
+	if v := ctx.Query("after_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return model.OrderFilter{}, errors.New("invalid after_id")
+		}
+		filter.AfterID = id
+	}
+	return filter, nil
+}
+
+func orderError(ctx *gin.Context, err error) {
+	code, res := orderErrorResponse(err)
+	ctx.JSON(code, res)
+}
+
+func orderErrorResponse(err error) (int, pkg.ErrorResponse) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid order", Errors: validationErr.Errors}
+	case errors.Is(err, service.ErrInvalidPatch):
+		return http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()}
+	case errors.Is(err, service.ErrPatchConflict):
+		return http.StatusConflict, pkg.ErrorResponse{Message: err.Error()}
+	case errors.Is(err, service.ErrOrderNotFound):
+		return http.StatusNotFound, pkg.ErrorResponse{Message: "Order Not Found"}
+	case errors.Is(err, service.ErrBatchAborted):
+		return http.StatusFailedDependency, pkg.ErrorResponse{Message: err.Error()}
+	default:
+		return http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()}
+	}
+}
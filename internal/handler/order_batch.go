@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrders godoc
+//
+//	@Summary		Create orders in bulk
+//	@Description	Create up to MAX_BATCH_OPERATIONS orders. Orders and items are inserted with one statement each.
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		model.BatchCreateRequest	true	"Orders to create"
+//	@Success		201		{object}	model.BatchResponse
+//	@Success		207		{object}	model.BatchResponse	"Partial batch where some operations failed"
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		422		{object}	model.BatchResponse	"Atomic batch that was rolled back"
+//	@Router			/orders:batch [post]
+func (u *orderHandlerImpl) CreateOrders(ctx *gin.Context) {
+	req := model.BatchCreateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	mode := batchMode(req.Mode)
+	results, err := u.svc.CreateOrders(ctx, req.Orders, mode)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	batchResponse(ctx, http.StatusCreated, mode, results, true)
+}
+
+// PatchOrders godoc
+//
+//	@Summary		Patch orders in bulk
+//	@Description	Apply a JSON Merge Patch (object) or JSON Patch (array) to each listed order
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		model.BatchPatchRequest	true	"Patches to apply"
+//	@Success		200		{object}	model.BatchResponse
+//	@Success		207		{object}	model.BatchResponse	"Partial batch where some operations failed"
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		422		{object}	model.BatchResponse	"Atomic batch that was rolled back"
+//	@Router			/orders:batch [patch]
+func (u *orderHandlerImpl) PatchOrders(ctx *gin.Context) {
+	req := model.BatchPatchRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	patches := make([]service.OrderPatch, 0, len(req.Operations))
+	for _, op := range req.Operations {
+		typ := service.MergePatch
+		if bytes.HasPrefix(bytes.TrimSpace(op.Patch), []byte("[")) {
+			typ = service.JSONPatch
+		}
+		patches = append(patches, service.OrderPatch{ID: op.OrderID, Patch: op.Patch, Type: typ})
+	}
+
+	mode := batchMode(req.Mode)
+	results, err := u.svc.PatchOrders(ctx, patches, mode)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	batchResponse(ctx, http.StatusOK, mode, results, true)
+}
+
+// DeleteOrders godoc
+//
+//	@Summary		Delete orders in bulk
+//	@Description	Delete every listed order
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		model.BatchDeleteRequest	true	"Orders to delete"
+//	@Success		200		{object}	model.BatchResponse
+//	@Success		207		{object}	model.BatchResponse	"Partial batch where some operations failed"
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		422		{object}	model.BatchResponse	"Atomic batch that was rolled back"
+//	@Router			/orders:batch [delete]
+func (u *orderHandlerImpl) DeleteOrders(ctx *gin.Context) {
+	req := model.BatchDeleteRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	mode := batchMode(req.Mode)
+	results, err := u.svc.DeleteOrders(ctx, req.OrderIDs, mode)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	batchResponse(ctx, http.StatusOK, mode, results, false)
+}
+
+func batchMode(mode string) service.BatchMode {
+	if mode == "" {
+		return service.BatchAtomic
+	}
+	return service.BatchMode(mode)
+}
+
+// batchResponse writes the per-operation results. The response has status ok
+// when every operation succeeded, 207 when a partial batch had failures and
+// 422 when an atomic batch was rolled back.
+func batchResponse(ctx *gin.Context, ok int, mode service.BatchMode, results []service.BatchResult, withOrder bool) {
+	res := model.BatchResponse{Results: make([]model.BatchOperationResult, 0, len(results))}
+	for i, r := range results {
+		op := model.BatchOperationResult{Index: i, Status: ok}
+		if r.Err != nil {
+			code, errRes := orderErrorResponse(r.Err)
+			op.Status, op.Message, op.Errors = code, errRes.Message, errRes.Errors
+			res.Failed++
+		} else {
+			order := r.Order
+			op.OrderID = order.ID
+			if withOrder {
+				op.Order = &order
+			}
+			res.Succeeded++
+		}
+		res.Results = append(res.Results, op)
+	}
+
+	code := ok
+	if res.Failed > 0 {
+		code = http.StatusMultiStatus
+		if mode == service.BatchAtomic {
+			code = http.StatusUnprocessableEntity
+		}
+	}
+	ctx.JSON(code, res)
+}
@@ -0,0 +1,129 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/router"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func newBatchRouter(svc service.OrderService) *gin.Engine {
+	g := newRouter()
+	v := g.Group("/api/v1")
+	hdl := handler.NewOrderHandler(svc)
+	router.NewOrderRouter(v.Group("/orders"), hdl).Mount()
+	router.NewOrderBatchRouter(v, hdl).Mount()
+	return g
+}
+
+func doBatch(g *gin.Engine, method, path, body string) (*httptest.ResponseRecorder, model.BatchResponse) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	g.ServeHTTP(w, req)
+	res := model.BatchResponse{}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	return w, res
+}
+
+func TestCreateOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("all created", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("CreateOrders", mock.Anything, mock.Anything, service.BatchAtomic).Return([]service.BatchResult{
+			{Order: model.Order{ID: 1, CustomerName: "a"}},
+			{Order: model.Order{ID: 2, CustomerName: "b"}},
+		}, nil)
+
+		w, res := doBatch(newBatchRouter(mockSvc), "POST", "/api/v1/orders:batch", `{"orders":[{"customer_name":"a"},{"customer_name":"b"}]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 2, res.Succeeded)
+		assert.Equal(t, uint64(2), res.Results[1].OrderID)
+		assert.Equal(t, "b", res.Results[1].Order.CustomerName)
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("CreateOrders", mock.Anything, mock.Anything, service.BatchPartial).Return([]service.BatchResult{
+			{Order: model.Order{ID: 1, CustomerName: "a"}},
+			{Err: &service.ValidationError{Errors: []string{"customer_name is required"}}},
+		}, nil)
+
+		w, res := doBatch(newBatchRouter(mockSvc), "POST", "/api/v1/orders:batch", `{"mode":"partial","orders":[{"customer_name":"a"},{}]}`)
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Equal(t, 1, res.Failed)
+		assert.Equal(t, http.StatusBadRequest, res.Results[1].Status)
+		assert.Equal(t, []string{"customer_name is required"}, res.Results[1].Errors)
+	})
+
+	t.Run("invalid batch", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("CreateOrders", mock.Anything, mock.Anything, service.BatchMode("sometimes")).Return(nil, &service.ValidationError{Errors: []string{`unknown mode "sometimes"`}})
+
+		w, _ := doBatch(newBatchRouter(mockSvc), "POST", "/api/v1/orders:batch", `{"mode":"sometimes","orders":[{}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown custom method", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+
+		w, _ := doBatch(newBatchRouter(mockSvc), "POST", "/api/v1/orders:merge", `{}`)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockSvc.AssertNotCalled(t, "CreateOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPatchOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("PatchOrders", mock.Anything, []service.OrderPatch{
+		{ID: 1, Patch: []byte(`{"status":"paid"}`), Type: service.MergePatch},
+		{ID: 2, Patch: []byte(`[{"op":"remove","path":"/items/0"}]`), Type: service.JSONPatch},
+	}, service.BatchAtomic).Return([]service.BatchResult{
+		{Err: service.ErrBatchAborted},
+		{Err: service.ErrOrderNotFound},
+	}, nil)
+
+	w, res := doBatch(newBatchRouter(mockSvc), "PATCH", "/api/v1/orders:batch", `{"operations":[
+		{"order_id":1,"patch":{"status":"paid"}},
+		{"order_id":2,"patch":[{"op":"remove","path":"/items/0"}]}
+	]}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, http.StatusFailedDependency, res.Results[0].Status)
+	assert.Equal(t, http.StatusNotFound, res.Results[1].Status)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestDeleteOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("DeleteOrders", mock.Anything, []uint64{1, 2}, service.BatchAtomic).Return([]service.BatchResult{
+		{Order: model.Order{ID: 1}},
+		{Order: model.Order{ID: 2}},
+	}, nil)
+
+	w, res := doBatch(newBatchRouter(mockSvc), "DELETE", "/api/v1/orders:batch", `{"order_ids":[1,2]}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint64(1), res.Results[0].OrderID)
+	assert.Nil(t, res.Results[0].Order)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/exporter"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportOrders godoc
+//
+//	@Summary		Export orders
+//	@Description	Stream every order matching the listing filters as CSV, one row per item, or as NDJSON, one order per line. The response is gzip encoded when the client accepts it.
+//	@Tags			orders
+//	@Produce		text/csv,application/x-ndjson,json
+//	@Param			format			query		string	false	"csv (default) or ndjson"
+//	@Param			customer_name	query		string	false	"Only orders of this customer"
+//	@Param			status			query		string	false	"Only orders with this status"
+//	@Param			ordered_from	query		string	false	"Only orders placed at or after this RFC 3339 time"
+//	@Param			ordered_to		query		string	false	"Only orders placed before this RFC 3339 time"
+//	@Success		200	{file}		file
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/orders/export [get]
+func (u *orderHandlerImpl) ExportOrders(ctx *gin.Context) {
+	format := exporter.Format(ctx.DefaultQuery("format", string(exporter.CSV)))
+	if format.ContentType() == "" {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "format must be csv or ndjson"})
+		return
+	}
+	filter, err := orderFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	exp := &orderExporter{ctx: ctx, format: format}
+	err = u.svc.ExportOrders(ctx, filter, exp.write)
+	if err == nil {
+		err = exp.close()
+	}
+	if err == nil {
+		return
+	}
+	if !exp.started {
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	// the status is already sent; drop the connection so the client sees a
+	// broken download instead of a silently truncated one
+	log.Printf("order export failed: %v", err)
+	if conn, _, err := ctx.Writer.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// orderExporter sends the response headers with the first chunk, so a query
+// that fails right away can still be answered with an error status.
+type orderExporter struct {
+	ctx     *gin.Context
+	format  exporter.Format
+	started bool
+	gz      *gzip.Writer
+	w       *exporter.Writer
+}
+
+func (e *orderExporter) start() {
+	e.started = true
+	h := e.ctx.Writer.Header()
+	h.Set("Content-Type", e.format.ContentType())
+	h.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="orders-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), e.format))
+	h.Add("Vary", "Accept-Encoding")
+
+	var w io.Writer = e.ctx.Writer
+	if acceptsGzip(e.ctx.GetHeader("Accept-Encoding")) {
+		h.Set("Content-Encoding", "gzip")
+		e.gz = gzip.NewWriter(w)
+		w = e.gz
+	}
+	e.ctx.Status(http.StatusOK)
+	// the format was checked by ExportOrders
+	e.w, _ = exporter.NewWriter(w, e.format)
+}
+
+func (e *orderExporter) write(orders []model.Order) error {
+	if !e.started {
+		e.start()
+	}
+	if err := e.w.Write(orders); err != nil {
+		return err
+	}
+	if e.gz != nil {
+		if err := e.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	e.ctx.Writer.Flush()
+	return nil
+}
+
+// close finishes the body. An export without any order still gets its
+// headers and, for CSV, the header row.
+func (e *orderExporter) close() error {
+	if !e.started {
+		if err := e.write(nil); err != nil {
+			return err
+		}
+	}
+	if e.gz != nil {
+		return e.gz.Close()
+	}
+	return nil
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
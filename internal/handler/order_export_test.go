@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func exportOrders(orders ...[]model.Order) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func([]model.Order) error)
+		for _, chunk := range orders {
+			fn(chunk)
+		}
+	}
+}
+
+func TestExportOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	orderedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	chunk1 := []model.Order{{ID: 1, CustomerName: "alice", Status: "paid", OrderedAt: orderedAt, Items: []model.Item{
+		{ID: 10, ItemCode: "A", Description: "apple, red", Quantity: 2},
+		{ID: 11, ItemCode: "B", Quantity: 1},
+	}}}
+	chunk2 := []model.Order{{ID: 2, CustomerName: "bob", Status: "pending", OrderedAt: orderedAt}}
+
+	t.Run("csv", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("ExportOrders", mock.Anything, model.OrderFilter{Status: "paid"}, mock.Anything).Run(exportOrders(chunk1, chunk2)).Return(nil)
+
+		router := newRouter()
+		router.GET("/orders/export", handler.NewOrderHandler(mockSvc).ExportOrders)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/orders/export?status=paid", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename="orders-\d{8}T\d{6}Z\.csv"$`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, strings.Join([]string{
+			"order_id,customer_name,status,ordered_at,item_id,item_code,description,quantity",
+			`1,alice,paid,2024-03-01T10:00:00Z,10,A,"apple, red",2`,
+			"1,alice,paid,2024-03-01T10:00:00Z,11,B,,1",
+			"2,bob,pending,2024-03-01T10:00:00Z,,,,",
+			"",
+		}, "\n"), w.Body.String())
+	})
+
+	t.Run("gzip ndjson", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("ExportOrders", mock.Anything, model.OrderFilter{}, mock.Anything).Run(exportOrders(chunk1, chunk2)).Return(nil)
+
+		router := newRouter()
+		router.GET("/orders/export", handler.NewOrderHandler(mockSvc).ExportOrders)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/orders/export?format=ndjson", nil)
+		req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		gz, err := gzip.NewReader(w.Body)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		assert.Len(t, lines, 2)
+		assert.Contains(t, lines[1], `"customer_name":"bob"`)
+	})
+
+	t.Run("error before the first chunk", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("ExportOrders", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+		router := newRouter()
+		router.GET("/orders/export", handler.NewOrderHandler(mockSvc).ExportOrders)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/orders/export", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("bad parameters", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+
+		router := newRouter()
+		router.GET("/orders/export", handler.NewOrderHandler(mockSvc).ExportOrders)
+
+		for _, q := range []string{"format=xlsx", "ordered_from=yesterday"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/orders/export?"+q, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, q)
+		}
+		mockSvc.AssertNotCalled(t, "ExportOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
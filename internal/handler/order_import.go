@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/MidnightHelix/assignment-2/internal/importer"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderImportHandler interface {
+	ImportOrders(ctx *gin.Context)
+}
+
+type orderImportHandlerImpl struct {
+	importer         importer.Importer
+	defaultBatchSize int
+	maxBatchSize     int
+}
+
+// NewOrderImportHandler returns a handler that commits imports in batches of
+// defaultBatchSize orders unless the request asks for another size of at
+// most maxBatchSize.
+func NewOrderImportHandler(imp importer.Importer, defaultBatchSize, maxBatchSize int) OrderImportHandler {
+	return &orderImportHandlerImpl{importer: imp, defaultBatchSize: defaultBatchSize, maxBatchSize: maxBatchSize}
+}
+
+// ImportOrders godoc
+//
+//	@Summary		Import orders
+//	@Description	Import orders from a CSV file with one row per item, grouped by order_ref, or from an NDJSON file with one order per line. The file is the request body or the "file" field of a multipart form. Every order is validated like POST /orders and problems are reported per row.
+//	@Tags			orders
+//	@Accept			text/csv,application/x-ndjson,multipart/form-data
+//	@Produce		json
+//	@Param			format		query		string	false	"csv or ndjson, by default taken from the content type or file name"
+//	@Param			dry_run		query		bool	false	"Validate without storing"
+//	@Param			batch_size	query		int		false	"Orders per transaction"
+//	@Success		200			{object}	importer.Report
+//	@Failure		400			{object}	importer.Report
+//	@Failure		500			{object}	importer.Report
+//	@Router			/orders/import [post]
+func (u *orderImportHandlerImpl) ImportOrders(ctx *gin.Context) {
+	opts := importer.Options{BatchSize: u.defaultBatchSize}
+	if v := ctx.Query("batch_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > u.maxBatchSize {
+			ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "batch_size must be between 1 and " + strconv.Itoa(u.maxBatchSize)})
+			return
+		}
+		opts.BatchSize = n
+	}
+	if v := ctx.Query("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "dry_run must be a boolean"})
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	body, name, err := importFile(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	defer body.Close()
+
+	opts.Format = importer.Format(ctx.Query("format"))
+	if opts.Format == "" {
+		opts.Format = importer.DetectFormat(ctx.ContentType(), name)
+	}
+	if opts.Format != importer.CSV && opts.Format != importer.NDJSON {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "format must be csv or ndjson"})
+		return
+	}
+
+	rep, err := u.importer.Import(ctx, body, opts)
+	switch {
+	case errors.Is(err, importer.ErrInvalidFile):
+		ctx.JSON(http.StatusBadRequest, rep)
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, rep)
+	default:
+		ctx.JSON(http.StatusOK, rep)
+	}
+}
+
+// importFile returns the uploaded file and its name, if it has one.
+func importFile(ctx *gin.Context) (io.ReadCloser, string, error) {
+	if ctx.ContentType() != "multipart/form-data" {
+		return ctx.Request.Body, "", nil
+	}
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	return f, fh.Filename, nil
+}
@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/importer"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func newImportRouter(svc service.OrderService) *gin.Engine {
+	router := newRouter()
+	router.POST("/orders/import", handler.NewOrderImportHandler(importer.NewImporter(svc), 100, 500).ImportOrders)
+	return router
+}
+
+func TestImportOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("csv dry run", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("ValidateOrder", mock.Anything, mock.Anything).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/orders/import?dry_run=true", strings.NewReader("order_ref,customer_name,item_code,quantity\nL-1,alice,A,1\nL-2,bob,B,-1\n"))
+		req.Header.Set("Content-Type", "text/csv")
+		newImportRouter(mockSvc).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		rep := importer.Report{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rep))
+		assert.True(t, rep.DryRun)
+		assert.Equal(t, 1, rep.Imported)
+		assert.Equal(t, []importer.RowError{{Line: 3, OrderRef: "L-2", Errors: []string{`quantity "-1" is not a whole number`}}}, rep.Errors)
+		mockSvc.AssertNotCalled(t, "CreateOrders", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("multipart ndjson", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("CreateOrders", mock.Anything, []model.Order{{CustomerName: "alice"}}, service.BatchPartial).
+			Return([]service.BatchResult{{Order: model.Order{ID: 1, CustomerName: "alice"}}}, nil)
+
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile("file", "orders.ndjson")
+		fw.Write([]byte(`{"customer_name":"alice"}` + "\n"))
+		mw.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/orders/import", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		newImportRouter(mockSvc).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"imported":1`)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/orders/import?format=csv", strings.NewReader("customer_name\nalice\n"))
+		newImportRouter(mockSvc).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing columns")
+	})
+
+	t.Run("bad parameters", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+
+		for _, q := range []string{"batch_size=0", "batch_size=501", "dry_run=maybe", "format=xlsx", ""} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/orders/import?"+q, strings.NewReader(""))
+			newImportRouter(mockSvc).ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, q)
+		}
+	})
+}
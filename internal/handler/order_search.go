@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderSearchHandler interface {
+	SearchOrders(ctx *gin.Context)
+}
+
+type orderSearchHandlerImpl struct {
+	svc          service.OrderSearchService
+	defaultLimit int
+}
+
+func NewOrderSearchHandler(svc service.OrderSearchService, defaultLimit int) OrderSearchHandler {
+	return &orderSearchHandlerImpl{svc: svc, defaultLimit: defaultLimit}
+}
+
+// SearchOrders godoc
+//
+//	@Summary		Search orders
+//	@Description	Find orders whose customer name, item codes or item descriptions contain every word of q, also as a prefix. Hits are ranked, customer names first, and carry the matched fragments with matches wrapped in <mark> tags. When nothing matches, orders with similarly spelled words are returned and fuzzy is set.
+//	@Tags			orders
+//	@Produce		json
+//	@Param			q		query		string	true	"Search text"
+//	@Param			limit	query		int		false	"Maximum number of hits"
+//	@Success		200		{object}	model.OrderSearchResponse
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/orders/search [get]
+func (u *orderSearchHandlerImpl) SearchOrders(ctx *gin.Context) {
+	limit := u.defaultLimit
+	if v := ctx.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "limit must be a number"})
+			return
+		}
+		limit = n
+	}
+
+	res, err := u.svc.SearchOrders(ctx, ctx.Query("q"), limit)
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid search", Errors: validationErr.Errors})
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+	default:
+		ctx.JSON(http.StatusOK, res)
+	}
+}
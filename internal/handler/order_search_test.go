@@ -0,0 +1,54 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func TestSearchOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderSearchService{}
+	mockSvc.On("SearchOrders", mock.Anything, "jon smi", 20).Return(model.OrderSearchResponse{
+		Query: "jon smi",
+		Hits: []model.OrderSearchHit{{
+			Order:      model.Order{ID: 1, CustomerName: "Jon Smith"},
+			Rank:       0.6,
+			Highlights: []model.SearchHighlight{{Field: "customer_name", Fragment: "<mark>Jon</mark> <mark>Smi</mark>th"}},
+		}},
+	}, nil)
+	mockSvc.On("SearchOrders", mock.Anything, "", 5).Return(model.OrderSearchResponse{}, &service.ValidationError{Errors: []string{"q must contain a letter or digit"}})
+
+	hdl := handler.NewOrderSearchHandler(mockSvc, 20)
+	router := newRouter()
+	router.GET("/orders/search", hdl.SearchOrders)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/search?q=jon+smi", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res model.OrderSearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "<mark>Jon</mark> <mark>Smi</mark>th", res.Hits[0].Highlights[0].Fragment)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/orders/search?limit=5", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/orders/search?q=jon&limit=many", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
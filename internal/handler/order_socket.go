@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/MidnightHelix/assignment-2/internal/ws"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type OrderSocketHandler interface {
+	ServeWS(ctx *gin.Context)
+}
+
+type orderSocketHandlerImpl struct {
+	hub      ws.Hub
+	upgrader websocket.Upgrader
+}
+
+func NewOrderSocketHandler(hub ws.Hub) OrderSocketHandler {
+	return &orderSocketHandlerImpl{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+}
+
+// ServeWS godoc
+//
+//	@Summary		Live order tracking
+//	@Description	Upgrade to a WebSocket. Send {"action":"subscribe","order_ids":[1],"customers":["alice"]} to follow orders; matching events arrive as {"type":"event","event":{...}}.
+//	@Tags			orders
+//	@Param			access_token	query	string	false	"API key, for clients that cannot set headers"
+//	@Success		101
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Router			/ws [get]
+func (u *orderSocketHandlerImpl) ServeWS(ctx *gin.Context) {
+	principal, _ := auth.FromContext(ctx.Request.Context())
+	tenantID, _ := tenant.FromContext(ctx.Request.Context())
+
+	// Upgrade writes its own error response on failure
+	conn, err := u.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+	u.hub.Serve(conn, principal, tenantID)
+}
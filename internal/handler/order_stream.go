@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// replayBatch is how many stored events are read per query while a client
+// catches up.
+const replayBatch = 500
+
+type OrderStreamHandler interface {
+	StreamOrders(ctx *gin.Context)
+}
+
+type orderStreamHandlerImpl struct {
+	svc          service.EventService
+	heartbeat    time.Duration
+	writeTimeout time.Duration
+}
+
+// NewOrderStreamHandler streams order events over Server-Sent Events,
+// writing a heartbeat comment every heartbeat and abandoning clients that
+// cannot take a write within writeTimeout.
+func NewOrderStreamHandler(svc service.EventService, heartbeat, writeTimeout time.Duration) OrderStreamHandler {
+	return &orderStreamHandlerImpl{
+		svc:          svc,
+		heartbeat:    heartbeat,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// StreamOrders godoc
+//
+//	@Summary		Stream order changes
+//	@Description	Push order events as Server-Sent Events. Reconnecting with Last-Event-ID resumes after that event.
+//	@Tags			orders
+//	@Produce		text/event-stream
+//	@Param			Last-Event-ID	header		int		false	"Resume after this event"
+//	@Param			last_event_id	query		int		false	"Resume after this event, for clients that cannot set headers"
+//	@Param			access_token	query		string	false	"API key, for clients that cannot set headers"
+//	@Param			customer_name	query		string	false	"Only events of this customer"
+//	@Param			status			query		string	false	"Only events of orders in these comma-separated statuses"
+//	@Success		200				{object}	event.Event
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/orders/stream [get]
+func (u *orderStreamHandlerImpl) StreamOrders(ctx *gin.Context) {
+	lastID, resume, err := lastEventID(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid Last-Event-ID"})
+		return
+	}
+	filter := event.Filter{CustomerName: ctx.Query("customer_name")}
+	if status := ctx.Query("status"); status != "" {
+		filter.Statuses = strings.Split(status, ",")
+	}
+
+	// subscribe before catching up so nothing published in between is lost
+	sub := u.svc.Subscribe(ctx)
+	defer sub.Close()
+
+	// only resuming clients catch up; new ones start with live events
+	backlog := []event.Event{}
+	if resume {
+		backlog, err = u.svc.GetEventsSince(ctx, lastID, replayBatch)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	rc := http.NewResponseController(ctx.Writer)
+	write := func(fn func(w io.Writer) error) bool {
+		// a client that stops reading must not pin this goroutine forever
+		_ = rc.SetWriteDeadline(time.Now().Add(u.writeTimeout))
+		if err := fn(ctx.Writer); err != nil {
+			return false
+		}
+		ctx.Writer.Flush()
+		return true
+	}
+	send := func(e event.Event) bool {
+		if !filter.Match(e) {
+			return true
+		}
+		return write(func(w io.Writer) error { return writeSSE(w, e) })
+	}
+
+	if !write(func(w io.Writer) error {
+		_, err := io.WriteString(w, "retry: 3000\n\n")
+		return err
+	}) {
+		return
+	}
+
+	// catch up from the outbox; live events already replayed are skipped
+	for len(backlog) > 0 {
+		for _, e := range backlog {
+			lastID = e.Sequence
+			if !send(e) {
+				return
+			}
+		}
+		if len(backlog) < replayBatch {
+			break
+		}
+		if backlog, err = u.svc.GetEventsSince(ctx, lastID, replayBatch); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(u.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				// dropped for falling behind or shutting down; the client
+				// reconnects with Last-Event-ID and catches up
+				return
+			}
+			if e.Sequence <= lastID {
+				continue
+			}
+			if !send(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err
+			}) {
+				return
+			}
+		}
+	}
+}
+
+// lastEventID reports the sequence number of the event a reconnecting client
+// saw last, and whether the client is resuming at all.
+func lastEventID(ctx *gin.Context) (uint64, bool, error) {
+	v := ctx.GetHeader("Last-Event-ID")
+	if v == "" {
+		v = ctx.Query("last_event_id")
+	}
+	if v == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	return id, true, err
+}
+
+func writeSSE(w io.Writer, e event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Sequence, e.Type, data)
+	return err
+}
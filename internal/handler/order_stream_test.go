@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func newOrderEvent(id uint64, t event.Type, customer string) event.Event {
+	e := event.New(t, model.Order{ID: id, CustomerName: customer, Status: model.OrderStatusPending})
+	e.ID = id
+	e.Sequence = id
+	return e
+}
+
+func TestStreamOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.EventService{}
+
+	broker := event.NewBroker(8)
+	mockSvc.On("Subscribe", mock.Anything).Return(broker.Subscribe())
+	mockSvc.On("GetEventsSince", mock.Anything, uint64(5), mock.Anything).Return([]event.Event{
+		newOrderEvent(6, event.OrderCreated, "alice"),
+		newOrderEvent(7, event.OrderCreated, "bob"),
+	}, nil)
+
+	// live events: a duplicate of a replayed one and a new one; closing the
+	// broker ends the stream once they are drained
+	broker.Publish(context.Background(), newOrderEvent(6, event.OrderCreated, "alice"))
+	broker.Publish(context.Background(), newOrderEvent(8, event.OrderUpdated, "alice"))
+	broker.Close()
+
+	handler := handler.NewOrderStreamHandler(mockSvc, time.Minute, time.Second)
+
+	router := newRouter()
+	router.GET("/orders/stream", handler.StreamOrders)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/stream?customer_name=alice", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "retry: 3000\n\n")
+	assert.Equal(t, 1, strings.Count(body, "id: 6\nevent: order.created\n"))
+	assert.NotContains(t, body, "id: 7\n")
+	assert.Contains(t, body, "id: 8\nevent: order.updated\ndata: {")
+
+	mockSvc.AssertCalled(t, "GetEventsSince", mock.Anything, uint64(5), mock.Anything)
+}
+
+func TestStreamOrdersInvalidLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.EventService{}
+
+	handler := handler.NewOrderStreamHandler(mockSvc, time.Minute, time.Second)
+
+	router := newRouter()
+	router.GET("/orders/stream", handler.StreamOrders)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "Subscribe")
+}
+
+func TestStreamOrdersLiveOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.EventService{}
+
+	broker := event.NewBroker(8)
+	mockSvc.On("Subscribe", mock.Anything).Return(broker.Subscribe())
+	broker.Publish(context.Background(), newOrderEvent(9, event.OrderDeleted, "bob"))
+	broker.Close()
+
+	handler := handler.NewOrderStreamHandler(mockSvc, time.Minute, time.Second)
+
+	router := newRouter()
+	router.GET("/orders/stream", handler.StreamOrders)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/stream", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "id: 9\nevent: order.deleted\n")
+	mockSvc.AssertNotCalled(t, "GetEventsSince", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/MidnightHelix/assignment-2/internal/handler"
 	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,11 +23,11 @@ func TestGetOrders(t *testing.T) {
 
 	mockSvc := &mocks.OrderService{}
 
-	mockSvc.On("GetOrders", mock.Anything).Return([]model.Order{}, nil)
+	mockSvc.On("GetOrders", mock.Anything, model.OrderFilter{}).Return([]model.Order{}, nil)
 
 	handler := handler.NewOrderHandler(mockSvc)
 
-	router := gin.New()
+	router := newRouter()
 	router.GET("/orders", handler.GetOrders)
 
 	w := httptest.NewRecorder()
@@ -37,7 +39,77 @@ func TestGetOrders(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &orders)
 	assert.NoError(t, err)
 
-	mockSvc.AssertCalled(t, "GetOrders", mock.Anything)
+	mockSvc.AssertCalled(t, "GetOrders", mock.Anything, model.OrderFilter{})
+}
+
+func TestGetOrdersFiltered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+
+	filter := model.OrderFilter{
+		CustomerName: "alice",
+		Status:       model.OrderStatusPaid,
+		OrderedFrom:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mockSvc.On("GetOrders", mock.Anything, filter).Return([]model.Order{}, nil)
+
+	handler := handler.NewOrderHandler(mockSvc)
+
+	router := newRouter()
+	router.GET("/orders", handler.GetOrders)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders?customer_name=alice&status=paid&ordered_from=2024-03-01T00:00:00Z", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestGetOrdersPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetOrders", mock.Anything, model.OrderFilter{AfterID: 10, Limit: 2}).Return([]model.Order{}, nil)
+
+	router := newRouter()
+	router.GET("/orders", handler.NewOrderHandler(mockSvc).GetOrders)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders?limit=2&after_id=10", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/orders?limit=5000", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetOrdersByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(1)).Return(model.Order{ID: 1, CustomerName: "customer"}, nil)
+	mockSvc.On("GetOrdersById", mock.Anything, uint64(2)).Return(model.Order{}, service.ErrOrderNotFound)
+
+	router := newRouter()
+	router.GET("/orders/:id", handler.NewOrderHandler(mockSvc).GetOrdersByID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var order model.Order
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	assert.Equal(t, "customer", order.CustomerName)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/orders/2", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 func TestCreateOrder(t *testing.T) {
@@ -51,7 +123,7 @@ func TestCreateOrder(t *testing.T) {
 
 	handler := handler.NewOrderHandler(mockSvc)
 
-	router := gin.New()
+	router := newRouter()
 	router.POST("/orders", handler.CreateOrder)
 
 	requestBody, err := json.Marshal(mockOrder)
@@ -74,13 +146,11 @@ func TestUpdateOrder(t *testing.T) {
 	mockID := uint64(1)
 	mockOrder := model.Order{ID: mockID, CustomerName: "customer"}
 
-	mockSvc.On("GetOrdersById", mock.Anything, mockID).Return(mockOrder, nil)
-
 	mockSvc.On("UpdateOrder", mock.Anything, mock.Anything, mockID).Return(mockOrder, nil)
 
 	handler := handler.NewOrderHandler(mockSvc)
 
-	router := gin.New()
+	router := newRouter()
 	router.PUT("/orders/:id", handler.UpdateOrder)
 
 	requestBody, err := json.Marshal(mockOrder)
@@ -92,11 +162,10 @@ func TestUpdateOrder(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	mockSvc.AssertCalled(t, "GetOrdersById", mock.Anything, mockID)
 	mockSvc.AssertCalled(t, "UpdateOrder", mock.Anything, mock.Anything, mockID)
 }
 
-func TestDeleteOrder(t *testing.T) {
+func TestUpdateOrderNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockSvc := &mocks.OrderService{}
@@ -104,13 +173,35 @@ func TestDeleteOrder(t *testing.T) {
 	mockID := uint64(1)
 	mockOrder := model.Order{ID: mockID, CustomerName: "customer"}
 
-	mockSvc.On("GetOrdersById", mock.Anything, mockID).Return(mockOrder, nil)
+	mockSvc.On("UpdateOrder", mock.Anything, mock.Anything, mockID).Return(model.Order{}, service.ErrOrderNotFound)
+
+	handler := handler.NewOrderHandler(mockSvc)
+
+	router := newRouter()
+	router.PUT("/orders/:id", handler.UpdateOrder)
+
+	requestBody, err := json.Marshal(mockOrder)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/orders/1", bytes.NewBuffer(requestBody))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+
+	mockID := uint64(1)
 
 	mockSvc.On("DeleteOrder", mock.Anything, mockID).Return(nil)
 
 	handler := handler.NewOrderHandler(mockSvc)
 
-	router := gin.New()
+	router := newRouter()
 	router.DELETE("/orders/:id", handler.DeleteOrder)
 
 	w := httptest.NewRecorder()
@@ -119,6 +210,151 @@ func TestDeleteOrder(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	mockSvc.AssertCalled(t, "GetOrdersById", mock.Anything, mockID)
 	mockSvc.AssertCalled(t, "DeleteOrder", mock.Anything, mockID)
 }
+
+func TestDeleteOrderNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+
+	mockID := uint64(1)
+
+	mockSvc.On("DeleteOrder", mock.Anything, mockID).Return(service.ErrOrderNotFound)
+
+	handler := handler.NewOrderHandler(mockSvc)
+
+	router := newRouter()
+	router.DELETE("/orders/:id", handler.DeleteOrder)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/orders/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetOrderSummaries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+
+	mockSummaries := []model.OrderSummary{{OrderID: 1, CustomerName: "customer", ItemCount: 2, TotalQuantity: 5}}
+	mockSvc.On("GetOrderSummaries", mock.Anything).Return(mockSummaries, nil)
+
+	handler := handler.NewOrderHandler(mockSvc)
+
+	router := newRouter()
+	router.GET("/orders/summaries", handler.GetOrderSummaries)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/summaries", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var summaries []model.OrderSummary
+	err := json.Unmarshal(w.Body.Bytes(), &summaries)
+	assert.NoError(t, err)
+	assert.Equal(t, mockSummaries, summaries)
+
+	mockSvc.AssertCalled(t, "GetOrderSummaries", mock.Anything)
+}
+
+func TestGetOrderHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.OrderService{}
+
+	mockEntries := []model.AuditEntry{
+		{ID: 1, OrderID: 1, Operation: model.AuditCreate, Actor: "alice", After: model.RawJSON(`{"order_id":1}`)},
+		{ID: 2, OrderID: 1, Operation: model.AuditUpdate, Actor: "bob", Changes: model.RawJSON(`{"status":"paid"}`)},
+	}
+	mockSvc.On("GetOrderHistory", mock.Anything, uint64(1)).Return(mockEntries, nil)
+	mockSvc.On("GetOrderHistory", mock.Anything, uint64(2)).Return(nil, service.ErrOrderNotFound)
+
+	handler := handler.NewOrderHandler(mockSvc)
+
+	router := newRouter()
+	router.GET("/orders/:id/history", handler.GetOrderHistory)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/orders/1/history", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var entries []model.AuditEntry
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	assert.Equal(t, "bob", entries[1].Actor)
+	assert.JSONEq(t, `{"status":"paid"}`, string(entries[1].Changes))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/orders/2/history", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPatchOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockID := uint64(1)
+	patch := []byte(`{"customer_name":"bob"}`)
+
+	t.Run("merge patch", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("PatchOrder", mock.Anything, mockID, patch, service.MergePatch).Return(model.Order{ID: mockID, CustomerName: "bob"}, nil)
+
+		router := newRouter()
+		router.PATCH("/orders/:id", handler.NewOrderHandler(mockSvc).PatchOrder)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/orders/1", bytes.NewBuffer(patch))
+		req.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+
+		router := newRouter()
+		router.PATCH("/orders/:id", handler.NewOrderHandler(mockSvc).PatchOrder)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/orders/1", bytes.NewBuffer(patch))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, w.Header().Get("Accept-Patch"), "application/json-patch+json")
+		mockSvc.AssertNotCalled(t, "PatchOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	errCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"invalid order", &service.ValidationError{Errors: []string{"customer_name is required"}}, http.StatusBadRequest},
+		{"invalid patch", service.ErrInvalidPatch, http.StatusBadRequest},
+		{"failed test", service.ErrPatchConflict, http.StatusConflict},
+		{"not found", service.ErrOrderNotFound, http.StatusNotFound},
+	}
+	for _, tc := range errCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := &mocks.OrderService{}
+			mockSvc.On("PatchOrder", mock.Anything, mockID, mock.Anything, service.JSONPatch).Return(model.Order{}, tc.err)
+
+			router := newRouter()
+			router.PATCH("/orders/:id", handler.NewOrderHandler(mockSvc).PatchOrder)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", "/orders/1", bytes.NewBufferString(`[]`))
+			req.Header.Set("Content-Type", "application/json-patch+json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler interface {
+	GetPromotions(ctx *gin.Context)
+	GetPromotionByID(ctx *gin.Context)
+	CreatePromotion(ctx *gin.Context)
+	UpdatePromotion(ctx *gin.Context)
+}
+
+type promotionHandlerImpl struct {
+	svc service.PromotionService
+}
+
+func NewPromotionHandler(svc service.PromotionService) PromotionHandler {
+	return &promotionHandlerImpl{
+		svc: svc,
+	}
+}
+
+// ShowPromotions godoc
+//
+//	@Summary		Show promotions
+//	@Description	Get all promotions with their use counts
+//	@Tags			promotions
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]model.Promotion
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/promotions [get]
+func (u *promotionHandlerImpl) GetPromotions(ctx *gin.Context) {
+	promos, err := u.svc.GetPromotions(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, promos)
+}
+
+// ShowPromotion godoc
+//
+//	@Summary		Show a promotion
+//	@Description	Get one promotion with its use count
+//	@Tags			promotions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Promotion ID"
+//	@Success		200	{object}	model.Promotion
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/promotions/{id} [get]
+func (u *promotionHandlerImpl) GetPromotionByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	promo, err := u.svc.GetPromotionByID(ctx, uint64(id))
+	if err != nil {
+		promotionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, promo)
+}
+
+// CreatePromotion godoc
+//
+//	@Summary		Create a promotion
+//	@Description	Create a promo code. Codes are case-insensitive and unique; value is a percentage or an amount in minor units, depending on kind.
+//	@Tags			promotions
+//	@Accept			json
+//	@Produce		json
+//	@Param			promotion	body		model.Promotion	true	"Create Promotion"
+//	@Success		201			{object}	model.Promotion
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		403			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/promotions [post]
+func (u *promotionHandlerImpl) CreatePromotion(ctx *gin.Context) {
+	req := model.Promotion{}
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	promo, err := u.svc.CreatePromotion(ctx, req)
+	if err != nil {
+		promotionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, promo)
+}
+
+// UpdatePromotion godoc
+//
+//	@Summary		Update a promotion
+//	@Description	Replace everything but the code. Once redeemed, only the limits, the validity window and the active flag may change; set active to false to retire a promotion.
+//	@Tags			promotions
+//	@Accept			json
+//	@Produce		json
+//	@Param			promotion	body		model.Promotion	true	"Update Promotion"
+//	@Param			id			path		int				true	"Promotion ID"
+//	@Success		200			{object}	model.Promotion
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		403			{object}	pkg.ErrorResponse
+//	@Failure		404			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/promotions/{id} [put]
+func (u *promotionHandlerImpl) UpdatePromotion(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	req := model.Promotion{}
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	promo, err := u.svc.UpdatePromotion(ctx, req, uint64(id))
+	if err != nil {
+		promotionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, promo)
+}
+
+func promotionError(ctx *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid promotion", Errors: validationErr.Errors})
+	case errors.Is(err, service.ErrPromotionNotFound):
+		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Promotion Not Found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+	}
+}
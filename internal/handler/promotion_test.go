@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func TestCreatePromotion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.PromotionService{}
+
+	mockPromo := model.Promotion{ID: 1, Code: "SPRING10", Kind: model.PromotionPercentage, Value: 10, Active: true}
+	mockSvc.On("CreatePromotion", mock.Anything, mock.Anything).Return(mockPromo, nil)
+
+	handler := handler.NewPromotionHandler(mockSvc)
+
+	router := newRouter()
+	router.POST("/promotions", handler.CreatePromotion)
+
+	requestBody, err := json.Marshal(model.Promotion{Code: "spring10", Kind: model.PromotionPercentage, Value: 10, Active: true})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/promotions", bytes.NewBuffer(requestBody))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var promo model.Promotion
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &promo))
+	assert.Equal(t, "SPRING10", promo.Code)
+
+	mockSvc.AssertCalled(t, "CreatePromotion", mock.Anything, mock.Anything)
+}
+
+func TestUpdatePromotionInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.PromotionService{}
+
+	validationErr := &service.ValidationError{Errors: []string{"kind, value, min_order_amount and item_codes cannot change once the promotion was redeemed"}}
+	mockSvc.On("UpdatePromotion", mock.Anything, mock.Anything, uint64(1)).Return(model.Promotion{}, validationErr)
+
+	handler := handler.NewPromotionHandler(mockSvc)
+
+	router := newRouter()
+	router.PUT("/promotions/:id", handler.UpdatePromotion)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/promotions/1", bytes.NewBufferString(`{"kind":"fixed","value":500,"active":true}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var res pkg.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, validationErr.Errors, res.Errors)
+}
+
+func TestGetPromotionNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.PromotionService{}
+
+	mockSvc.On("GetPromotionByID", mock.Anything, uint64(3)).Return(model.Promotion{}, service.ErrPromotionNotFound)
+
+	handler := handler.NewPromotionHandler(mockSvc)
+
+	router := newRouter()
+	router.GET("/promotions/:id", handler.GetPromotionByID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/promotions/3", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
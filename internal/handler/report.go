@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler interface {
+	GetOrderVolumes(ctx *gin.Context)
+	GetTopItems(ctx *gin.Context)
+	GetOrderSizes(ctx *gin.Context)
+	GetCustomerOrders(ctx *gin.Context)
+}
+
+type reportHandlerImpl struct {
+	svc service.ReportService
+}
+
+func NewReportHandler(svc service.ReportService) ReportHandler {
+	return &reportHandlerImpl{svc: svc}
+}
+
+// ShowOrderVolumes godoc
+//
+//	@Summary		Orders per period
+//	@Description	Count orders, items and quantities per calendar day, ISO week or month of the given time zone, including empty periods. The range defaults to the last 30 days.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from		query		string	false	"Start of the range, RFC 3339, inclusive"
+//	@Param			to			query		string	false	"End of the range, RFC 3339, exclusive"
+//	@Param			interval	query		string	false	"day (default), week or month"
+//	@Param			tz			query		string	false	"IANA time zone of the periods"
+//	@Success		200			{object}	model.OrderVolumeReport
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/reports/orders [get]
+func (u *reportHandlerImpl) GetOrderVolumes(ctx *gin.Context) {
+	req, err := reportRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	rep, err := u.svc.OrderVolumes(ctx, req)
+	if err != nil {
+		reportError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rep)
+}
+
+// ShowTopItems godoc
+//
+//	@Summary		Top item codes
+//	@Description	List the item codes ordered in the largest quantities. The range defaults to the last 30 days.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the range, RFC 3339, inclusive"
+//	@Param			to		query		string	false	"End of the range, RFC 3339, exclusive"
+//	@Param			limit	query		int		false	"Number of item codes, 10 by default"
+//	@Success		200		{object}	model.TopItemsReport
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/reports/top-items [get]
+func (u *reportHandlerImpl) GetTopItems(ctx *gin.Context) {
+	req, err := reportRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	rep, err := u.svc.TopItems(ctx, req)
+	if err != nil {
+		reportError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rep)
+}
+
+// ShowOrderSizes godoc
+//
+//	@Summary		Average items per order
+//	@Description	Total and average number of items and quantities per order. The range defaults to the last 30 days.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the range, RFC 3339, inclusive"
+//	@Param			to		query		string	false	"End of the range, RFC 3339, exclusive"
+//	@Success		200		{object}	model.OrderSizeReport
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/reports/items-per-order [get]
+func (u *reportHandlerImpl) GetOrderSizes(ctx *gin.Context) {
+	req, err := reportRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	rep, err := u.svc.OrderSizes(ctx, req)
+	if err != nil {
+		reportError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rep)
+}
+
+// ShowCustomerOrders godoc
+//
+//	@Summary		Orders per customer
+//	@Description	List the customers with the most orders. The range defaults to the last 30 days.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the range, RFC 3339, inclusive"
+//	@Param			to		query		string	false	"End of the range, RFC 3339, exclusive"
+//	@Param			limit	query		int		false	"Number of customers, 10 by default"
+//	@Success		200		{object}	model.CustomerOrdersReport
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/reports/customers [get]
+func (u *reportHandlerImpl) GetCustomerOrders(ctx *gin.Context) {
+	req, err := reportRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	rep, err := u.svc.CustomerOrders(ctx, req)
+	if err != nil {
+		reportError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rep)
+}
+
+func reportRequest(ctx *gin.Context) (model.ReportRequest, error) {
+	req := model.ReportRequest{
+		Interval: ctx.Query("interval"),
+		TimeZone: ctx.Query("tz"),
+	}
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &req.From}, {"to", &req.To}} {
+		v := ctx.Query(p.key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return model.ReportRequest{}, fmt.Errorf("invalid %s: expected an RFC 3339 time", p.key)
+		}
+		*p.dst = t
+	}
+	if v := ctx.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return model.ReportRequest{}, errors.New("limit must be a number")
+		}
+		req.Limit = n
+	}
+	return req, nil
+}
+
+func reportError(ctx *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid report", Errors: validationErr.Errors})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+}
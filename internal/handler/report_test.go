@@ -0,0 +1,55 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func TestGetOrderVolumes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	req := model.ReportRequest{From: from, Interval: "week", TimeZone: "Asia/Jakarta"}
+	mockSvc := &mocks.ReportService{}
+	mockSvc.On("OrderVolumes", mock.Anything, req).Return(model.OrderVolumeReport{
+		Interval: "week",
+		Periods:  []model.OrderVolume{{PeriodStart: from, Orders: 2}},
+	}, nil)
+	mockSvc.On("TopItems", mock.Anything, model.ReportRequest{Limit: 500}).
+		Return(model.TopItemsReport{}, &service.ValidationError{Errors: []string{"limit must be between 1 and 100"}})
+
+	hdl := handler.NewReportHandler(mockSvc)
+	router := newRouter()
+	router.GET("/reports/orders", hdl.GetOrderVolumes)
+	router.GET("/reports/top-items", hdl.GetTopItems)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/reports/orders?from=2024-03-01T00:00:00Z&interval=week&tz=Asia/Jakarta", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rep model.OrderVolumeReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rep))
+	assert.Equal(t, uint64(2), rep.Periods[0].Orders)
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/reports/orders?from=yesterday", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/reports/top-items?limit=500", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler interface {
+	GetWebhooks(ctx *gin.Context)
+	GetWebhookByID(ctx *gin.Context)
+	CreateWebhook(ctx *gin.Context)
+	UpdateWebhook(ctx *gin.Context)
+	DeleteWebhook(ctx *gin.Context)
+	GetDeliveries(ctx *gin.Context)
+	GetDeliveryByID(ctx *gin.Context)
+	Redeliver(ctx *gin.Context)
+}
+
+type webhookHandlerImpl struct {
+	svc service.WebhookService
+}
+
+func NewWebhookHandler(svc service.WebhookService) WebhookHandler {
+	return &webhookHandlerImpl{
+		svc: svc,
+	}
+}
+
+// ShowWebhooks godoc
+//
+//	@Summary		Show webhook subscriptions
+//	@Description	Get all webhook subscriptions, without their secrets
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]model.WebhookSubscription
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/webhooks [get]
+func (u *webhookHandlerImpl) GetWebhooks(ctx *gin.Context) {
+	subs, err := u.svc.GetWebhooks(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, subs)
+}
+
+// ShowWebhook godoc
+//
+//	@Summary		Show a webhook subscription
+//	@Description	Get one webhook subscription, without its secret
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	model.WebhookSubscription
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/webhooks/{id} [get]
+func (u *webhookHandlerImpl) GetWebhookByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	sub, err := u.svc.GetWebhookByID(ctx, uint64(id))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sub)
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		Create a webhook subscription
+//	@Description	Subscribe a URL to order events. The response carries the signing secret; it is not shown again.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		model.WebhookSubscription	true	"Create Webhook"
+//	@Success		201		{object}	model.WebhookSubscription
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/webhooks [post]
+func (u *webhookHandlerImpl) CreateWebhook(ctx *gin.Context) {
+	req := model.WebhookSubscription{}
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	sub, err := u.svc.CreateWebhook(ctx, req)
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, sub)
+}
+
+// UpdateWebhook godoc
+//
+//	@Summary		Update a webhook subscription
+//	@Description	Replace URL, event types and active flag. Setting active to true re-enables an auto-disabled subscription.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		model.WebhookSubscription	true	"Update Webhook"
+//	@Param			id		path		int							true	"Webhook ID"
+//	@Success		200		{object}	model.WebhookSubscription
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/webhooks/{id} [put]
+func (u *webhookHandlerImpl) UpdateWebhook(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	req := model.WebhookSubscription{}
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	sub, err := u.svc.UpdateWebhook(ctx, req, uint64(id))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sub)
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Delete a webhook subscription
+//	@Description	Delete a subscription and its delivery log
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/webhooks/{id} [delete]
+func (u *webhookHandlerImpl) DeleteWebhook(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	if err := u.svc.DeleteWebhook(ctx, uint64(id)); err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]any{
+		"message": "Webhook deleted",
+	})
+}
+
+// ShowDeliveries godoc
+//
+//	@Summary		Show webhook deliveries
+//	@Description	Get the delivery log of a subscription, newest first
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	[]model.WebhookDelivery
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/webhooks/{id}/deliveries [get]
+func (u *webhookHandlerImpl) GetDeliveries(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if id == 0 || err != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	deliveries, err := u.svc.GetDeliveries(ctx, uint64(id))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// ShowDelivery godoc
+//
+//	@Summary		Show a webhook delivery
+//	@Description	Get one delivery with every attempt and its response code
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int	true	"Webhook ID"
+//	@Param			delivery_id	path		int	true	"Delivery ID"
+//	@Success		200			{object}	model.WebhookDelivery
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		403			{object}	pkg.ErrorResponse
+//	@Failure		404			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/webhooks/{id}/deliveries/{delivery_id} [get]
+func (u *webhookHandlerImpl) GetDeliveryByID(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	deliveryID, deliveryErr := strconv.Atoi(ctx.Param("delivery_id"))
+	if id == 0 || deliveryID == 0 || err != nil || deliveryErr != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	delivery, err := u.svc.GetDeliveryByID(ctx, uint64(id), uint64(deliveryID))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver godoc
+//
+//	@Summary		Redeliver a webhook
+//	@Description	Queue a new delivery of the same event to the same subscription
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int	true	"Webhook ID"
+//	@Param			delivery_id	path		int	true	"Delivery ID"
+//	@Success		202			{object}	model.WebhookDelivery
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		403			{object}	pkg.ErrorResponse
+//	@Failure		404			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (u *webhookHandlerImpl) Redeliver(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	deliveryID, deliveryErr := strconv.Atoi(ctx.Param("delivery_id"))
+	if id == 0 || deliveryID == 0 || err != nil || deliveryErr != nil {
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		return
+	}
+	delivery, err := u.svc.Redeliver(ctx, uint64(id), uint64(deliveryID))
+	if err != nil {
+		webhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, delivery)
+}
+
+func webhookError(ctx *gin.Context, err error) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid webhook", Errors: validationErr.Errors})
+	case errors.Is(err, service.ErrWebhookNotFound):
+		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Webhook Not Found"})
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Delivery Not Found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+	}
+}
@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.WebhookService{}
+
+	mockSub := model.WebhookSubscription{ID: 1, URL: "https://partner.example.com/hooks", Secret: "s3cret", Active: true}
+	mockSvc.On("CreateWebhook", mock.Anything, mock.Anything).Return(mockSub, nil)
+
+	handler := handler.NewWebhookHandler(mockSvc)
+
+	router := newRouter()
+	router.POST("/webhooks", handler.CreateWebhook)
+
+	requestBody, err := json.Marshal(model.WebhookSubscription{URL: mockSub.URL})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBuffer(requestBody))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var sub model.WebhookSubscription
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sub))
+	assert.Equal(t, "s3cret", sub.Secret)
+
+	mockSvc.AssertCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+func TestCreateWebhookInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.WebhookService{}
+
+	validationErr := &service.ValidationError{Errors: []string{"url must be an absolute http or https URL"}}
+	mockSvc.On("CreateWebhook", mock.Anything, mock.Anything).Return(model.WebhookSubscription{}, validationErr)
+
+	handler := handler.NewWebhookHandler(mockSvc)
+
+	router := newRouter()
+	router.POST("/webhooks", handler.CreateWebhook)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(`{"url":"ftp://nope"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var res pkg.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, validationErr.Errors, res.Errors)
+}
+
+func TestGetWebhookNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.WebhookService{}
+
+	mockSvc.On("GetWebhookByID", mock.Anything, uint64(3)).Return(model.WebhookSubscription{}, service.ErrWebhookNotFound)
+
+	handler := handler.NewWebhookHandler(mockSvc)
+
+	router := newRouter()
+	router.GET("/webhooks/:id", handler.GetWebhookByID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhooks/3", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRedeliver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSvc := &mocks.WebhookService{}
+
+	original := uint64(5)
+	mockDelivery := model.WebhookDelivery{ID: 6, SubscriptionID: 1, RedeliveryOf: &original, Status: model.DeliveryStatusPending}
+	mockSvc.On("Redeliver", mock.Anything, uint64(1), uint64(5)).Return(mockDelivery, nil)
+
+	handler := handler.NewWebhookHandler(mockSvc)
+
+	router := newRouter()
+	router.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", handler.Redeliver)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks/1/deliveries/5/redeliver", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockSvc.AssertCalled(t, "Redeliver", mock.Anything, uint64(1), uint64(5))
+}
@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// csvRequired are the columns a CSV file must have. The status, ordered_at
+// and description columns are optional.
+var csvRequired = []string{"order_ref", "customer_name", "item_code", "quantity"}
+
+// maxSeenRefs caps how many order_refs a CSV import remembers to report
+// orders whose rows are not adjacent, about 100 bytes each. The rows of a
+// ref first seen after that are not checked and form a new order.
+const maxSeenRefs = 100000
+
+// csvSource groups adjacent rows with the same order_ref into one order.
+type csvSource struct {
+	r       *csv.Reader
+	columns map[string]int
+	// pending is the first row of the next order, read while looking for
+	// the end of the current one.
+	pending *csvRow
+	// seen maps the order_refs read so far, up to maxSeenRefs of them, to
+	// the line of the last order with each.
+	seen map[string]int
+	err  error
+}
+
+type csvRow struct {
+	line   int
+	ref    string
+	order  model.Order
+	item   *model.Item
+	errors []string
+}
+
+func newCSVSource(r io.Reader) *csvSource {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	return &csvSource{r: cr, seen: map[string]int{}}
+}
+
+func (s *csvSource) next() (record, error) {
+	if s.columns == nil {
+		if err := s.readHeader(); err != nil {
+			return record{}, err
+		}
+	}
+
+	first := s.pending
+	s.pending = nil
+	if first == nil {
+		row, err := s.readRow()
+		if err != nil {
+			return record{}, err
+		}
+		first = row
+	}
+
+	rec := record{line: first.line, ref: first.ref, order: first.order}
+	add := func(row *csvRow) {
+		if len(row.errors) > 0 {
+			rec.errors = append(rec.errors, RowError{Line: row.line, OrderRef: row.ref, Errors: row.errors})
+		}
+		if row.item != nil {
+			rec.order.Items = append(rec.order.Items, *row.item)
+		}
+	}
+
+	if line, ok := s.seen[first.ref]; ok && first.ref != "" {
+		first.errors = append(first.errors, fmt.Sprintf("order_ref %q was already used by the order at line %d; rows of an order must be adjacent", first.ref, line))
+	}
+	if len(s.seen) < maxSeenRefs {
+		s.seen[first.ref] = first.line
+	}
+	add(first)
+
+	for {
+		row, err := s.readRow()
+		if errors.Is(err, io.EOF) {
+			return rec, nil
+		}
+		if err != nil {
+			return record{}, err
+		}
+		if row.ref != first.ref || first.ref == "" {
+			s.pending = row
+			return rec, nil
+		}
+		row.errors = append(row.errors, sameOrder(first, row)...)
+		add(row)
+	}
+}
+
+func (s *csvSource) readHeader() error {
+	header, err := s.r.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	s.columns = map[string]int{}
+	for i, name := range header {
+		s.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	missing := []string{}
+	for _, name := range csvRequired {
+		if _, ok := s.columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing columns %s", ErrInvalidFile, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (s *csvSource) readRow() (*csvRow, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	fields, err := s.r.Read()
+	if errors.Is(err, io.EOF) {
+		s.err = io.EOF
+		return nil, s.err
+	}
+	if errors.Is(err, csv.ErrFieldCount) {
+		// the reader can go on after this one
+		line, _ := s.r.FieldPos(0)
+		return &csvRow{line: line, errors: []string{"wrong number of fields"}}, nil
+	}
+	if err != nil {
+		s.err = fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		return nil, s.err
+	}
+	line, _ := s.r.FieldPos(0)
+
+	get := func(name string) string {
+		if i, ok := s.columns[name]; ok {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	row := &csvRow{
+		line: line,
+		ref:  get("order_ref"),
+		order: model.Order{
+			CustomerName: get("customer_name"),
+			Status:       get("status"),
+		},
+	}
+	if row.ref == "" {
+		row.errors = append(row.errors, "order_ref is required")
+	}
+	if v := get("ordered_at"); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			row.errors = append(row.errors, "ordered_at must be an RFC 3339 time or a YYYY-MM-DD date")
+		}
+		row.order.OrderedAt = t
+	}
+
+	code, qty := get("item_code"), get("quantity")
+	if code == "" && qty == "" {
+		// an order without items
+		return row, nil
+	}
+	row.item = &model.Item{ItemCode: code, Description: get("description")}
+	if qty != "" {
+		n, err := strconv.ParseUint(qty, 10, 64)
+		if err != nil {
+			row.errors = append(row.errors, fmt.Sprintf("quantity %q is not a whole number", qty))
+		}
+		row.item.Quantity = n
+	}
+	return row, nil
+}
+
+// sameOrder reports order level fields of row that disagree with the first
+// row of its order.
+func sameOrder(first, row *csvRow) []string {
+	errs := []string{}
+	if row.order.CustomerName != first.order.CustomerName {
+		errs = append(errs, fmt.Sprintf("customer_name differs from line %d", first.line))
+	}
+	if row.order.Status != first.order.Status {
+		errs = append(errs, fmt.Sprintf("status differs from line %d", first.line))
+	}
+	if !row.order.OrderedAt.Equal(first.order.OrderedAt) {
+		errs = append(errs, fmt.Sprintf("ordered_at differs from line %d", first.line))
+	}
+	return errs
+}
+
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
@@ -0,0 +1,212 @@
+// Package importer loads orders from CSV and NDJSON files through the order
+// service, in batches, reporting problems per row.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+)
+
+// Format is the file format of an import.
+type Format string
+
+const (
+	// CSV files have one row per item. Rows of the same order share an
+	// order_ref and must be adjacent.
+	CSV Format = "csv"
+	// NDJSON files have one order per line.
+	NDJSON Format = "ndjson"
+)
+
+// DetectFormat guesses the format from a content type or, failing that, a
+// file name. It returns "" when neither gives it away.
+func DetectFormat(contentType, name string) Format {
+	switch contentType {
+	case "text/csv":
+		return CSV
+	case "application/x-ndjson", "application/ndjson":
+		return NDJSON
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return CSV
+	case ".ndjson", ".jsonl":
+		return NDJSON
+	}
+	return ""
+}
+
+// ErrInvalidFile is returned when the file cannot be read any further, for
+// example because of a broken CSV quote or a missing column.
+var ErrInvalidFile = errors.New("invalid import file")
+
+// Options controls one import.
+type Options struct {
+	Format Format
+	// BatchSize is how many orders are stored per transaction.
+	BatchSize int
+	// DryRun validates the file without storing anything. Each order is
+	// checked on its own: the promo codes of orders earlier in the file are
+	// not counted against usage limits.
+	DryRun bool
+	// Progress, when set, is called after each batch with the number of
+	// orders read so far.
+	Progress func(orders int)
+}
+
+// RowError lists the problems of one row, or of the order that starts at
+// that row.
+type RowError struct {
+	Line     int      `json:"line" example:"3"`
+	OrderRef string   `json:"order_ref,omitempty" example:"LEGACY-1001"`
+	Errors   []string `json:"errors"`
+}
+
+// Report summarizes an import. Imported counts the orders that were stored,
+// or that would have been on a dry run.
+type Report struct {
+	DryRun   bool       `json:"dry_run"`
+	Orders   int        `json:"orders" example:"2"`
+	Imported int        `json:"imported" example:"1"`
+	Failed   int        `json:"failed" example:"1"`
+	Errors   []RowError `json:"errors"`
+	// Error is set when the import stopped early. Orders counted as imported
+	// up to that point remain stored.
+	Error string `json:"error,omitempty"`
+}
+
+type Importer interface {
+	Import(ctx context.Context, r io.Reader, opts Options) (Report, error)
+}
+
+type importerImpl struct {
+	svc service.OrderService
+}
+
+func NewImporter(svc service.OrderService) Importer {
+	return &importerImpl{svc: svc}
+}
+
+// record is one order read from the file, with the problems found while
+// reading its rows.
+type record struct {
+	line   int
+	ref    string
+	order  model.Order
+	errors []RowError
+}
+
+type source interface {
+	// next returns io.EOF after the last record.
+	next() (record, error)
+}
+
+func (i *importerImpl) Import(ctx context.Context, r io.Reader, opts Options) (Report, error) {
+	if opts.BatchSize < 1 {
+		return Report{}, errors.New("batch size must be at least 1")
+	}
+	var src source
+	switch opts.Format {
+	case CSV:
+		src = newCSVSource(r)
+	case NDJSON:
+		src = newNDJSONSource(r)
+	default:
+		return Report{}, fmt.Errorf("unknown format %q", opts.Format)
+	}
+
+	rep := Report{DryRun: opts.DryRun, Errors: []RowError{}}
+	batch := []record{}
+	for {
+		rec, err := src.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return i.fail(rep, err)
+		}
+		rep.Orders++
+
+		// a real import has CreateOrders apply the same rules
+		if len(rec.errors) == 0 && opts.DryRun {
+			err := i.svc.ValidateOrder(ctx, rec.order)
+			var validationErr *service.ValidationError
+			if errors.As(err, &validationErr) {
+				rec.errors = append(rec.errors, RowError{Line: rec.line, OrderRef: rec.ref, Errors: validationErr.Errors})
+			} else if err != nil {
+				return i.fail(rep, err)
+			}
+		}
+		if len(rec.errors) > 0 {
+			rep.Failed++
+			rep.Errors = append(rep.Errors, rec.errors...)
+			continue
+		}
+
+		batch = append(batch, rec)
+		if len(batch) == opts.BatchSize {
+			if err := i.store(ctx, &rep, batch); err != nil {
+				return i.fail(rep, err)
+			}
+			batch = batch[:0]
+			opts.progress(rep.Orders)
+		}
+	}
+	if err := i.store(ctx, &rep, batch); err != nil {
+		return i.fail(rep, err)
+	}
+	opts.progress(rep.Orders)
+	return rep, nil
+}
+
+// store commits one batch of valid orders and records the outcome of each.
+func (i *importerImpl) store(ctx context.Context, rep *Report, batch []record) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if rep.DryRun {
+		rep.Imported += len(batch)
+		return nil
+	}
+
+	orders := make([]model.Order, 0, len(batch))
+	for _, rec := range batch {
+		orders = append(orders, rec.order)
+	}
+	results, err := i.svc.CreateOrders(ctx, orders, service.BatchPartial)
+	if err != nil {
+		return err
+	}
+	for j, res := range results {
+		if res.Err == nil {
+			rep.Imported++
+			continue
+		}
+		rep.Failed++
+		rowErr := RowError{Line: batch[j].line, OrderRef: batch[j].ref, Errors: []string{res.Err.Error()}}
+		var validationErr *service.ValidationError
+		if errors.As(res.Err, &validationErr) {
+			rowErr.Errors = validationErr.Errors
+		}
+		rep.Errors = append(rep.Errors, rowErr)
+	}
+	return nil
+}
+
+func (o Options) progress(orders int) {
+	if o.Progress != nil {
+		o.Progress(orders)
+	}
+}
+
+func (i *importerImpl) fail(rep Report, err error) (Report, error) {
+	rep.Error = err.Error()
+	return rep, err
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+	"github.com/MidnightHelix/assignment-2/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const ordersCSV = `order_ref,customer_name,status,ordered_at,item_code,description,quantity
+L-1,alice,paid,2024-03-01,A,"apple, red",2
+L-1,alice,paid,2024-03-01,B,,1
+L-2,bob,,,C,,x
+L-3,carol,,,,,
+L-4,dave,,,D,,1
+L-4,eve,,,E,,1
+L-5,,,,F,,1
+L-1,alice,paid,2024-03-01,G,,1
+`
+
+// created returns a CreateOrders result that stores every order.
+func created(ctx context.Context, orders []model.Order, mode service.BatchMode) []service.BatchResult {
+	res := make([]service.BatchResult, len(orders))
+	for i, o := range orders {
+		o.ID = uint64(i + 1)
+		res[i] = service.BatchResult{Order: o}
+	}
+	return res
+}
+
+func TestImportCSV(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+	var stored [][]model.Order
+	mockSvc.On("CreateOrders", mock.Anything, mock.Anything, service.BatchPartial).
+		Run(func(args mock.Arguments) { stored = append(stored, args.Get(1).([]model.Order)) }).
+		Return(created, nil)
+
+	rep, err := NewImporter(mockSvc).Import(context.Background(), strings.NewReader(ordersCSV), Options{Format: CSV, BatchSize: 10})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, rep.Orders)
+	assert.Equal(t, 3, rep.Imported)
+	assert.Equal(t, 3, rep.Failed)
+	assert.Equal(t, []RowError{
+		{Line: 4, OrderRef: "L-2", Errors: []string{`quantity "x" is not a whole number`}},
+		{Line: 7, OrderRef: "L-4", Errors: []string{"customer_name differs from line 6"}},
+		{Line: 9, OrderRef: "L-1", Errors: []string{`order_ref "L-1" was already used by the order at line 2; rows of an order must be adjacent`}},
+	}, rep.Errors)
+
+	assert.Len(t, stored, 1)
+	assert.Equal(t, []model.Order{
+		{CustomerName: "alice", Status: "paid", OrderedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Items: []model.Item{
+			{ItemCode: "A", Description: "apple, red", Quantity: 2},
+			{ItemCode: "B", Quantity: 1},
+		}},
+		{CustomerName: "carol"},
+		{Items: []model.Item{{ItemCode: "F", Quantity: 1}}},
+	}, stored[0])
+}
+
+func TestImportNDJSON(t *testing.T) {
+	file := `{"order_ref":"L-1","customer_name":"alice","items":[{"item_code":"A","quantity":1}]}
+
+{"customer_name":"bob","colour":"red"}
+{"customer_name":"carol"}
+{"customer_name":"dave"}
+`
+
+	t.Run("commits in batches", func(t *testing.T) {
+		mockSvc := &mocks.OrderService{}
+		mockSvc.On("CreateOrders", mock.Anything, mock.MatchedBy(func(o []model.Order) bool { return len(o) == 2 }), service.BatchPartial).
+			Return(created, nil).Once()
+		mockSvc.On("CreateOrders", mock.Anything, mock.MatchedBy(func(o []model.Order) bool { return len(o) == 1 }), service.BatchPartial).
+			Return([]service.BatchResult{{Err: errors.New("duplicate key")}}, nil).Once()
+
+		rep, err := NewImporter(mockSvc).Import(context.Background(), strings.NewReader(file), Options{Format: NDJSON, BatchSize: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, rep.Orders)
+		assert.Equal(t, 2, rep.Imported)
+		assert.Equal(t, 2, rep.Failed)
+		assert.Equal(t, 3, rep.Errors[0].Line)
+		assert.Equal(t, RowError{Line: 5, Errors: []string{"duplicate key"}}, rep.Errors[1])
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("dry run validates with the service and stores nothing", func(t *testing.T) {
+		mockSvc := mocks.NewOrderService(t)
+		mockSvc.On("ValidateOrder", mock.Anything, mock.MatchedBy(func(o model.Order) bool { return o.CustomerName != "dave" })).
+			Return(nil).Twice()
+		mockSvc.On("ValidateOrder", mock.Anything, mock.MatchedBy(func(o model.Order) bool { return o.CustomerName == "dave" })).
+			Return(&service.ValidationError{Errors: []string{`unknown status "lost"`}}).Once()
+
+		rep, err := NewImporter(mockSvc).Import(context.Background(), strings.NewReader(file), Options{Format: NDJSON, BatchSize: 2, DryRun: true})
+		assert.NoError(t, err)
+		assert.True(t, rep.DryRun)
+		assert.Equal(t, 2, rep.Imported)
+		assert.Equal(t, 2, rep.Failed)
+		assert.Equal(t, RowError{Line: 5, Errors: []string{`unknown status "lost"`}}, rep.Errors[1])
+	})
+
+	t.Run("reports the rules broken by stored orders", func(t *testing.T) {
+		mockSvc := mocks.NewOrderService(t)
+		mockSvc.On("CreateOrders", mock.Anything, mock.Anything, service.BatchPartial).
+			Return([]service.BatchResult{{Err: &service.ValidationError{Errors: []string{`promo_code "X" does not exist`}}}}, nil).Once()
+
+		rep, err := NewImporter(mockSvc).Import(context.Background(), strings.NewReader(`{"customer_name":"alice","promo_code":"X"}`), Options{Format: NDJSON, BatchSize: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []RowError{{Line: 1, Errors: []string{`promo_code "X" does not exist`}}}, rep.Errors)
+	})
+}
+
+func TestImportInvalidFile(t *testing.T) {
+	mockSvc := &mocks.OrderService{}
+
+	_, err := NewImporter(mockSvc).Import(context.Background(), strings.NewReader("order_ref,customer_name\n"), Options{Format: CSV, BatchSize: 1})
+	assert.True(t, errors.Is(err, ErrInvalidFile))
+	assert.Contains(t, err.Error(), "missing columns item_code, quantity")
+
+	rep, err := NewImporter(mockSvc).Import(context.Background(), strings.NewReader("order_ref,customer_name,item_code,quantity\nL-1,\"alice,A,1\n"), Options{Format: CSV, BatchSize: 1, DryRun: true})
+	assert.True(t, errors.Is(err, ErrInvalidFile))
+	assert.NotEmpty(t, rep.Error)
+}
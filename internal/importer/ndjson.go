@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// maxLineSize bounds one NDJSON line, so a file without newlines cannot
+// exhaust memory.
+const maxLineSize = 1 << 20
+
+// ndjsonOrder is one NDJSON line: an order as the API accepts it, plus the
+// optional reference it had in the legacy system.
+type ndjsonOrder struct {
+	model.Order
+	OrderRef string `json:"order_ref"`
+}
+
+type ndjsonSource struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newNDJSONSource(r io.Reader) *ndjsonSource {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &ndjsonSource{s: s}
+}
+
+func (n *ndjsonSource) next() (record, error) {
+	for n.s.Scan() {
+		n.line++
+		b := bytes.TrimSpace(n.s.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		v := ndjsonOrder{}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&v); err != nil {
+			return record{line: n.line, errors: []RowError{{Line: n.line, Errors: []string{fmt.Sprintf("invalid JSON: %v", err)}}}}, nil
+		}
+		return record{line: n.line, ref: v.OrderRef, order: v.Order}, nil
+	}
+	if err := n.s.Err(); err != nil {
+		return record{}, fmt.Errorf("%w: line %d: %v", ErrInvalidFile, n.line+1, err)
+	}
+	return record{}, io.EOF
+}
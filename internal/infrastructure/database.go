@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/MidnightHelix/assignment-2/internal/config"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"gorm.io/gorm"
+)
+
+type GormDB interface {
+	GetConnection() *gorm.DB
+}
+
+type gormDBImpl struct {
+	master *gorm.DB
+}
+
+// Driver adapts GORM to one kind of database. The repositories tell the
+// dialects apart by the name of the GORM dialector.
+type Driver interface {
+	Dialector(cfg config.Database) gorm.Dialector
+	// ConfigurePool sizes the connection pool.
+	ConfigurePool(db *sql.DB, cfg config.Database)
+	// Migrate runs the schema changes AutoMigrate cannot express.
+	Migrate(db *gorm.DB, cfg config.Database) error
+}
+
+var drivers = map[string]Driver{
+	"postgres": postgresDriver{},
+	"sqlite":   sqliteDriver{},
+}
+
+// NewGormDB connects to the database of cfg.Driver, retrying with backoff
+// until cfg.ConnectDeadline elapses, then migrates the schema.
+func NewGormDB(cfg config.Database) (GormDB, error) {
+	driver, ok := drivers[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+	db, err := connect(driver, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &gormDBImpl{
+		master: db,
+	}, nil
+}
+
+func connect(driver Driver, cfg config.Database) (*gorm.DB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectDeadline)
+	defer cancel()
+
+	var db *gorm.DB
+	backoff := pkg.Backoff{Initial: cfg.RetryInitialBackoff, Max: cfg.RetryMaxBackoff}
+	err := pkg.Retry(ctx, backoff, func(attempt int) error {
+		var err error
+		db, err = open(ctx, driver, cfg)
+		if err != nil {
+			log.Printf("%s %s not ready (attempt %d): %v", cfg.Driver, cfg.Address(), attempt+1, err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s %s within %s: %w", cfg.Driver, cfg.Address(), cfg.ConnectDeadline, err)
+	}
+
+	if err := registerTenantScope(db); err != nil {
+		return nil, fmt.Errorf("register tenant scope: %w", err)
+	}
+	if cfg.RowLevelSecurity {
+		if err := registerRowLevelSecurity(db); err != nil {
+			return nil, fmt.Errorf("register row-level security: %w", err)
+		}
+	}
+
+	if err := db.AutoMigrate(
+		&model.Order{},
+		&model.Item{},
+		&model.OrderSummary{},
+		&model.OutboxEvent{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.WebhookDeliveryAttempt{},
+		&model.Job{},
+		&model.JobFile{},
+		&model.AuditEntry{},
+		&model.IdempotencyKey{},
+		&model.Promotion{},
+		&model.PromotionRedemption{},
+	); err != nil {
+		return nil, fmt.Errorf("migrate schema: %w", err)
+	}
+	if err := backfillOrderSummaries(db.WithContext(tenant.WithAllTenants(context.Background()))); err != nil {
+		return nil, fmt.Errorf("backfill order summaries: %w", err)
+	}
+	if err := driver.Migrate(db, cfg); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// backfillOrderSummaries creates the read model rows of orders written
+// before order_summaries existed, for every tenant. It runs in a
+// transaction so row-level security lets it through.
+func backfillOrderSummaries(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return tx.Exec(`
+			INSERT INTO order_summaries (order_id, tenant_id, customer_name, ordered_at, item_count, total_quantity, updated_at)
+			SELECT o.id, o.tenant_id, o.customer_name, o.ordered_at, COUNT(i.id), COALESCE(SUM(i.quantity), 0), CURRENT_TIMESTAMP
+			FROM orders o
+			LEFT JOIN items i ON i.order_id = o.id
+			WHERE NOT EXISTS (SELECT 1 FROM order_summaries s WHERE s.order_id = o.id)
+			GROUP BY o.id, o.tenant_id, o.customer_name, o.ordered_at
+		`).Error
+	})
+}
+
+func open(ctx context.Context, driver Driver, cfg config.Database) (*gorm.DB, error) {
+	db, err := gorm.Open(driver.Dialector(cfg), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	driver.ConfigurePool(sqlDB, cfg)
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func (g *gormDBImpl) GetConnection() *gorm.DB {
+	return g.master
+}
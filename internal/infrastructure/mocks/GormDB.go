@@ -8,13 +8,13 @@ import (
 	mock "github.com/stretchr/testify/mock"
 )
 
-// GormPostgres is an autogenerated mock type for the GormPostgres type
-type GormPostgres struct {
+// GormDB is an autogenerated mock type for the GormDB type
+type GormDB struct {
 	mock.Mock
 }
 
 // GetConnection provides a mock function with given fields:
-func (_m *GormPostgres) GetConnection() *gorm.DB {
+func (_m *GormDB) GetConnection() *gorm.DB {
 	ret := _m.Called()
 
 	if len(ret) == 0 {
@@ -33,13 +33,13 @@ func (_m *GormPostgres) GetConnection() *gorm.DB {
 	return r0
 }
 
-// NewGormPostgres creates a new instance of GormPostgres. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewGormDB creates a new instance of GormDB. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewGormPostgres(t interface {
+func NewGormDB(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *GormPostgres {
-	mock := &GormPostgres{}
+}) *GormDB {
+	mock := &GormDB{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
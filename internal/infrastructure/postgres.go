@@ -1,43 +1,123 @@
 package infrastructure
 
 import (
+	"database/sql"
 	"fmt"
 
-	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-type GormPostgres interface {
-	GetConnection() *gorm.DB
+type postgresDriver struct{}
+
+func (postgresDriver) Dialector(cfg config.Database) gorm.Dialector {
+	return postgres.Open(cfg.DSN())
 }
 
-type gormPostgresImpl struct {
-	master *gorm.DB
+func (postgresDriver) ConfigurePool(db *sql.DB, cfg config.Database) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 }
 
-func NewGormPostgres() GormPostgres {
-	return &gormPostgresImpl{
-		master: connect(),
+func (postgresDriver) Migrate(db *gorm.DB, cfg config.Database) error {
+	if err := migrateSearch(db); err != nil {
+		return fmt.Errorf("migrate search: %w", err)
+	}
+	if err := protectAuditEntries(db); err != nil {
+		return fmt.Errorf("protect audit entries: %w", err)
 	}
+	if err := migrateRowLevelSecurity(db, cfg.RowLevelSecurity); err != nil {
+		return fmt.Errorf("migrate row-level security: %w", err)
+	}
+	return nil
 }
 
-func connect() *gorm.DB {
-	host := "127.0.0.1"
-	port := "5432"
-	user := "midnight"
-	password := "midnight"
-	dbname := "orders_by"
-
-	connectionString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, dbname)
-	db, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
-	if err != nil {
-		panic(err)
+// migrateRowLevelSecurity turns the tenant policies on or off. A policy
+// keeps the rows of the tenant in the app.tenant_id setting, and every row
+// when app.all_tenants is on, as it is for background workers. Statements
+// with neither see no rows. FORCE applies the policies to the owner of the
+// tables, usually the application itself.
+func migrateRowLevelSecurity(db *gorm.DB, enabled bool) error {
+	for _, table := range tenantTables {
+		stmts := []string{
+			`ALTER TABLE ` + table + ` NO FORCE ROW LEVEL SECURITY`,
+			`ALTER TABLE ` + table + ` DISABLE ROW LEVEL SECURITY`,
+		}
+		if enabled {
+			stmts = []string{
+				`DROP POLICY IF EXISTS tenant_isolation ON ` + table,
+				`CREATE POLICY tenant_isolation ON ` + table + `
+					USING (tenant_id = current_setting('app.tenant_id', true)
+						OR current_setting('app.all_tenants', true) = 'on')`,
+				`ALTER TABLE ` + table + ` ENABLE ROW LEVEL SECURITY`,
+				`ALTER TABLE ` + table + ` FORCE ROW LEVEL SECURITY`,
+			}
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
 	}
-	db.AutoMigrate(&model.Order{}, &model.Item{})
-	return db
+	return nil
 }
 
-func (g *gormPostgresImpl) GetConnection() *gorm.DB {
-	return g.master
+// protectAuditEntries makes audit_entries append-only: updates, deletes and
+// truncates fail, whoever issues them.
+func protectAuditEntries(db *gorm.DB) error {
+	for _, stmt := range []string{
+		`CREATE OR REPLACE FUNCTION reject_audit_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit entries are append-only';
+		END
+		$$ LANGUAGE plpgsql`,
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'audit_entries_append_only') THEN
+				CREATE TRIGGER audit_entries_append_only
+					BEFORE UPDATE OR DELETE ON audit_entries
+					FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
+			END IF;
+			IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'audit_entries_no_truncate') THEN
+				CREATE TRIGGER audit_entries_no_truncate
+					BEFORE TRUNCATE ON audit_entries
+					FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_change();
+			END IF;
+		END
+		$$`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSearch adds the search columns and indexes of orders and items.
+// The tsvector columns are generated by Postgres and kept out of the models,
+// so writes never have to maintain them.
+func migrateSearch(db *gorm.DB) error {
+	for _, stmt := range []string{
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`ALTER TABLE orders ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(customer_name, '')), 'A')) STORED`,
+		`ALTER TABLE items ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', coalesce(item_code, '')), 'B') ||
+				setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_orders_search ON orders USING GIN (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_items_search ON items USING GIN (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_orders_customer_name_trgm ON orders USING GIN (customer_name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_items_item_code_trgm ON items USING GIN (item_code gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS idx_items_description_trgm ON items USING GIN (description gin_trgm_ops)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
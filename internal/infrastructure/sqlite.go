@@ -0,0 +1,51 @@
+package infrastructure
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/MidnightHelix/assignment-2/internal/config"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDriver runs the schema on an SQLite file, for development and edge
+// deployments without Postgres. Search and reports need Postgres.
+type sqliteDriver struct{}
+
+func (sqliteDriver) Dialector(cfg config.Database) gorm.Dialector {
+	dsn := cfg.DSN()
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	// times are written in a format SQLite's date functions understand
+	return sqlite.Open(dsn + sep + "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_time_format=sqlite")
+}
+
+// ConfigurePool keeps a single connection that never expires: SQLite has
+// one writer at a time anyway, and an in-memory database lives only as long
+// as its connection.
+func (sqliteDriver) ConfigurePool(db *sql.DB, cfg config.Database) {
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+}
+
+func (sqliteDriver) Migrate(db *gorm.DB, cfg config.Database) error {
+	for _, stmt := range []string{
+		`CREATE TRIGGER IF NOT EXISTS audit_entries_no_update
+			BEFORE UPDATE ON audit_entries
+			BEGIN SELECT RAISE(ABORT, 'audit entries are append-only'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_entries_no_delete
+			BEFORE DELETE ON audit_entries
+			BEGIN SELECT RAISE(ABORT, 'audit entries are append-only'); END`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("protect audit entries: %w", err)
+		}
+	}
+	return nil
+}
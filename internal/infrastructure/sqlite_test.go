@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/config"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLite(t *testing.T) {
+	cfg := config.Database{
+		Driver:          "sqlite",
+		URL:             "file:" + filepath.Join(t.TempDir(), "orders.db"),
+		ConnectDeadline: time.Second,
+	}
+	open := func() GormDB {
+		db, err := NewGormDB(cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			sqlDB, _ := db.GetConnection().DB()
+			sqlDB.Close()
+		})
+		return db
+	}
+
+	ctx := tenant.With(context.Background(), "shop-a")
+	conn := open().GetConnection().WithContext(ctx)
+	entry := model.AuditEntry{OrderID: 1, Operation: "create", Actor: "alice", CreatedAt: time.Now()}
+	require.NoError(t, conn.Create(&entry).Error)
+	assert.ErrorContains(t, conn.Model(&entry).Update("actor", "mallory").Error, "append-only")
+	assert.ErrorContains(t, conn.Delete(&entry).Error, "append-only")
+
+	// migrating an existing database keeps its rows
+	entries := []model.AuditEntry{}
+	require.NoError(t, open().GetConnection().WithContext(ctx).Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alice", entries[0].Actor)
+}
+
+func TestNewGormDBUnknownDriver(t *testing.T) {
+	_, err := NewGormDB(config.Database{Driver: "oracle"})
+	assert.EqualError(t, err, `unknown database driver "oracle"`)
+}
@@ -0,0 +1,149 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantTables are the tables of the models with a TenantID field.
+var tenantTables = []string{
+	"orders",
+	"items",
+	"order_summaries",
+	"audit_entries",
+	"outbox_events",
+	"webhook_subscriptions",
+	"webhook_deliveries",
+	"jobs",
+	"promotions",
+	"promotion_redemptions",
+}
+
+// registerTenantScope keeps statements on models with a TenantID field to
+// the tenant of their context: reads, updates and deletes only reach its
+// rows, and the rows written are stamped with it. Raw SQL is left alone.
+// Statements of background work marked by tenant.WithAllTenants reach every
+// row; those with neither a tenant nor the mark fail with
+// tenant.ErrUnscoped.
+func registerTenantScope(db *gorm.DB) error {
+	for _, err := range []error{
+		db.Callback().Create().Before("gorm:create").Register("tenant:stamp", stampTenant),
+		db.Callback().Query().Before("gorm:query").Register("tenant:scope", scopeTenant),
+		db.Callback().Update().Before("gorm:update").Register("tenant:stamp", stampTenant),
+		db.Callback().Update().Before("gorm:update").Register("tenant:scope", scopeTenant),
+		db.Callback().Delete().Before("gorm:delete").Register("tenant:scope", scopeTenant),
+		db.Callback().Row().Before("gorm:row").Register("tenant:scope", scopeTenant),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantField returns the TenantID field of the statement model and the
+// tenant of its context. ok is false when the statement is not kept to a
+// tenant: the model has no such field, the statement acts for every tenant
+// or it failed, for want of a tenant among others.
+func tenantField(db *gorm.DB) (field *schema.Field, id string, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, "", false
+	}
+	field = db.Statement.Schema.LookUpField("TenantID")
+	if field == nil {
+		return nil, "", false
+	}
+	id, err := tenant.Scope(db.Statement.Context)
+	if err != nil {
+		db.AddError(fmt.Errorf("%s: %w", db.Statement.Schema.Table, err))
+		return nil, "", false
+	}
+	return field, id, id != ""
+}
+
+func scopeTenant(db *gorm.DB) {
+	field, id, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+func stampTenant(db *gorm.DB) {
+	field, id, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			db.AddError(field.Set(db.Statement.Context, reflect.Indirect(rv.Index(i)), id))
+		}
+	case reflect.Struct:
+		db.AddError(field.Set(db.Statement.Context, rv, id))
+	}
+}
+
+// registerRowLevelSecurity hands the tenant of each statement, or the mark
+// of WithAllTenants, to the policies of migrateRowLevelSecurity. The
+// settings are local to a transaction, so reads run in one of their own.
+// Raw statements, which GORM runs as rows or execs, get them inside
+// transactions only: raw reads outside one go through Find instead of Scan.
+func registerRowLevelSecurity(db *gorm.DB) error {
+	for _, err := range []error{
+		db.Callback().Query().Before("gorm:query").Register("tenant:begin_transaction", beginTenantTransaction),
+		db.Callback().Query().Before("gorm:query").After("tenant:begin_transaction").Register("tenant:set_config", setTenantConfig),
+		db.Callback().Query().After("gorm:after_query").Register("tenant:commit_or_rollback_transaction", callbacks.CommitOrRollbackTransaction),
+		db.Callback().Create().After("gorm:begin_transaction").Before("gorm:before_create").Register("tenant:set_config", setTenantConfig),
+		db.Callback().Update().After("gorm:begin_transaction").Before("gorm:before_update").Register("tenant:set_config", setTenantConfig),
+		db.Callback().Delete().After("gorm:begin_transaction").Before("gorm:before_delete").Register("tenant:set_config", setTenantConfig),
+		db.Callback().Row().Before("gorm:row").Register("tenant:set_config", setTenantConfig),
+		db.Callback().Raw().Before("gorm:raw").Register("tenant:set_config", setTenantConfig),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func beginTenantTransaction(db *gorm.DB) {
+	if _, _, ok := tenantSetting(db.Statement.Context); ok {
+		callbacks.BeginTransaction(db)
+	}
+}
+
+func setTenantConfig(db *gorm.DB) {
+	name, value, ok := tenantSetting(db.Statement.Context)
+	if !ok || db.Error != nil {
+		return
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); !inTx {
+		return
+	}
+	if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, `SELECT set_config($1, $2, true)`, name, value); err != nil {
+		db.AddError(err)
+	}
+}
+
+// tenantSetting returns the setting that lets the statements of ctx through
+// the policies: the tenant of ctx, or else the mark of WithAllTenants.
+func tenantSetting(ctx context.Context) (name, value string, ok bool) {
+	if id, ok := tenant.FromContext(ctx); ok {
+		return "app.tenant_id", id, true
+	}
+	if tenant.AllTenants(ctx) {
+		return "app.all_tenants", "on", true
+	}
+	return "", "", false
+}
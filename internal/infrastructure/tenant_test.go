@@ -0,0 +1,126 @@
+package infrastructure
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/config"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestTenantScope(t *testing.T) {
+	db, err := NewGormDB(config.Database{Driver: "sqlite", URL: "file::memory:", ConnectDeadline: time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.GetConnection().DB()
+		sqlDB.Close()
+	})
+	conn := db.GetConnection()
+	shopA := tenant.With(context.Background(), "shop-a")
+	shopB := tenant.With(context.Background(), "shop-b")
+
+	orders := []model.Order{{CustomerName: "alice"}, {CustomerName: "amy"}}
+	require.NoError(t, conn.WithContext(shopA).Create(&orders).Error)
+	assert.Equal(t, "shop-a", orders[1].TenantID)
+	bob := model.Order{CustomerName: "bob", Items: []model.Item{{ItemCode: "B1", Quantity: 1}}}
+	require.NoError(t, conn.WithContext(shopB).Create(&bob).Error)
+	assert.Equal(t, "shop-b", bob.Items[0].TenantID)
+	all := tenant.WithAllTenants(context.Background())
+	system := model.Order{CustomerName: "carol"}
+	require.NoError(t, conn.WithContext(all).Create(&system).Error)
+	assert.Equal(t, tenant.Default, system.TenantID)
+
+	found := []model.Order{}
+	require.NoError(t, conn.WithContext(shopA).Preload("Items").Find(&found).Error)
+	assert.Len(t, found, 2)
+	var count int64
+	require.NoError(t, conn.WithContext(shopA).Model(&model.Item{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// writes do not reach the rows of other tenants
+	res := conn.WithContext(shopA).Model(&model.Order{}).Where("id = ?", bob.ID).Update("customer_name", "mallory")
+	require.NoError(t, res.Error)
+	assert.Zero(t, res.RowsAffected)
+	res = conn.WithContext(shopA).Delete(&model.Order{}, bob.ID)
+	require.NoError(t, res.Error)
+	assert.Zero(t, res.RowsAffected)
+
+	// background work marked for every tenant sees every row
+	found = []model.Order{}
+	require.NoError(t, conn.WithContext(all).Find(&found).Error)
+	assert.Len(t, found, 4)
+
+	// a context with neither a tenant nor the mark is refused
+	assert.ErrorIs(t, conn.Find(&found).Error, tenant.ErrUnscoped)
+	assert.ErrorIs(t, conn.Create(&model.Order{CustomerName: "dave"}).Error, tenant.ErrUnscoped)
+	assert.ErrorIs(t, conn.Model(&model.Order{}).Where("id = ?", bob.ID).Update("customer_name", "mallory").Error, tenant.ErrUnscoped)
+	assert.ErrorIs(t, conn.Delete(&model.Order{}, bob.ID).Error, tenant.ErrUnscoped)
+	stored := model.Order{}
+	require.NoError(t, conn.WithContext(all).First(&stored, bob.ID).Error)
+	assert.Equal(t, "bob", stored.CustomerName)
+}
+
+func TestRowLevelSecurity(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, registerTenantScope(db))
+	require.NoError(t, registerRowLevelSecurity(db))
+	shopA := tenant.With(context.Background(), "shop-a")
+
+	// reads with a tenant run in a transaction that sets it
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config($1, $2, true)`)).
+		WithArgs("app.tenant_id", "shop-a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders" WHERE "orders"."tenant_id" = $1`)).
+		WithArgs("shop-a").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	orders := []model.Order{}
+	require.NoError(t, db.WithContext(shopA).Find(&orders).Error)
+	assert.Len(t, orders, 1)
+
+	// writes set it in their own transaction
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config($1, $2, true)`)).
+		WithArgs("app.tenant_id", "shop-a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "orders" WHERE "orders"."id" = $1 AND "orders"."tenant_id" = $2`)).
+		WithArgs(1, "shop-a").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, db.WithContext(shopA).Delete(&model.Order{}, 1).Error)
+
+	// background work marked for every tenant says so
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config($1, $2, true)`)).
+		WithArgs("app.all_tenants", "on").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	require.NoError(t, db.WithContext(tenant.WithAllTenants(context.Background())).Find(&orders).Error)
+
+	// raw statements in a transaction set it too
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT set_config($1, $2, true)`)).
+		WithArgs("app.tenant_id", "shop-a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT MAX(id) FROM orders`)).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+	mock.ExpectCommit()
+	var maxID uint64
+	require.NoError(t, db.WithContext(shopA).Transaction(func(tx *gorm.DB) error {
+		return tx.Raw(`SELECT MAX(id) FROM orders`).Scan(&maxID).Error
+	}))
+	assert.Equal(t, uint64(1), maxID)
+
+	// reads with neither never reach the database
+	assert.ErrorIs(t, db.Find(&orders).Error, tenant.ErrUnscoped)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
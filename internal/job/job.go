@@ -0,0 +1,73 @@
+// Package job runs long operations, such as imports, exports and purges, in
+// background workers that take their work from the jobs table.
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+)
+
+// Handler runs the jobs of one type.
+type Handler interface {
+	// Validate checks the parameters and input of a job before it is
+	// queued.
+	Validate(params []byte, input []byte) error
+	// Run performs job. It reports how many orders it has processed so far
+	// through progress.
+	Run(ctx context.Context, job model.Job, input []byte, progress func(n int)) (Result, error)
+}
+
+// Result is what a successful job leaves behind.
+type Result struct {
+	// Report is stored as JSON and shown with the job.
+	Report any
+	// Output is a file to download from the job, or nil.
+	Output *model.JobFile
+}
+
+// Type describes one job type.
+type Type struct {
+	Handler Handler
+	// MaxAttempts is how many times a job is started before it is failed.
+	// Jobs that cannot safely run twice use 1.
+	MaxAttempts int
+}
+
+// Registry maps job type names to their handlers. It implements
+// service.JobTypes.
+type Registry map[string]Type
+
+var _ service.JobTypes = Registry{}
+
+func (r Registry) Validate(typ string, params []byte, input []byte) (int, error) {
+	t, ok := r[typ]
+	if !ok {
+		return 0, fmt.Errorf("unknown job type %q", typ)
+	}
+	if err := t.Handler.Validate(params, input); err != nil {
+		return 0, err
+	}
+	return t.MaxAttempts, nil
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one that retrying cannot fix, so the job fails
+// right away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
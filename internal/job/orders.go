@@ -0,0 +1,269 @@
+package job
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/exporter"
+	"github.com/MidnightHelix/assignment-2/internal/filestore"
+	"github.com/MidnightHelix/assignment-2/internal/importer"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/service"
+)
+
+// Job type names.
+const (
+	TypeImport = "import"
+	TypeExport = "export"
+	TypePurge  = "purge"
+)
+
+// NewRegistry returns the order job types. Imports run only once, since
+// the batches they committed before failing would be imported again.
+// Exports are written to files.
+func NewRegistry(svc service.OrderService, imp importer.Importer, files filestore.Store, importBatchSize, maxBatchSize, maxAttempts int) Registry {
+	return Registry{
+		TypeImport: {Handler: &importHandler{imp: imp, defaultBatchSize: importBatchSize, maxBatchSize: maxBatchSize}, MaxAttempts: 1},
+		TypeExport: {Handler: &exportHandler{svc: svc, files: files}, MaxAttempts: maxAttempts},
+		TypePurge:  {Handler: &purgeHandler{svc: svc, batchSize: maxBatchSize}, MaxAttempts: maxAttempts},
+	}
+}
+
+// filterParams select the orders of export and purge jobs, like the query
+// parameters of the order listing.
+type filterParams struct {
+	CustomerName string    `json:"customer_name"`
+	Status       string    `json:"status"`
+	OrderedFrom  time.Time `json:"ordered_from"`
+	OrderedTo    time.Time `json:"ordered_to"`
+}
+
+func (p filterParams) filter() model.OrderFilter {
+	return model.OrderFilter{
+		CustomerName: p.CustomerName,
+		Status:       p.Status,
+		OrderedFrom:  p.OrderedFrom,
+		OrderedTo:    p.OrderedTo,
+	}
+}
+
+func decodeParams(params []byte, dst any) error {
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return fmt.Errorf("invalid params: %v", err)
+	}
+	return nil
+}
+
+type importParams struct {
+	// Format is csv or ndjson.
+	Format    importer.Format `json:"format"`
+	BatchSize int             `json:"batch_size"`
+	DryRun    bool            `json:"dry_run"`
+}
+
+type importHandler struct {
+	imp              importer.Importer
+	defaultBatchSize int
+	maxBatchSize     int
+}
+
+func (h *importHandler) params(raw []byte) (importParams, error) {
+	p := importParams{BatchSize: h.defaultBatchSize}
+	if err := decodeParams(raw, &p); err != nil {
+		return importParams{}, err
+	}
+	if p.Format != importer.CSV && p.Format != importer.NDJSON {
+		return importParams{}, errors.New("format must be csv or ndjson")
+	}
+	if p.BatchSize < 1 || p.BatchSize > h.maxBatchSize {
+		return importParams{}, fmt.Errorf("batch_size must be between 1 and %d", h.maxBatchSize)
+	}
+	return p, nil
+}
+
+func (h *importHandler) Validate(params []byte, input []byte) error {
+	if _, err := h.params(params); err != nil {
+		return err
+	}
+	if len(input) == 0 {
+		return errors.New("an import needs a file")
+	}
+	return nil
+}
+
+func (h *importHandler) Run(ctx context.Context, job model.Job, input []byte, progress func(n int)) (Result, error) {
+	p, err := h.params(job.Params)
+	if err != nil {
+		return Result{}, Permanent(err)
+	}
+	rep, err := h.imp.Import(ctx, bytes.NewReader(input), importer.Options{
+		Format:    p.Format,
+		BatchSize: p.BatchSize,
+		DryRun:    p.DryRun,
+		Progress:  progress,
+	})
+	if errors.Is(err, importer.ErrInvalidFile) {
+		err = Permanent(err)
+	}
+	return Result{Report: rep}, err
+}
+
+type exportParams struct {
+	filterParams
+	// Format is csv or ndjson.
+	Format exporter.Format `json:"format"`
+}
+
+type exportReport struct {
+	Orders int `json:"orders"`
+}
+
+type exportHandler struct {
+	svc   service.OrderService
+	files filestore.Store
+}
+
+func (h *exportHandler) params(raw []byte) (exportParams, error) {
+	p := exportParams{Format: exporter.CSV}
+	if err := decodeParams(raw, &p); err != nil {
+		return exportParams{}, err
+	}
+	if p.Format.ContentType() == "" {
+		return exportParams{}, errors.New("format must be csv or ndjson")
+	}
+	return p, nil
+}
+
+func (h *exportHandler) Validate(params []byte, input []byte) error {
+	_, err := h.params(params)
+	return err
+}
+
+// Run streams the export gzip compressed into a file of the file store, a
+// chunk of orders at a time, so that memory stays flat however many orders
+// match. The job keeps the key of the file.
+func (h *exportHandler) Run(ctx context.Context, job model.Job, input []byte, progress func(n int)) (Result, error) {
+	p, err := h.params(job.Params)
+	if err != nil {
+		return Result{}, Permanent(err)
+	}
+
+	key, f, err := h.files.Create(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	rep, err := h.export(ctx, f, p, progress)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := h.files.Remove(context.WithoutCancel(ctx), key); removeErr != nil {
+			err = errors.Join(err, removeErr)
+		}
+		return Result{}, err
+	}
+	return Result{
+		Report: rep,
+		Output: &model.JobFile{
+			Name:        fmt.Sprintf("orders-%d.%s", job.ID, p.Format),
+			ContentType: p.Format.ContentType(),
+			Encoding:    "gzip",
+			StorageKey:  key,
+		},
+	}, nil
+}
+
+func (h *exportHandler) export(ctx context.Context, dst io.Writer, p exportParams, progress func(n int)) (exportReport, error) {
+	gz := gzip.NewWriter(dst)
+	w, _ := exporter.NewWriter(gz, p.Format)
+	rep := exportReport{}
+	err := h.svc.ExportOrders(ctx, p.filter(), func(orders []model.Order) error {
+		rep.Orders += len(orders)
+		progress(rep.Orders)
+		return w.Write(orders)
+	})
+	if err == nil && rep.Orders == 0 {
+		// still write the CSV header
+		err = w.Write(nil)
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	return rep, err
+}
+
+type purgeReport struct {
+	Matched int `json:"matched"`
+	Deleted int `json:"deleted"`
+}
+
+// purgeHandler deletes every order matching a filter. Orders are deleted
+// one by one through the order service, so each deletion emits its event.
+type purgeHandler struct {
+	svc       service.OrderService
+	batchSize int
+}
+
+func (h *purgeHandler) params(raw []byte) (filterParams, error) {
+	p := filterParams{}
+	if err := decodeParams(raw, &p); err != nil {
+		return filterParams{}, err
+	}
+	if p.filter() == (model.OrderFilter{}) {
+		return filterParams{}, errors.New("a purge needs at least one of customer_name, status, ordered_from and ordered_to")
+	}
+	return p, nil
+}
+
+func (h *purgeHandler) Validate(params []byte, input []byte) error {
+	_, err := h.params(params)
+	return err
+}
+
+func (h *purgeHandler) Run(ctx context.Context, job model.Job, input []byte, progress func(n int)) (Result, error) {
+	p, err := h.params(job.Params)
+	if err != nil {
+		return Result{}, Permanent(err)
+	}
+
+	// collect the IDs first rather than deleting rows under the running
+	// export query
+	ids := []uint64{}
+	if err := h.svc.ExportOrders(ctx, p.filter(), func(orders []model.Order) error {
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+		return nil
+	}); err != nil {
+		return Result{}, err
+	}
+
+	rep := purgeReport{Matched: len(ids)}
+	for start := 0; start < len(ids); start += h.batchSize {
+		end := min(start+h.batchSize, len(ids))
+		results, err := h.svc.DeleteOrders(ctx, ids[start:end], service.BatchPartial)
+		if err != nil {
+			return Result{}, err
+		}
+		for _, res := range results {
+			// an order deleted by someone else, or by an earlier attempt,
+			// is gone all the same
+			if res.Err != nil && !errors.Is(res.Err, service.ErrOrderNotFound) {
+				return Result{}, res.Err
+			}
+			if res.Err == nil {
+				rep.Deleted++
+			}
+		}
+		progress(end)
+	}
+	return Result{Report: rep}, nil
+}
@@ -0,0 +1,209 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/filestore"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/MidnightHelix/assignment-2/pkg"
+)
+
+// Pool runs queued jobs on a fixed number of workers. Pools in several
+// processes can share one database: each job is claimed by one worker at a
+// time, and a job whose worker stops extending its lock is picked up again
+// once the lock expires.
+type Pool interface {
+	// Run starts the workers and blocks until ctx is done and the jobs they
+	// were running have been released.
+	Run(ctx context.Context)
+	// RunOnce claims and runs one job as worker. It reports whether there
+	// was a job to run.
+	RunOnce(ctx context.Context, worker string) (bool, error)
+}
+
+type Config struct {
+	Concurrency  int
+	PollInterval time.Duration
+	// VisibilityTimeout is how long a claimed job stays locked without its
+	// worker extending the lock. Workers extend it three times per timeout.
+	VisibilityTimeout time.Duration
+	// Backoff spaces out the retries of failed jobs.
+	Backoff pkg.Backoff
+}
+
+type poolImpl struct {
+	repo  repository.JobRepository
+	files filestore.Store
+	types Registry
+	cfg   Config
+	now   func() time.Time
+}
+
+// NewPool returns a pool that runs the job types of types. Output files
+// that jobs leave in files are removed when their job cannot be saved.
+func NewPool(repo repository.JobRepository, files filestore.Store, types Registry, cfg Config) Pool {
+	return &poolImpl{repo: repo, files: files, types: types, cfg: cfg, now: time.Now}
+}
+
+func (p *poolImpl) Run(ctx context.Context) {
+	host, _ := os.Hostname()
+	var wg sync.WaitGroup
+	for i := 0; i < p.cfg.Concurrency; i++ {
+		worker := fmt.Sprintf("%s/%d/%d", host, os.Getpid(), i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx, worker)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *poolImpl) work(ctx context.Context, worker string) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// drain the queue before waiting for the next tick
+		ran, err := p.RunOnce(ctx, worker)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("job: worker %s: %v", worker, err)
+		}
+		if ran && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *poolImpl) RunOnce(ctx context.Context, worker string) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+	now := p.now().UTC()
+	if err := p.repo.FailExpiredJobs(ctx, now); err != nil {
+		return false, err
+	}
+	job, err := p.repo.ClaimJob(ctx, worker, now, now.Add(p.cfg.VisibilityTimeout))
+	if err != nil || job.ID == 0 {
+		return false, err
+	}
+
+	res, progress, runErr := p.run(ctx, worker, job)
+	job.Progress = progress
+
+	// record the outcome even when ctx ended during the run
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	var output *model.JobFile
+	switch {
+	case runErr == nil:
+		output = res.Output
+		report, err := json.Marshal(res.Report)
+		if err != nil {
+			return true, err
+		}
+		p.finish(&job, model.JobStatusSucceeded, "")
+		job.Result = report
+	case ctx.Err() != nil:
+		// shutting down; let the next process retry the job if it may
+		p.retryOrFail(&job, "interrupted by shutdown", false)
+		job.RunAt = p.now().UTC()
+	default:
+		p.retryOrFail(&job, runErr.Error(), isPermanent(runErr))
+	}
+
+	held, err := p.repo.FinishJob(saveCtx, job, worker, output)
+	if (err != nil || !held) && output != nil && output.StorageKey != "" {
+		if err := p.files.Remove(saveCtx, output.StorageKey); err != nil {
+			log.Printf("job: worker %s: removing the output of job %d: %v", worker, job.ID, err)
+		}
+	}
+	if err != nil {
+		return true, err
+	}
+	if !held {
+		log.Printf("job: worker %s lost the lock of job %d; its outcome was dropped", worker, job.ID)
+	}
+	return true, nil
+}
+
+// run executes job while extending its lock. When the lock is lost, the
+// context of the handler is cancelled.
+func (p *poolImpl) run(ctx context.Context, worker string, job model.Job) (Result, int, error) {
+	var progress atomic.Int64
+	progress.Store(int64(job.Progress))
+
+	t, ok := p.types[job.Type]
+	if !ok {
+		return Result{}, job.Progress, Permanent(fmt.Errorf("unknown job type %q", job.Type))
+	}
+	input, err := p.repo.GetJobFile(ctx, job.ID, model.JobFileInput)
+	if err != nil {
+		return Result{}, job.Progress, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(p.cfg.VisibilityTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			held, err := p.repo.ExtendJob(runCtx, job.ID, worker, int(progress.Load()), p.now().UTC().Add(p.cfg.VisibilityTimeout))
+			if err != nil {
+				log.Printf("job: extending job %d: %v", job.ID, err)
+				continue
+			}
+			if !held {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	// the handler acts for the tenant that submitted the job
+	handlerCtx := runCtx
+	if job.TenantID != "" {
+		handlerCtx = tenant.With(runCtx, job.TenantID)
+	}
+	res, err := t.Handler.Run(handlerCtx, job, input.Data, func(n int) { progress.Store(int64(n)) })
+	return res, int(progress.Load()), err
+}
+
+func (p *poolImpl) finish(job *model.Job, status, msg string) {
+	now := p.now().UTC()
+	job.Status = status
+	job.Error = msg
+	job.FinishedAt = &now
+}
+
+// retryOrFail queues job again after a backoff, or fails it when the error
+// is permanent or no attempts are left.
+func (p *poolImpl) retryOrFail(job *model.Job, msg string, permanent bool) {
+	if permanent || job.Attempts >= job.MaxAttempts {
+		p.finish(job, model.JobStatusFailed, msg)
+		return
+	}
+	job.Status = model.JobStatusQueued
+	job.Error = msg
+	job.RunAt = p.now().UTC().Add(p.cfg.Backoff.Duration(job.Attempts - 1))
+}
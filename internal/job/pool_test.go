@@ -0,0 +1,167 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/filestore"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/repository/mocks"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHandler struct {
+	res Result
+	err error
+}
+
+func (h fakeHandler) Validate(params []byte, input []byte) error { return h.err }
+
+func (h fakeHandler) Run(ctx context.Context, job model.Job, input []byte, progress func(n int)) (Result, error) {
+	progress(len(input))
+	return h.res, h.err
+}
+
+func newTestPool(repo repository.JobRepository, h Handler) *poolImpl {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &poolImpl{
+		repo:  repo,
+		types: Registry{"test": {Handler: h, MaxAttempts: 3}},
+		cfg:   Config{VisibilityTimeout: time.Minute, Backoff: pkg.Backoff{Initial: time.Second, Max: time.Minute}},
+		now:   func() time.Time { return now },
+	}
+}
+
+// finishedJob is what the pool saved of a job.
+type finishedJob struct {
+	job    model.Job
+	output *model.JobFile
+}
+
+// claimableJob returns a repository from which worker w1 claims job, with
+// input as its input file. FinishJob reports held and records what the pool
+// saved.
+func claimableJob(t *testing.T, job model.Job, input []byte, held bool) (*mocks.JobRepository, *finishedJob) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	job.Status = model.JobStatusRunning
+	job.Attempts++
+
+	repo := mocks.NewJobRepository(t)
+	repo.On("FailExpiredJobs", mock.Anything, now).Return(nil)
+	repo.On("ClaimJob", mock.Anything, "w1", now, now.Add(time.Minute)).Return(job, nil)
+	// the pool only reads the input of job types it knows
+	if job.Type == "test" {
+		repo.On("GetJobFile", mock.Anything, job.ID, model.JobFileInput).Return(model.JobFile{Data: input}, nil)
+	}
+	finished := &finishedJob{}
+	repo.On("FinishJob", mock.Anything, mock.Anything, "w1", mock.Anything).
+		Run(func(args mock.Arguments) {
+			finished.job = args.Get(1).(model.Job)
+			finished.output, _ = args.Get(3).(*model.JobFile)
+		}).
+		Return(held, nil)
+	return repo, finished
+}
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("no job due", func(t *testing.T) {
+		repo := mocks.NewJobRepository(t)
+		repo.On("FailExpiredJobs", mock.Anything, now).Return(nil)
+		repo.On("ClaimJob", mock.Anything, "w1", now, now.Add(time.Minute)).Return(model.Job{}, nil)
+		ran, err := newTestPool(repo, fakeHandler{}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.False(t, ran)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo, finished := claimableJob(t, model.Job{ID: 1, Type: "test", MaxAttempts: 3}, []byte("abc"), true)
+		output := &model.JobFile{Data: []byte("out")}
+		ran, err := newTestPool(repo, fakeHandler{res: Result{Report: map[string]int{"orders": 3}, Output: output}}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, model.JobStatusSucceeded, finished.job.Status)
+		assert.Equal(t, 3, finished.job.Progress)
+		assert.JSONEq(t, `{"orders":3}`, string(finished.job.Result))
+		assert.Equal(t, &now, finished.job.FinishedAt)
+		assert.Equal(t, output, finished.output)
+	})
+
+	t.Run("failed attempt is retried after a backoff", func(t *testing.T) {
+		repo, finished := claimableJob(t, model.Job{ID: 1, Type: "test", Attempts: 1, MaxAttempts: 3}, nil, true)
+		_, err := newTestPool(repo, fakeHandler{err: errors.New("db down")}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.Equal(t, model.JobStatusQueued, finished.job.Status)
+		assert.Equal(t, "db down", finished.job.Error)
+		assert.True(t, finished.job.RunAt.After(now))
+		assert.False(t, finished.job.RunAt.After(now.Add(2*time.Second)))
+		assert.Nil(t, finished.job.FinishedAt)
+	})
+
+	t.Run("last attempt fails the job", func(t *testing.T) {
+		repo, finished := claimableJob(t, model.Job{ID: 1, Type: "test", Attempts: 2, MaxAttempts: 3}, nil, true)
+		_, err := newTestPool(repo, fakeHandler{err: errors.New("db down")}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.Equal(t, model.JobStatusFailed, finished.job.Status)
+		assert.Equal(t, "db down", finished.job.Error)
+		assert.NotNil(t, finished.job.FinishedAt)
+	})
+
+	t.Run("permanent error fails the job", func(t *testing.T) {
+		repo, finished := claimableJob(t, model.Job{ID: 1, Type: "test", MaxAttempts: 3}, nil, true)
+		_, err := newTestPool(repo, fakeHandler{err: Permanent(errors.New("bad params"))}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.Equal(t, model.JobStatusFailed, finished.job.Status)
+		assert.Equal(t, "bad params", finished.job.Error)
+	})
+
+	t.Run("unknown type fails the job", func(t *testing.T) {
+		repo, finished := claimableJob(t, model.Job{ID: 1, Type: "other", MaxAttempts: 3}, nil, true)
+		_, err := newTestPool(repo, fakeHandler{}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.Equal(t, model.JobStatusFailed, finished.job.Status)
+	})
+
+	t.Run("lost lock drops the outcome", func(t *testing.T) {
+		repo, _ := claimableJob(t, model.Job{ID: 1, Type: "test", MaxAttempts: 3}, nil, false)
+		ran, err := newTestPool(repo, fakeHandler{}).RunOnce(context.Background(), "w1")
+		assert.Nil(t, err)
+		assert.True(t, ran)
+		repo.AssertCalled(t, "FinishJob", mock.Anything, mock.Anything, "w1", mock.Anything)
+	})
+
+	t.Run("lost lock removes the output file", func(t *testing.T) {
+		ctx := context.Background()
+		files, err := filestore.NewDirStore(t.TempDir())
+		require.NoError(t, err)
+		key, w, err := files.Create(ctx)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		repo, _ := claimableJob(t, model.Job{ID: 1, Type: "test", MaxAttempts: 3}, nil, false)
+		pool := newTestPool(repo, fakeHandler{res: Result{Output: &model.JobFile{StorageKey: key}}})
+		pool.files = files
+		_, err = pool.RunOnce(ctx, "w1")
+		assert.Nil(t, err)
+		_, err = files.Open(ctx, key)
+		assert.ErrorIs(t, err, filestore.ErrNotFound)
+	})
+}
+
+func TestRegistryValidate(t *testing.T) {
+	r := Registry{"test": {Handler: fakeHandler{}, MaxAttempts: 2}}
+
+	n, err := r.Validate("test", []byte("{}"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = r.Validate("other", []byte("{}"), nil)
+	assert.EqualError(t, err, `unknown job type "other"`)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(Auth(auth.NewStaticAuthenticator(map[string]auth.Principal{"k1": {Name: "alice"}}), "/stream/:id"))
+	whoami := func(ctx *gin.Context) {
+		p, _ := auth.FromContext(ctx)
+		ctx.String(http.StatusOK, p.Name)
+	}
+	router.GET("/whoami", whoami)
+	router.GET("/stream/:id", whoami)
+
+	tests := []struct {
+		name   string
+		path   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		{"bearer token", "/whoami", func(r *http.Request) { r.Header.Set("Authorization", "Bearer k1") }, http.StatusOK},
+		{"api key header", "/whoami", func(r *http.Request) { r.Header.Set("X-API-Key", "k1") }, http.StatusOK},
+		{"query parameter on stream", "/stream/1", func(r *http.Request) { r.URL.RawQuery = "access_token=k1" }, http.StatusOK},
+		{"query parameter elsewhere", "/whoami", func(r *http.Request) { r.URL.RawQuery = "access_token=k1" }, http.StatusUnauthorized},
+		{"wrong key", "/whoami", func(r *http.Request) { r.Header.Set("Authorization", "Bearer k2") }, http.StatusUnauthorized},
+		{"no credentials", "/stream/1", func(r *http.Request) {}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			tt.setup(req)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, "alice", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Auth(auth.NewStaticAuthenticator(map[string]auth.Principal{
+		"k1": {Name: "alice"},
+		"k2": {Name: "ops", Admin: true},
+	})), Admin())
+	router.POST("/webhooks", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+
+	for key, status := range map[string]int{"k1": http.StatusForbidden, "k2": http.StatusCreated} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/webhooks", nil)
+		req.Header.Set("X-API-Key", key)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, key)
+	}
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier
+	// request with the same key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// IdempotencyConfig controls the Idempotency middleware.
+type IdempotencyConfig struct {
+	// TTL is how long the response to a request is kept for retries.
+	TTL time.Duration
+	// LockTimeout is how long a request may be served before a retry with
+	// its key presumes it lost and runs again.
+	LockTimeout time.Duration
+	// MaxBodyBytes caps the bodies of requests sent with a key.
+	MaxBodyBytes int64
+}
+
+// Idempotency makes POST and PATCH requests sent with an Idempotency-Key
+// safe to retry: the first request with a key is served and its response
+// stored for cfg.TTL, and later requests with the same key get that response
+// without running again. A key reused with a different request is
+// rejected, as is a retry that arrives while the first request is still
+// being served. Responses with a 5xx status are not stored, so the request
+// can be retried with the same key. Requests without a key are passed
+// through as they are.
+func Idempotency(repo repository.IdempotencyRepository, cfg IdempotencyConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		method := ctx.Request.Method
+		if key == "" || (method != http.MethodPost && method != http.MethodPatch) {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "Idempotency-Key is too long"})
+			return
+		}
+		// the body is kept to tell retries from other requests
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, cfg.MaxBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, pkg.ErrorResponse{Message: fmt.Sprintf("a request sent with an Idempotency-Key may not exceed %d bytes", cfg.MaxBodyBytes)})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// keys are per caller and tenant
+		owner := ""
+		if p, ok := auth.FromContext(ctx.Request.Context()); ok {
+			owner = p.Name
+		}
+		if t, ok := tenant.FromContext(ctx.Request.Context()); ok {
+			owner = t + "/" + owner
+		}
+		hash := requestHash(method, ctx.Request.URL.RequestURI(), body)
+		now := time.Now().UTC()
+		rec, created, err := repo.Reserve(ctx, model.IdempotencyKey{
+			Owner:       owner,
+			Key:         key,
+			RequestHash: hash,
+			CreatedAt:   now,
+			LockedUntil: now.Add(cfg.LockTimeout),
+		}, now.Add(-cfg.TTL))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+			return
+		}
+		if !created {
+			replay(ctx, rec, hash)
+			return
+		}
+
+		rw := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = rw
+		ctx.Next()
+
+		// the response is sent; store it even if the client went away
+		saveCtx := context.WithoutCancel(ctx.Request.Context())
+		if rw.Status() >= http.StatusInternalServerError {
+			if err := repo.Release(saveCtx, rec.ID); err != nil {
+				log.Printf("idempotency: release key %q: %v", key, err)
+			}
+			return
+		}
+		if err := repo.Complete(saveCtx, rec.ID, rw.Status(), rw.Header().Get("Content-Type"), rw.body.Bytes()); err != nil {
+			log.Printf("idempotency: store response of key %q: %v", key, err)
+		}
+	}
+}
+
+// SweepIdempotencyKeys deletes the keys that expired, under the TTL and
+// LockTimeout of cfg, every interval until ctx is done. Reserve frees an
+// expired key when it is used again; the sweep deletes the rest.
+func SweepIdempotencyKeys(ctx context.Context, repo repository.IdempotencyRepository, cfg IdempotencyConfig, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		now := time.Now().UTC()
+		if n, err := repo.DeleteExpired(ctx, now.Add(-cfg.TTL), now); err != nil && ctx.Err() == nil {
+			log.Printf("idempotency: sweep failed: %v", err)
+		} else if n > 0 {
+			log.Printf("idempotency: deleted %d expired keys", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func replay(ctx *gin.Context, rec model.IdempotencyKey, hash string) {
+	switch {
+	case rec.RequestHash != hash:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, pkg.ErrorResponse{Message: "Idempotency-Key was already used with a different request"})
+	case rec.StatusCode == 0:
+		ctx.AbortWithStatusJSON(http.StatusConflict, pkg.ErrorResponse{Message: "a request with this Idempotency-Key is still being served"})
+	default:
+		ctx.Header(IdempotentReplayedHeader, "true")
+		ctx.Data(rec.StatusCode, rec.ContentType, rec.Body)
+		ctx.Abort()
+	}
+}
+
+func requestHash(method, uri string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+uri+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute, MaxBodyBytes: 64}
+	repo := mocks.NewIdempotencyRepository(t)
+	created, failures := 0, 1
+	router := gin.New()
+	router.Use(Idempotency(repo, cfg))
+	router.POST("/orders", func(ctx *gin.Context) {
+		if failures > 0 {
+			failures--
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"message": "try again"})
+			return
+		}
+		created++
+		ctx.JSON(http.StatusCreated, gin.H{"order_id": created})
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/orders", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// reservation matches a new reservation of key, locked for LockTimeout
+	reservation := func(key string) any {
+		return mock.MatchedBy(func(k model.IdempotencyKey) bool {
+			return k.Key == key && k.StatusCode == 0 && k.LockedUntil.Equal(k.CreatedAt.Add(cfg.LockTimeout))
+		})
+	}
+	expiry := mock.MatchedBy(func(expiredBefore time.Time) bool {
+		return time.Until(expiredBefore) < -cfg.TTL+time.Second
+	})
+	alice := requestHash("POST", "/orders", []byte(`{"customer_name":"alice"}`))
+
+	// a failed request frees its key for the retry
+	repo.On("Reserve", mock.Anything, reservation("k1"), expiry).
+		Return(model.IdempotencyKey{ID: 1, Key: "k1", RequestHash: alice}, true, nil).Once()
+	repo.On("Release", mock.Anything, uint64(1)).Return(nil).Once()
+	assert.Equal(t, http.StatusServiceUnavailable, send("k1", `{"customer_name":"alice"}`).Code)
+
+	repo.On("Reserve", mock.Anything, reservation("k1"), expiry).
+		Return(model.IdempotencyKey{ID: 2, Key: "k1", RequestHash: alice}, true, nil).Once()
+	repo.On("Complete", mock.Anything, uint64(2), http.StatusCreated, "application/json; charset=utf-8", []byte(`{"order_id":1}`)).
+		Return(nil).Once()
+	w := send("k1", `{"customer_name":"alice"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"order_id":1}`, w.Body.String())
+
+	stored := model.IdempotencyKey{ID: 2, Key: "k1", RequestHash: alice, StatusCode: http.StatusCreated, ContentType: "application/json; charset=utf-8", Body: []byte(`{"order_id":1}`)}
+	repo.On("Reserve", mock.Anything, reservation("k1"), expiry).Return(stored, false, nil).Twice()
+	w = send("k1", `{"customer_name":"alice"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"order_id":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, created)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, send("k1", `{"customer_name":"bob"}`).Code)
+
+	repo.On("Reserve", mock.Anything, reservation("k2"), expiry).
+		Return(model.IdempotencyKey{ID: 3, Key: "k2", RequestHash: requestHash("POST", "/orders", []byte(`{}`))}, false, nil).Once()
+	assert.Equal(t, http.StatusConflict, send("k2", `{}`).Code)
+
+	// bodies too large to keep are turned down before a key is reserved
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send("k3", strings.Repeat("x", 65)).Code)
+
+	// requests without a key are not buffered or recorded
+	assert.Equal(t, http.StatusCreated, send("", strings.Repeat("x", 65)).Code)
+	assert.Equal(t, http.StatusCreated, send("", `{}`).Code)
+	assert.Equal(t, 3, created)
+}
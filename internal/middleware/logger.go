@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redacted replaces the values of query parameters that must not be logged.
+const redacted = "REDACTED"
+
+// secretParams are query parameters that carry credentials.
+var secretParams = []string{"access_token"}
+
+// Logger logs every request like gin's default logger, with the values of
+// credential query parameters such as access_token redacted.
+func Logger(out io.Writer) gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logFormatter, Output: out})
+}
+
+func logFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactQuery(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactQuery hides the values of secretParams in a path with a query,
+// leaving the rest as it was sent.
+func redactQuery(path string) string {
+	p, query, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		// compare the key the way the query is parsed
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		for _, secret := range secretParams {
+			if name == secret {
+				pairs[i] = key + "=" + redacted
+			}
+		}
+	}
+	return p + "?" + strings.Join(pairs, "&")
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(Logger(&out))
+	router.GET("/stream", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	for _, query := range []string{"access_token=k1&status=paid", "status=paid&access%5Ftoken=k1"} {
+		out.Reset()
+		req, _ := http.NewRequest("GET", "/stream?"+query, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.NotContains(t, out.String(), "k1")
+		assert.Contains(t, out.String(), "status=paid")
+		assert.Contains(t, out.String(), "token="+redacted)
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	assert.Equal(t, "/orders", redactQuery("/orders"))
+	assert.Equal(t, "/ws?access_token=REDACTED", redactQuery("/ws?access_token=k1"))
+	assert.Equal(t, "/ws?a=1&access_token=REDACTED&b=2", redactQuery("/ws?a=1&access_token=k1&b=2"))
+	assert.Equal(t, "/ws?my_access_token=k1", redactQuery("/ws?my_access_token=k1"))
+}
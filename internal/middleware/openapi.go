@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	openapi3filter.RegisterBodyDecoder("application/merge-patch+json", openapi3filter.JSONBodyDecoder)
+}
+
+// OpenAPIValidation rejects requests that do not match doc: unknown paths
+// with 404, unknown methods with 405, bodies of an undeclared content type
+// with 415, and bad parameters or bodies with 400. Only JSON bodies are checked; uploads are left to their handler.
+//
+// With validateResponses, responses are held back until the handler
+// returns and replaced with a 500 when they do not match doc either, which
+// catches handlers drifting from their annotations in tests. Streamed
+// responses, which flush or hijack the connection, are not checked.
+func OpenAPIValidation(doc *openapi3.T, validateResponses bool) (gin.HandlerFunc, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	opts := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+	return func(ctx *gin.Context) {
+		route, params, err := router.FindRoute(ctx.Request)
+		if err != nil {
+			if allowed := allowedMethods(router, ctx.Request); len(allowed) > 0 {
+				ctx.Header("Allow", strings.Join(allowed, ", "))
+				ctx.AbortWithStatusJSON(http.StatusMethodNotAllowed, pkg.ErrorResponse{Message: "method not allowed"})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusNotFound, pkg.ErrorResponse{Message: "not found"})
+			return
+		}
+
+		if body := route.Operation.RequestBody; body != nil && body.Value != nil {
+			if ct := ctx.ContentType(); ct != "" && body.Value.Content.Get(ct) == nil {
+				types := []string{}
+				for t := range body.Value.Content {
+					types = append(types, t)
+				}
+				sort.Strings(types)
+				if ctx.Request.Method == http.MethodPatch {
+					ctx.Header("Accept-Patch", strings.Join(types, ", "))
+				}
+				ctx.AbortWithStatusJSON(http.StatusUnsupportedMediaType, pkg.ErrorResponse{
+					Message: "unsupported content type",
+					Errors:  []string{"expected one of " + strings.Join(types, ", ")},
+				})
+				return
+			}
+		}
+
+		reqOpts := *opts
+		reqOpts.ExcludeRequestBody = !jsonContent(ctx.ContentType())
+		input := &openapi3filter.RequestValidationInput{
+			Request:    ctx.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    &reqOpts,
+		}
+		if err := openapi3filter.ValidateRequest(ctx.Request.Context(), input); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, pkg.ErrorResponse{
+				Message: "request does not match the API description",
+				Errors:  validationErrors(err, ""),
+			})
+			return
+		}
+		if !validateResponses {
+			ctx.Next()
+			return
+		}
+
+		w := &heldResponseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+		ctx.Next()
+		ctx.Writer = w.ResponseWriter
+		if w.streaming {
+			return
+		}
+
+		resOpts := *opts
+		resOpts.IncludeResponseStatus = true
+		// event streams and NDJSON have no decoder to check them with
+		resOpts.ExcludeResponseBody = openapi3filter.RegisteredBodyDecoder(mediaType(w.Header().Get("Content-Type"))) == nil
+		err = openapi3filter.ValidateResponse(ctx.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 w.Status(),
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(w.body.Bytes())),
+			Options:                &resOpts,
+		})
+		if err != nil {
+			errs := validationErrors(err, "")
+			log.Printf("openapi: %s %s answered %d: %s", ctx.Request.Method, route.Path, w.Status(), strings.Join(errs, "; "))
+			w.Header().Del("Content-Length")
+			ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{
+				Message: "response does not match the API description",
+				Errors:  errs,
+			})
+			return
+		}
+		w.release()
+	}, nil
+}
+
+// allowedMethods lists the methods doc describes for the path of req.
+func allowedMethods(router routers.Router, req *http.Request) []string {
+	allowed := []string{}
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		r := *req
+		r.Method = method
+		if _, _, err := router.FindRoute(&r); err == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mt
+}
+
+func jsonContent(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// validationErrors lists the problems of a validation error, each prefixed
+// with the parameter or body field it concerns.
+func validationErrors(err error, where string) []string {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		res := []string{}
+		for _, err := range e {
+			res = append(res, validationErrors(err, where)...)
+		}
+		return res
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			where = fmt.Sprintf("%s parameter %q", e.Parameter.In, e.Parameter.Name)
+		case e.RequestBody != nil:
+			where = "request body"
+		}
+		if e.Err != nil {
+			return validationErrors(e.Err, where)
+		}
+		return []string{join(where, e.Reason)}
+	case *openapi3filter.ResponseError:
+		if e.Err != nil {
+			return validationErrors(e.Err, "response body")
+		}
+		return []string{join("response", e.Reason)}
+	case *openapi3.SchemaError:
+		if ptr := e.JSONPointer(); len(ptr) > 0 {
+			where = join(where, "/"+strings.Join(ptr, "/"))
+		}
+		return []string{join(where, e.Reason)}
+	}
+	return []string{join(where, err.Error())}
+}
+
+func join(where, msg string) string {
+	if where == "" {
+		return msg
+	}
+	return where + ": " + msg
+}
+
+// heldResponseWriter keeps the response until release is called, unless the
+// handler streams it by flushing or hijacking the connection.
+type heldResponseWriter struct {
+	gin.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+func (w *heldResponseWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *heldResponseWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+}
+
+func (w *heldResponseWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	w.WriteHeaderNow()
+	return w.body.Write(b)
+}
+
+func (w *heldResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *heldResponseWriter) Status() int {
+	if w.streaming || w.status == 0 {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *heldResponseWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if w.status == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *heldResponseWriter) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.status != 0
+}
+
+func (w *heldResponseWriter) Flush() {
+	w.stream()
+	w.ResponseWriter.Flush()
+}
+
+func (w *heldResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.streaming = true
+	return w.ResponseWriter.Hijack()
+}
+
+// stream sends what is held and lets later writes through.
+func (w *heldResponseWriter) stream() {
+	if !w.streaming {
+		w.release()
+		w.streaming = true
+	}
+}
+
+func (w *heldResponseWriter) release() {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.WriteHeaderNow()
+	}
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `{
+	"openapi": "3.0.3",
+	"info": {"title": "test", "version": "1"},
+	"servers": [{"url": "/api/v1"}],
+	"paths": {
+		"/orders/{id}": {
+			"get": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+				"responses": {"200": {"description": "", "content": {"application/json": {"schema": {
+					"type": "object",
+					"required": ["order_id"],
+					"properties": {"order_id": {"type": "integer"}}
+				}}}}}
+			},
+			"patch": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+				"requestBody": {"required": true, "content": {"application/merge-patch+json": {"schema": {
+					"type": "object",
+					"properties": {"status": {"type": "string"}}
+				}}}},
+				"responses": {"200": {"description": ""}}
+			}
+		}
+	}
+}`
+
+func newValidatedRouter(t *testing.T, validateResponses bool, order string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(testDoc))
+	require.NoError(t, err)
+	validate, err := OpenAPIValidation(doc, validateResponses)
+	require.NoError(t, err)
+
+	router := gin.New()
+	v := router.Group("/api/v1", validate)
+	v.GET("/orders/:id", func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json", []byte(order))
+	})
+	v.PATCH("/orders/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	v.DELETE("/orders/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	v.GET("/undocumented", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestOpenAPIValidationRequests(t *testing.T) {
+	router := newValidatedRouter(t, false, `{"order_id":"not a number"}`)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		code        int
+		errors      []string
+	}{
+		{"valid", "GET", "/api/v1/orders/1", "", "", http.StatusOK, nil},
+		{"bad path parameter", "GET", "/api/v1/orders/abc", "", "", http.StatusBadRequest, []string{`path parameter "id": value abc: an invalid integer: invalid syntax`}},
+		{"bad body", "PATCH", "/api/v1/orders/1", "application/merge-patch+json", `{"status":1}`, http.StatusBadRequest, []string{"request body: /status: value must be a string"}},
+		{"undeclared content type", "PATCH", "/api/v1/orders/1", "application/xml", `<order/>`, http.StatusUnsupportedMediaType, nil},
+		{"unknown method", "DELETE", "/api/v1/orders/1", "", "", http.StatusMethodNotAllowed, nil},
+		{"undocumented route", "GET", "/api/v1/undocumented", "", "", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+			if tt.errors != nil {
+				res := pkg.ErrorResponse{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.Equal(t, tt.errors, res.Errors)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/v1/orders/1", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "application/merge-patch+json", w.Header().Get("Accept-Patch"))
+}
+
+func TestOpenAPIValidationResponses(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/orders/1", nil)
+	newValidatedRouter(t, true, `{"order_id":1}`).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"order_id":1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	newValidatedRouter(t, true, `{"order_id":"1"}`).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	res := pkg.ErrorResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, []string{"response body: /order_id: value must be an integer"}, res.Errors)
+}
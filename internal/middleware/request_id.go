@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID stores the ID of each request in the request context and echoes
+// it in the response. An ID sent by the client or a proxy is kept when it
+// is printable ASCII of reasonable length; otherwise a new one is generated.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(RequestIDHeader)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		ctx.Header(RequestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(requestid.With(ctx.Request.Context(), id))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestID())
+	router.GET("/id", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, requestid.FromContext(ctx))
+	})
+
+	tests := []struct {
+		name string
+		sent string
+		kept bool
+	}{
+		{"kept from client", "abc-123", true},
+		{"generated when missing", "", false},
+		{"generated when too long", strings.Repeat("a", 129), false},
+		{"generated when not printable", "a b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/id", nil)
+			if tt.sent != "" {
+				req.Header.Set(RequestIDHeader, tt.sent)
+			}
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			assert.Equal(t, id, w.Body.String())
+			if tt.kept {
+				assert.Equal(t, tt.sent, id)
+			} else {
+				assert.Len(t, id, 32)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const TenantHeader = "X-Tenant-ID"
+
+// Tenant stores the tenant of each request in the request context, taken
+// from the credentials of the caller, or the X-Tenant-ID header or the
+// subdomain when the resolver allows it. It must run after Auth.
+func Tenant(r tenant.Resolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p, _ := auth.FromContext(ctx.Request.Context())
+		id, err := r.Resolve(p.Tenant, ctx.GetHeader(TenantHeader), ctx.Request.Host)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, tenant.ErrMismatch) || errors.Is(err, tenant.ErrUnbound) {
+				status = http.StatusForbidden
+			}
+			ctx.AbortWithStatusJSON(status, pkg.ErrorResponse{Message: err.Error()})
+			return
+		}
+		ctx.Request = ctx.Request.WithContext(tenant.With(ctx.Request.Context(), id))
+		ctx.Next()
+	}
+}
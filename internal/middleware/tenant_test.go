@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(Auth(auth.NewStaticAuthenticator(map[string]auth.Principal{
+		"k1": {Name: "alice", Tenant: "shop-a"},
+		"k2": {Name: "ops"},
+	})))
+	router.Use(Tenant(tenant.Resolver{BaseDomain: "orders.example.com", Default: tenant.Default}))
+	router.GET("/tenant", func(ctx *gin.Context) {
+		id, _ := tenant.FromContext(ctx)
+		ctx.String(http.StatusOK, id)
+	})
+
+	tests := []struct {
+		name   string
+		key    string
+		header string
+		host   string
+		status int
+		tenant string
+	}{
+		{"from credentials", "k1", "", "", http.StatusOK, "shop-a"},
+		{"credentials of another tenant", "k1", "shop-b", "", http.StatusForbidden, ""},
+		{"unbound to the default", "k2", "", "", http.StatusOK, tenant.Default},
+		{"unbound from header", "k2", "shop-b", "", http.StatusForbidden, ""},
+		{"unbound from subdomain", "k2", "", "shop-c.orders.example.com", http.StatusForbidden, ""},
+		{"invalid", "k2", "Shop B", "", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/tenant", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.tenant, w.Body.String())
+			}
+		})
+	}
+}
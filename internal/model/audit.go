@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Audit operations.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one change of an order. Entries are never updated or
+// deleted, and outlive the order they describe.
+type AuditEntry struct {
+	ID        uint64 `json:"audit_id" example:"1"`
+	OrderID   uint64 `json:"order_id" gorm:"index" example:"1"`
+	TenantID  string `json:"-" gorm:"index;not null;default:default"`
+	Operation string `json:"operation" example:"update"`
+	// Actor is the authenticated caller, or "anonymous".
+	Actor     string `json:"actor" example:"partner-a"`
+	RequestID string `json:"request_id,omitempty" example:"4f1c0a9e2b7d4e55a3c1b0f2d9e8a7c6"`
+	// Before and After are the order with its items; Before is null for a
+	// create and After for a delete.
+	Before RawJSON `json:"before" gorm:"type:jsonb" swaggertype:"object" extensions:"x-nullable"`
+	After  RawJSON `json:"after" gorm:"type:jsonb" swaggertype:"object" extensions:"x-nullable"`
+	// Changes is the JSON Merge Patch (RFC 7386) turning Before into After.
+	// Items are replaced as a whole when any of them changed.
+	Changes   RawJSON   `json:"changes" gorm:"type:jsonb" swaggertype:"object" extensions:"x-nullable"`
+	CreatedAt time.Time `json:"created_at"`
+}
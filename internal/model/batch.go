@@ -0,0 +1,49 @@
+package model
+
+import "encoding/json"
+
+// BatchCreateRequest is the body of POST /orders:batch. Mode is "atomic",
+// the default, to apply all operations or none, or "partial" to apply those
+// that succeed.
+type BatchCreateRequest struct {
+	Mode   string  `json:"mode" example:"atomic"`
+	Orders []Order `json:"orders"`
+}
+
+// BatchPatchRequest is the body of PATCH /orders:batch.
+type BatchPatchRequest struct {
+	Mode       string                `json:"mode" example:"partial"`
+	Operations []BatchPatchOperation `json:"operations"`
+}
+
+// BatchPatchOperation patches one order. Patch is a JSON Merge Patch when it
+// is an object and a JSON Patch when it is an array.
+type BatchPatchOperation struct {
+	OrderID uint64          `json:"order_id" example:"1"`
+	Patch   json.RawMessage `json:"patch" swaggertype:"object" extensions:"x-any-json"`
+}
+
+// BatchDeleteRequest is the body of DELETE /orders:batch.
+type BatchDeleteRequest struct {
+	Mode     string   `json:"mode" example:"atomic"`
+	OrderIDs []uint64 `json:"order_ids" example:"1,2"`
+}
+
+// BatchResponse reports the outcome of every operation of a batch, in
+// request order.
+type BatchResponse struct {
+	Succeeded int                    `json:"succeeded" example:"1"`
+	Failed    int                    `json:"failed" example:"0"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult is the outcome of one operation. Status is the HTTP
+// status the operation would have had as a single request.
+type BatchOperationResult struct {
+	Index   int      `json:"index" example:"0"`
+	Status  int      `json:"status" example:"201"`
+	OrderID uint64   `json:"order_id,omitempty" example:"1"`
+	Order   *Order   `json:"order,omitempty"`
+	Message string   `json:"message,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
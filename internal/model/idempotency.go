@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// IdempotencyKey remembers the response to a request sent with an
+// Idempotency-Key header, so a retry of the request gets the same response
+// instead of repeating its effect. Keys are scoped to the caller.
+type IdempotencyKey struct {
+	ID    uint64 `gorm:"primaryKey"`
+	Owner string `gorm:"uniqueIndex:idx_idempotency_keys_owner_key"`
+	Key   string `gorm:"uniqueIndex:idx_idempotency_keys_owner_key"`
+	// RequestHash identifies the method, path and body the key was first
+	// used with.
+	RequestHash string
+	// StatusCode is zero while the first request is still being served.
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	// LockedUntil is when a reservation that is still being served is
+	// presumed abandoned, for example by a server that died, and its key
+	// may be reserved again.
+	LockedUntil time.Time
+}
@@ -1,10 +1,18 @@
 package model
 
+// Item is one line of an order. Prices are in minor units of the currency;
+// Discount is the share of the promotion of the order that went to the line
+// and is computed by the service. TaxCategory picks the tax rate of the
+// line, "standard" when empty.
 type Item struct {
 	ID          uint64 `json:"item_id"`
 	ItemCode    string `json:"item_code"`
 	Description string `json:"description"`
 	Quantity    uint64 `json:"quantity"`
+	UnitPrice   uint64 `json:"unit_price" example:"1250"`
+	Discount    uint64 `json:"discount" example:"125"`
+	TaxCategory string `json:"tax_category,omitempty" example:"standard"`
 	OrderID     uint64 `json:"order_id"`
+	TenantID    string `json:"-" gorm:"index;not null;default:default"`
 	// CreatedAt    time.Time `json:"ordered_at" gorm:"column:ordered_at"`
 }
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job statuses. A job is queued until a worker claims it, and goes back to
+// queued when a failed attempt may be retried.
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Job is a long-running operation executed by a background worker. While a
+// job runs, LockedUntil is pushed forward by its worker; a job whose lock
+// expired is picked up by another worker.
+type Job struct {
+	ID          uint64     `json:"job_id" example:"1"`
+	TenantID    string     `json:"-" gorm:"index;not null;default:default"`
+	Type        string     `json:"type" example:"export"`
+	Status      string     `json:"status" gorm:"index:idx_jobs_due,priority:1" example:"queued"`
+	Params      RawJSON    `json:"params" gorm:"type:text" swaggertype:"object" extensions:"x-nullable"`
+	Progress    int        `json:"progress" example:"0"`
+	Attempts    int        `json:"attempts" example:"0"`
+	MaxAttempts int        `json:"max_attempts" example:"3"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:idx_jobs_due,priority:2"`
+	LockedBy    string     `json:"-"`
+	LockedUntil *time.Time `json:"-"`
+	Error       string     `json:"error,omitempty"`
+	Result      RawJSON    `json:"result,omitempty" gorm:"type:text" swaggertype:"object"`
+	// HasOutput tells whether a file can be downloaded from
+	// /jobs/{id}/result.
+	HasOutput  bool       `json:"has_output"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at" extensions:"x-nullable"`
+	FinishedAt *time.Time `json:"finished_at" extensions:"x-nullable"`
+}
+
+// Job file kinds.
+const (
+	JobFileInput  = "input"
+	JobFileOutput = "output"
+)
+
+// JobFile is the uploaded input or the produced output of a job. Files live
+// apart from the jobs table so polling for work does not read them. Outputs
+// are kept in a file store, named by StorageKey, rather than in Data.
+type JobFile struct {
+	ID    uint64 `gorm:"primaryKey"`
+	JobID uint64 `gorm:"uniqueIndex:idx_job_files_kind"`
+	Kind  string `gorm:"uniqueIndex:idx_job_files_kind"`
+	// Name is the file name offered on download.
+	Name        string
+	ContentType string
+	// Encoding is "gzip" when Data is compressed.
+	Encoding   string
+	Data       []byte
+	StorageKey string
+}
+
+// JobRequest queues a job. Params depend on the job type.
+type JobRequest struct {
+	Type   string          `json:"type" example:"export"`
+	Params json.RawMessage `json:"params" swaggertype:"object" extensions:"x-nullable"`
+}
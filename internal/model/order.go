@@ -4,9 +4,37 @@ import (
 	"time"
 )
 
+// Order statuses. New orders start as OrderStatusPending.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+)
+
 type Order struct {
 	ID           uint64    `json:"order_id" example:"1"`
 	CustomerName string    `json:"customer_name" example:"testing"`
+	Status       string    `json:"status" gorm:"default:pending" example:"pending"`
 	OrderedAt    time.Time `json:"ordered_at" example:"2019-11-10T04:21:46+07:00"`
-	Items        []Item    `json:"items"`
+	Items        []Item    `json:"items" extensions:"x-nullable"`
+	// PromoCode is the promotion the order was placed with. It can only be
+	// given when the order is created.
+	PromoCode   string  `json:"promo_code,omitempty" example:"SPRING10"`
+	PromotionID *uint64 `json:"-" gorm:"index"`
+	// Region is where the order ships to, which decides its taxes. Orders
+	// without one are taxed in the default region, if there is one.
+	Region string `json:"region,omitempty" example:"DE"`
+	// Subtotal, Discount, Tax and Total are computed from the items:
+	// Subtotal before the promotion, Total after it and with the taxes that
+	// are not included in the prices.
+	Subtotal uint64   `json:"subtotal" example:"2500"`
+	Discount uint64   `json:"discount" example:"250"`
+	Tax      uint64   `json:"tax" example:"359"`
+	Total    uint64   `json:"total" example:"2250"`
+	TaxLines TaxLines `json:"tax_lines" gorm:"type:text" extensions:"x-nullable"`
+	// TenantID is the storefront the order belongs to. It is set by the
+	// repositories and never leaves the API.
+	TenantID string `json:"-" gorm:"index;not null;default:default"`
 }
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// OrderFilter narrows an order listing. Zero fields do not filter.
+type OrderFilter struct {
+	CustomerName string
+	Status       string
+	// OrderedFrom and OrderedTo bound OrderedAt, inclusive and exclusive.
+	OrderedFrom time.Time
+	OrderedTo   time.Time
+	// AfterID and Limit page through a listing by ascending ID: only orders
+	// with a greater ID are returned, at most Limit of them.
+	AfterID uint64
+	Limit   int
+}
@@ -0,0 +1,24 @@
+package model
+
+// SearchHighlight is a fragment of a field that matched a search. Full-text
+// matches are wrapped in <mark> tags; fuzzy matches return the whole field.
+type SearchHighlight struct {
+	Field    string `json:"field" example:"customer_name"`
+	ItemID   uint64 `json:"item_id,omitempty" example:"0"`
+	Fragment string `json:"fragment" example:"<mark>Jonathan</mark> Smith"`
+}
+
+// OrderSearchHit is one order found by a search, best matches first.
+type OrderSearchHit struct {
+	Order      Order             `json:"order"`
+	Rank       float64           `json:"rank" example:"0.6"`
+	Highlights []SearchHighlight `json:"highlights"`
+}
+
+type OrderSearchResponse struct {
+	Query string `json:"query" example:"jon"`
+	// Fuzzy tells that nothing matched the words of the query, and the hits
+	// are orders with similarly spelled customer names or items instead.
+	Fuzzy bool             `json:"fuzzy"`
+	Hits  []OrderSearchHit `json:"hits"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// OrderSummary is a denormalized read model of an order. It is written by
+// the command side of the repository in the same transaction as the order.
+type OrderSummary struct {
+	OrderID       uint64    `json:"order_id" gorm:"primaryKey;autoIncrement:false" example:"1"`
+	TenantID      string    `json:"-" gorm:"index;not null;default:default"`
+	CustomerName  string    `json:"customer_name" example:"testing"`
+	OrderedAt     time.Time `json:"ordered_at" example:"2019-11-10T04:21:46+07:00"`
+	ItemCount     uint64    `json:"item_count" example:"2"`
+	TotalQuantity uint64    `json:"total_quantity" example:"5"`
+	UpdatedAt     time.Time `json:"updated_at" example:"2019-11-10T04:21:46+07:00"`
+}
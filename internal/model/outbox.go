@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// that produced it, waiting to be published.
+type OutboxEvent struct {
+	ID            uint64     `json:"id"`
+	AggregateID   uint64     `json:"aggregate_id" gorm:"index"`
+	TenantID      string     `json:"-" gorm:"index;not null;default:default"`
+	EventType     string     `json:"event_type"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	PublishedAt   *time.Time `json:"published_at" gorm:"index"`
+	// Sequence numbers events in the order they were published, which,
+	// unlike their IDs, is the order their transactions committed in.
+	Sequence  *uint64   `json:"sequence" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
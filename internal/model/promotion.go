@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Promotion kinds. A percentage promotion takes Value percent off the
+// eligible lines, a fixed one takes Value minor units off them.
+const (
+	PromotionPercentage = "percentage"
+	PromotionFixed      = "fixed"
+)
+
+// Promotion is a promo code customers can redeem on a new order. Codes are
+// stored upper case and are unique per tenant. An empty ItemCodes list makes
+// every line eligible; a zero MaxUses or MaxUsesPerCustomer means no limit.
+type Promotion struct {
+	ID                 uint64     `json:"promotion_id" example:"1"`
+	TenantID           string     `json:"-" gorm:"uniqueIndex:idx_promotions_code,priority:1;not null;default:default"`
+	Code               string     `json:"code" gorm:"uniqueIndex:idx_promotions_code,priority:2;not null" example:"SPRING10"`
+	Kind               string     `json:"kind" example:"percentage"`
+	Value              uint64     `json:"value" example:"10"`
+	MinOrderAmount     uint64     `json:"min_order_amount" example:"5000"`
+	ItemCodes          StringList `json:"item_codes" gorm:"type:text" swaggertype:"array,string" example:"A1,B2" extensions:"x-nullable"`
+	MaxUses            uint64     `json:"max_uses" example:"100"`
+	MaxUsesPerCustomer uint64     `json:"max_uses_per_customer" example:"1"`
+	// Uses counts the redemptions so far. It is maintained by the
+	// repository and ignored on writes.
+	Uses      uint64     `json:"uses" example:"0"`
+	StartsAt  *time.Time `json:"starts_at" extensions:"x-nullable"`
+	EndsAt    *time.Time `json:"ends_at" extensions:"x-nullable"`
+	Active    bool       `json:"active" example:"true"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// PromotionRedemption records that an order used a promotion. Discount is
+// what the code took off the order when it was placed.
+type PromotionRedemption struct {
+	ID           uint64 `gorm:"primaryKey"`
+	PromotionID  uint64 `gorm:"index:idx_promotion_redemptions_customer,priority:1;not null"`
+	TenantID     string `gorm:"index;not null;default:default"`
+	CustomerName string `gorm:"index:idx_promotion_redemptions_customer,priority:2;not null"`
+	OrderID      uint64 `gorm:"uniqueIndex;not null"`
+	Discount     uint64
+	CreatedAt    time.Time
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// RawJSON is a JSON document stored as-is in a text or jsonb column.
+type RawJSON json.RawMessage
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *RawJSON) UnmarshalJSON(b []byte) error {
+	*j = append((*j)[:0], b...)
+	return nil
+}
+
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *RawJSON) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+		return nil
+	case string:
+		*j = RawJSON(v)
+		return nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into RawJSON", src)
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Report intervals.
+const (
+	ReportDay   = "day"
+	ReportWeek  = "week"
+	ReportMonth = "month"
+)
+
+// ReportRequest selects the orders placed in [From, To). Periods are
+// calendar days, ISO weeks or months in TimeZone.
+type ReportRequest struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	TimeZone string
+	Limit    int
+}
+
+// OrderVolume counts the orders placed in one period.
+type OrderVolume struct {
+	PeriodStart time.Time `json:"period_start" example:"2024-03-04T00:00:00+07:00"`
+	Orders      uint64    `json:"orders" example:"12"`
+	Items       uint64    `json:"items" example:"30"`
+	Quantity    uint64    `json:"quantity" example:"75"`
+}
+
+type OrderVolumeReport struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Interval string    `json:"interval" example:"week"`
+	TimeZone string    `json:"time_zone" example:"Asia/Jakarta"`
+	// Periods has one entry per period in the range, including empty ones.
+	// The first and last period may be partly outside the range.
+	Periods []OrderVolume `json:"periods"`
+}
+
+// ItemVolume totals one item code over a range.
+type ItemVolume struct {
+	ItemCode string `json:"item_code" example:"SKU-1"`
+	Quantity uint64 `json:"quantity" example:"40"`
+	Orders   uint64 `json:"orders" example:"9"`
+}
+
+type TopItemsReport struct {
+	From  time.Time    `json:"from"`
+	To    time.Time    `json:"to"`
+	Items []ItemVolume `json:"items"`
+}
+
+type OrderSizeReport struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	Orders          uint64    `json:"orders" example:"12"`
+	Items           uint64    `json:"items" example:"30"`
+	Quantity        uint64    `json:"quantity" example:"75"`
+	AverageItems    float64   `json:"average_items" example:"2.5"`
+	AverageQuantity float64   `json:"average_quantity" example:"6.25"`
+}
+
+// CustomerOrders counts the orders of one customer over a range.
+type CustomerOrders struct {
+	CustomerName   string    `json:"customer_name" example:"testing"`
+	Orders         uint64    `json:"orders" example:"3"`
+	Quantity       uint64    `json:"quantity" example:"8"`
+	FirstOrderedAt time.Time `json:"first_ordered_at"`
+	LastOrderedAt  time.Time `json:"last_ordered_at"`
+}
+
+type CustomerOrdersReport struct {
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Customers []CustomerOrders `json:"customers"`
+}
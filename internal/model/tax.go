@@ -0,0 +1,51 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// TaxLine is the tax of one rate over the items of an order it applies to.
+// Amounts are in minor units; Taxable is the base the tax was computed on,
+// net of the tax and of discounts.
+type TaxLine struct {
+	Region   string `json:"region" example:"DE"`
+	Category string `json:"category" example:"standard"`
+	// Rate is a percentage.
+	Rate string `json:"rate" example:"19"`
+	// Inclusive tells that Amount is part of the item prices rather than
+	// added to them.
+	Inclusive bool   `json:"inclusive" example:"true"`
+	Taxable   uint64 `json:"taxable" example:"1891"`
+	Amount    uint64 `json:"amount" example:"359"`
+}
+
+// TaxLines are the tax lines of an order, stored as a JSON array in a text
+// column.
+type TaxLines []TaxLine
+
+func (l TaxLines) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]TaxLine(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *TaxLines) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case []byte:
+		return json.Unmarshal(v, l)
+	default:
+		return fmt.Errorf("cannot scan %T into TaxLines", src)
+	}
+}
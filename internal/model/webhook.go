@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Webhook delivery statuses.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription is a partner endpoint receiving order events. An empty
+// EventTypes list subscribes to every event type.
+type WebhookSubscription struct {
+	ID                  uint64     `json:"webhook_id" example:"1"`
+	TenantID            string     `json:"-" gorm:"index;not null;default:default"`
+	URL                 string     `json:"url" example:"https://partner.example.com/hooks/orders"`
+	EventTypes          StringList `json:"event_types" gorm:"type:text" swaggertype:"array,string" example:"order.created,order.status_changed" extensions:"x-nullable"`
+	Secret              string     `json:"secret,omitempty"`
+	Active              bool       `json:"active" example:"true"`
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"0"`
+	DisabledAt          *time.Time `json:"disabled_at" extensions:"x-nullable"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one subscription. Manual
+// redeliveries are new rows pointing at the delivery they repeat.
+type WebhookDelivery struct {
+	ID             uint64              `json:"delivery_id" example:"1"`
+	SubscriptionID uint64              `json:"webhook_id" gorm:"index" example:"1"`
+	TenantID       string              `json:"-" gorm:"index;not null;default:default"`
+	Subscription   WebhookSubscription `json:"-"`
+	EventID        uint64              `json:"event_id" example:"1"`
+	EventType      string              `json:"event_type" example:"order.created"`
+	Payload        string              `json:"-" gorm:"type:text"`
+	// DedupKey makes queueing the same event twice for a subscription a no-op.
+	DedupKey      *string                  `json:"-" gorm:"uniqueIndex"`
+	RedeliveryOf  *uint64                  `json:"redelivery_of,omitempty"`
+	Status        string                   `json:"status" gorm:"index" example:"pending"`
+	Attempts      int                      `json:"attempts" example:"0"`
+	ResponseCode  int                      `json:"response_code" example:"200"`
+	LastError     string                   `json:"last_error"`
+	NextAttemptAt time.Time                `json:"next_attempt_at"`
+	DeliveredAt   *time.Time               `json:"delivered_at" extensions:"x-nullable"`
+	CreatedAt     time.Time                `json:"created_at"`
+	AttemptLog    []WebhookDeliveryAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// WebhookDeliveryAttempt records the outcome of one HTTP request.
+type WebhookDeliveryAttempt struct {
+	ID           uint64    `json:"attempt_id"`
+	DeliveryID   uint64    `json:"delivery_id" gorm:"index"`
+	Attempt      int       `json:"attempt" example:"1"`
+	ResponseCode int       `json:"response_code" example:"500"`
+	ResponseBody string    `json:"response_body"`
+	Error        string    `json:"error"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,107 @@
+// Package openapi serves the OpenAPI 3 document of the REST API.
+//
+// The document is converted from the swagger 2 document swag generates
+// from the handler annotations into cmd/docs, so the annotations stay the
+// single description of the API. Regenerate it after changing them:
+//
+//	swag init -g cmd/main.go -o cmd/docs --parseInternal
+//
+// Swagger 2 cannot describe a value of any JSON type, so such bodies and
+// fields are annotated as objects with the x-any-json extension and
+// widened here.
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MidnightHelix/assignment-2/cmd/docs"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// BasePath is where the API described by the document is mounted.
+const BasePath = "/api/v1"
+
+// Load returns the OpenAPI 3 document of the API.
+func Load() (*openapi3.T, error) {
+	v2 := openapi2.T{}
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &v2); err != nil {
+		return nil, fmt.Errorf("openapi: read swagger document: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&v2)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: convert swagger document: %w", err)
+	}
+	// a relative server lets the document describe any host the API is
+	// reached through
+	doc.Servers = openapi3.Servers{{URL: BasePath}}
+	fixFileResponses(doc)
+	widenAnyJSON(doc)
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("openapi: invalid document: %w", err)
+	}
+	return doc, nil
+}
+
+// Handler serves doc as JSON.
+func Handler(doc *openapi3.T) (gin.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "application/json", body)
+	}, nil
+}
+
+// fixFileResponses rewrites the swagger 2 "file" responses, which the
+// converter keeps as they are, into the binary strings of OpenAPI 3.
+func fixFileResponses(doc *openapi3.T) {
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			for _, res := range op.Responses.Map() {
+				if res.Value == nil {
+					continue
+				}
+				for _, media := range res.Value.Content {
+					if s := media.Schema; s != nil && s.Value != nil && s.Value.Type.Is("file") {
+						media.Schema = openapi3.NewStringSchema().WithFormat("binary").NewRef()
+					}
+				}
+			}
+		}
+	}
+}
+
+const anyJSON = "x-any-json"
+
+// widenAnyJSON lets the request bodies and schema properties marked with
+// x-any-json hold any JSON value.
+func widenAnyJSON(doc *openapi3.T) {
+	for _, s := range doc.Components.Schemas {
+		if s.Value == nil {
+			continue
+		}
+		for name, prop := range s.Value.Properties {
+			if prop.Value != nil && prop.Value.Extensions[anyJSON] != nil {
+				s.Value.Properties[name] = &openapi3.SchemaRef{Value: &openapi3.Schema{Nullable: true}}
+			}
+		}
+	}
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			body := op.RequestBody
+			if body == nil || body.Value == nil || body.Value.Extensions[anyJSON] == nil {
+				continue
+			}
+			for _, media := range body.Value.Content {
+				media.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{}}
+			}
+		}
+	}
+}
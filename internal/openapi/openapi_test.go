@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, BasePath, doc.Servers[0].URL)
+	for _, path := range []string{"/orders", "/orders/{id}", "/orders:batch", "/jobs/{id}/result", "/graphql"} {
+		assert.NotNil(t, doc.Paths.Value(path), path)
+	}
+
+	// swagger 2 file responses become binary strings
+	res := doc.Paths.Value("/orders/export").Get.Responses.Status(http.StatusOK).Value
+	assert.Equal(t, "binary", res.Content.Get("text/csv").Schema.Value.Format)
+
+	// a patch is an object or an array
+	body := doc.Paths.Value("/orders/{id}").Patch.RequestBody.Value
+	patch := body.Content.Get("application/json-patch+json").Schema.Value
+	assert.NoError(t, patch.VisitJSON([]any{}))
+	assert.NoError(t, patch.VisitJSON(map[string]any{}))
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc, err := Load()
+	require.NoError(t, err)
+	serve, err := Handler(doc)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/openapi.json", serve)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	served := map[string]any{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	assert.Equal(t, "3.0.3", served["openapi"])
+}
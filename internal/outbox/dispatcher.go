@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/pkg"
+)
+
+// Dispatcher publishes stored outbox events at-least-once. Events of the
+// same order are published in the order they were written; a failing event
+// holds back the events queued behind it until it succeeds.
+//
+// Run a single dispatcher per database: concurrent dispatchers would still
+// deliver every event, but could reorder events of one order.
+type Dispatcher interface {
+	// Run dispatches pending events every interval until ctx is done.
+	Run(ctx context.Context)
+	// DispatchOnce publishes one batch of due events.
+	DispatchOnce(ctx context.Context) error
+}
+
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+	Backoff   pkg.Backoff
+}
+
+type dispatcherImpl struct {
+	repo      repository.OutboxRepository
+	publisher event.Publisher
+	live      event.Publisher
+	cfg       Config
+	now       func() time.Time
+}
+
+// NewDispatcher publishes events to publisher. Once an event is marked
+// published it is handed to live as well, carrying its sequence number:
+// in-process subscribers that catch up with GetSince are fed this way, so
+// that every event they miss while subscribing is already there to replay.
+func NewDispatcher(repo repository.OutboxRepository, publisher, live event.Publisher, cfg Config) Dispatcher {
+	return &dispatcherImpl{repo: repo, publisher: publisher, live: live, cfg: cfg, now: time.Now}
+}
+
+func (d *dispatcherImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox: dispatch failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *dispatcherImpl) DispatchOnce(ctx context.Context) error {
+	pending, err := d.repo.GetPending(ctx, d.now(), d.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	// orders whose earlier event failed in this batch
+	blocked := map[uint64]bool{}
+	for _, row := range pending {
+		if blocked[row.AggregateID] {
+			continue
+		}
+
+		e, err := event.FromOutbox(row)
+		if err == nil {
+			err = d.publisher.Publish(ctx, e)
+		}
+		if err != nil {
+			blocked[row.AggregateID] = true
+			next := d.now().Add(d.cfg.Backoff.Duration(row.Attempts))
+			if err := d.repo.MarkFailed(ctx, row.ID, err.Error(), next); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if e.Sequence, err = d.repo.MarkPublished(ctx, row.ID, d.now()); err != nil {
+			return err
+		}
+		if err := d.live.Publish(ctx, e); err != nil {
+			log.Printf("outbox: live publish of event %d failed: %v", e.ID, err)
+		}
+	}
+	return nil
+}
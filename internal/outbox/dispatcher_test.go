@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyPublisher fails every event of failOrder until healed.
+type flakyPublisher struct {
+	*event.MemoryPublisher
+	failOrder uint64
+	healed    bool
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, e event.Event) error {
+	if e.OrderID == p.failOrder && !p.healed {
+		return errors.New("downstream unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, e)
+}
+
+func TestDispatchOnce(t *testing.T) {
+	cfg := Config{BatchSize: 10, Backoff: pkg.Backoff{Initial: time.Minute, Max: time.Minute}}
+
+	t.Run("publishes pending events", func(t *testing.T) {
+		repo := repository.NewMemoryOutboxRepository(repository.NewMemoryStore())
+		repo.Append(context.Background(),
+			event.New(event.OrderCreated, model.Order{ID: 1}),
+			event.New(event.OrderUpdated, model.Order{ID: 1}),
+		)
+		pub := event.NewMemoryPublisher()
+		live := event.NewMemoryPublisher()
+
+		d := NewDispatcher(repo, pub, live, cfg)
+		assert.Nil(t, d.DispatchOnce(context.Background()))
+
+		events := pub.Events()
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, uint64(1), events[0].ID)
+		assert.Equal(t, event.OrderCreated, events[0].Type)
+		assert.Equal(t, event.OrderUpdated, events[1].Type)
+
+		// live subscribers hear of events once they can be replayed
+		events = live.Events()
+		assert.Equal(t, 2, len(events))
+		assert.Equal(t, uint64(1), events[0].Sequence)
+		assert.Equal(t, uint64(2), events[1].Sequence)
+		stored, err := repo.GetSince(context.Background(), 1, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(stored))
+		assert.Equal(t, uint64(2), stored[0].ID)
+
+		// already published events are not sent again
+		assert.Nil(t, d.DispatchOnce(context.Background()))
+		assert.Equal(t, 2, len(pub.Events()))
+	})
+
+	t.Run("failure holds back later events of the same order", func(t *testing.T) {
+		repo := repository.NewMemoryOutboxRepository(repository.NewMemoryStore())
+		repo.Append(context.Background(),
+			event.New(event.OrderCreated, model.Order{ID: 1}),
+			event.New(event.OrderCreated, model.Order{ID: 2}),
+			event.New(event.OrderDeleted, model.Order{ID: 1}),
+		)
+		pub := &flakyPublisher{MemoryPublisher: event.NewMemoryPublisher(), failOrder: 1}
+		live := event.NewMemoryPublisher()
+
+		now := time.Now()
+		d := &dispatcherImpl{repo: repo, publisher: pub, live: live, cfg: cfg, now: func() time.Time { return now }}
+		assert.Nil(t, d.DispatchOnce(context.Background()))
+
+		events := pub.Events()
+		assert.Equal(t, 1, len(events))
+		assert.Equal(t, uint64(2), events[0].OrderID)
+		failed, err := repo.GetPending(context.Background(), now.Add(time.Hour), 1)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, failed[0].Attempts)
+		assert.Equal(t, "downstream unavailable", failed[0].LastError)
+
+		// not due yet
+		pub.healed = true
+		assert.Nil(t, d.DispatchOnce(context.Background()))
+		assert.Equal(t, 1, len(pub.Events()))
+
+		// retried after backoff, still in order
+		now = now.Add(2 * time.Minute)
+		assert.Nil(t, d.DispatchOnce(context.Background()))
+		events = pub.Events()
+		assert.Equal(t, 3, len(events))
+		assert.Equal(t, event.OrderCreated, events[1].Type)
+		assert.Equal(t, event.OrderDeleted, events[2].Type)
+
+		// replay follows the order events were published in, not their IDs
+		stored, err := repo.GetSince(context.Background(), 0, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(stored))
+		assert.Equal(t, []uint64{2, 1, 3}, []uint64{stored[0].ID, stored[1].ID, stored[2].ID})
+		assert.Equal(t, 3, len(live.Events()))
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// AuditRepository stores the audit trail of orders. It only ever inserts;
+// the database rejects changes to existing entries.
+type AuditRepository interface {
+	// Append stores entries, joining the transaction in ctx so they are
+	// written only if the change they describe is.
+	Append(ctx context.Context, entries ...model.AuditEntry) error
+	// GetOrderHistory returns the entries of an order, oldest first.
+	GetOrderHistory(ctx context.Context, orderID uint64) ([]model.AuditEntry, error)
+}
+
+type auditRepositoryImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewAuditRepository(db infrastructure.GormDB) AuditRepository {
+	return &auditRepositoryImpl{db: db}
+}
+
+func (u *auditRepositoryImpl) Append(ctx context.Context, entries ...model.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	db := conn(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Table("audit_entries").
+		Create(&entries).Error
+}
+
+func (u *auditRepositoryImpl) GetOrderHistory(ctx context.Context, orderID uint64) ([]model.AuditEntry, error) {
+	db := conn(ctx, u.db)
+	entries := []model.AuditEntry{}
+	if err := db.
+		WithContext(ctx).
+		Table("audit_entries").
+		Where("order_id = ?", orderID).
+		Order("id").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+type memoryAuditRepositoryImpl struct {
+	store *MemoryStore
+}
+
+func NewMemoryAuditRepository(store *MemoryStore) AuditRepository {
+	return &memoryAuditRepositoryImpl{store: store}
+}
+
+func (u *memoryAuditRepositoryImpl) Append(ctx context.Context, entries ...model.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return u.store.write(ctx, func(d *memoryData) error {
+		for _, entry := range entries {
+			entry.ID = d.nextID("audit_entries")
+			entry.TenantID = tenantToStamp(ctx)
+			d.appendAudit(entry)
+		}
+		return nil
+	})
+}
+
+func (u *memoryAuditRepositoryImpl) GetOrderHistory(ctx context.Context, orderID uint64) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		for _, entry := range d.audit {
+			if entry.OrderID == orderID && visible(ctx, entry.TenantID) {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAudit(t *testing.T) {
+	t.Run("error append entries", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "audit_entries"`)).WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		auditRepo := auditRepositoryImpl{db: postgresMock}
+		err := auditRepo.Append(context.Background(), model.AuditEntry{OrderID: 1, Operation: model.AuditCreate})
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success append entries", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "audit_entries" ("order_id","tenant_id","operation","actor","request_id","before","after","changes","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9),($10,$11,$12,$13,$14,$15,$16,$17,$18) RETURNING "id"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectCommit()
+
+		auditRepo := auditRepositoryImpl{db: postgresMock}
+		err := auditRepo.Append(context.Background(),
+			model.AuditEntry{OrderID: 1, Operation: model.AuditCreate, After: model.RawJSON(`{"order_id":1}`)},
+			model.AuditEntry{OrderID: 2, Operation: model.AuditCreate, After: model.RawJSON(`{"order_id":2}`)},
+		)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to append", func(t *testing.T) {
+		auditRepo := auditRepositoryImpl{}
+		assert.Nil(t, auditRepo.Append(context.Background()))
+	})
+}
+
+func TestGetOrderHistory(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_entries" WHERE order_id = $1 ORDER BY id`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "operation", "changes"}).
+			AddRow(1, 1, "create", `{"order_id":1}`).
+			AddRow(2, 1, "update", `{"status":"paid"}`))
+
+	auditRepo := auditRepositoryImpl{db: postgresMock}
+	entries, err := auditRepo.GetOrderHistory(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, `{"status":"paid"}`, string(entries[1].Changes))
+}
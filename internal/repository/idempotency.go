@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository stores the responses of requests sent with an
+// Idempotency-Key.
+type IdempotencyRepository interface {
+	// Reserve claims key for a request. When the key is already taken it
+	// returns the stored record and false instead. Records created before
+	// expiredBefore, and reservations whose lock expired by key.CreatedAt,
+	// are discarded first, freeing their keys.
+	Reserve(ctx context.Context, key model.IdempotencyKey, expiredBefore time.Time) (model.IdempotencyKey, bool, error)
+	// Complete stores the response of a reserved key.
+	Complete(ctx context.Context, id uint64, statusCode int, contentType string, body []byte) error
+	// Release frees a reserved key whose request failed, so it can be retried.
+	Release(ctx context.Context, id uint64) error
+	// DeleteExpired discards the records Reserve would discard at now and
+	// returns how many there were.
+	DeleteExpired(ctx context.Context, expiredBefore, now time.Time) (int64, error)
+}
+
+type idempotencyRepositoryImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewIdempotencyRepository(db infrastructure.GormDB) IdempotencyRepository {
+	return &idempotencyRepositoryImpl{db: db}
+}
+
+func (u *idempotencyRepositoryImpl) Reserve(ctx context.Context, key model.IdempotencyKey, expiredBefore time.Time) (model.IdempotencyKey, bool, error) {
+	db := conn(ctx, u.db).WithContext(ctx)
+	if err := expired(db.Where("owner = ? AND key = ?", key.Owner, key.Key), expiredBefore, key.CreatedAt).
+		Delete(&model.IdempotencyKey{}).Error; err != nil {
+		return model.IdempotencyKey{}, false, err
+	}
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+	if res.Error != nil {
+		return model.IdempotencyKey{}, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return key, true, nil
+	}
+	existing := model.IdempotencyKey{}
+	if err := db.
+		Where("owner = ? AND key = ?", key.Owner, key.Key).
+		Take(&existing).Error; err != nil {
+		return model.IdempotencyKey{}, false, err
+	}
+	return existing, false, nil
+}
+
+func (u *idempotencyRepositoryImpl) Complete(ctx context.Context, id uint64, statusCode int, contentType string, body []byte) error {
+	db := conn(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Model(&model.IdempotencyKey{ID: id}).
+		Updates(map[string]any{"status_code": statusCode, "content_type": contentType, "body": body}).Error
+}
+
+func (u *idempotencyRepositoryImpl) Release(ctx context.Context, id uint64) error {
+	db := conn(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Delete(&model.IdempotencyKey{ID: id}).Error
+}
+
+func (u *idempotencyRepositoryImpl) DeleteExpired(ctx context.Context, expiredBefore, now time.Time) (int64, error) {
+	db := conn(ctx, u.db)
+	res := expired(db.WithContext(ctx), expiredBefore, now).Delete(&model.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
+
+// expired selects the records that are past their TTL, and the reservations
+// whose lock expired.
+func expired(db *gorm.DB, expiredBefore, now time.Time) *gorm.DB {
+	return db.Where("created_at < ? OR (status_code = 0 AND locked_until < ?)", expiredBefore, now)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	now := time.Now()
+	expiredBefore := now.Add(-time.Hour)
+	key := model.IdempotencyKey{Owner: "alice", Key: "k1", RequestHash: "h", CreatedAt: now, LockedUntil: now.Add(time.Minute)}
+
+	t.Run("frees an expired key and reserves it", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_keys" WHERE (owner = $1 AND key = $2) AND (created_at < $3 OR (status_code = 0 AND locked_until < $4))`)).
+			WithArgs("alice", "k1", expiredBefore, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "idempotency_keys"`) + `.*` + regexp.QuoteMeta(`ON CONFLICT DO NOTHING`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		idempotencyRepo := idempotencyRepositoryImpl{db: postgresMock}
+		rec, created, err := idempotencyRepo.Reserve(context.Background(), key, expiredBefore)
+		assert.Nil(t, err)
+		assert.True(t, created)
+		assert.Equal(t, uint64(1), rec.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns the record holding the key", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_keys"`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "idempotency_keys"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_keys" WHERE owner = $1 AND key = $2 LIMIT $3`)).
+			WithArgs("alice", "k1", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "owner", "key", "request_hash", "status_code"}).AddRow(7, "alice", "k1", "h", 0))
+
+		idempotencyRepo := idempotencyRepositoryImpl{db: postgresMock}
+		rec, created, err := idempotencyRepo.Reserve(context.Background(), key, expiredBefore)
+		assert.Nil(t, err)
+		assert.False(t, created)
+		assert.Equal(t, uint64(7), rec.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	now := time.Now()
+	expiredBefore := now.Add(-time.Hour)
+
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "idempotency_keys" WHERE created_at < $1 OR (status_code = 0 AND locked_until < $2)`)).
+		WithArgs(expiredBefore, now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	idempotencyRepo := idempotencyRepositoryImpl{db: postgresMock}
+	n, err := idempotencyRepo.DeleteExpired(context.Background(), expiredBefore, now)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
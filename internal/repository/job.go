@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository interface {
+	// CreateJob queues job together with its input file, when there is one.
+	CreateJob(ctx context.Context, job model.Job, input *model.JobFile) (model.Job, error)
+	GetJobByID(ctx context.Context, id uint64) (model.Job, error)
+	// GetJobFile returns the file of the given kind, or a zero JobFile when
+	// the job has none.
+	GetJobFile(ctx context.Context, jobID uint64, kind string) (model.JobFile, error)
+
+	// ClaimJob takes the oldest job that is due at now, or whose worker let
+	// its lock expire, and locks it for worker until lockedUntil. Workers
+	// claiming at the same time skip each other's rows. It returns a zero Job
+	// when there is no work.
+	ClaimJob(ctx context.Context, worker string, now, lockedUntil time.Time) (model.Job, error)
+	// ExtendJob stores the progress of a running job and pushes its lock
+	// forward. It returns false when worker no longer holds the lock.
+	ExtendJob(ctx context.Context, id uint64, worker string, progress int, lockedUntil time.Time) (bool, error)
+	// FinishJob saves the outcome of an attempt, along with the output file
+	// when there is one, and releases the lock. It returns false when worker
+	// no longer holds the lock, in which case nothing is saved.
+	FinishJob(ctx context.Context, job model.Job, worker string, output *model.JobFile) (bool, error)
+	// FailExpiredJobs fails running jobs whose lock expired at now and that
+	// have no attempts left.
+	FailExpiredJobs(ctx context.Context, now time.Time) error
+}
+
+type jobRepositoryImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewJobRepository(db infrastructure.GormDB) JobRepository {
+	return &jobRepositoryImpl{db: db}
+}
+
+func (u *jobRepositoryImpl) CreateJob(ctx context.Context, job model.Job, input *model.JobFile) (model.Job, error) {
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Table("jobs").
+				Create(&job).Error; err != nil {
+				return err
+			}
+			if input == nil {
+				return nil
+			}
+			input.JobID = job.ID
+			input.Kind = model.JobFileInput
+			return tx.
+				Table("job_files").
+				Create(input).Error
+		}); err != nil {
+		return model.Job{}, err
+	}
+	return job, nil
+}
+
+func (u *jobRepositoryImpl) GetJobByID(ctx context.Context, id uint64) (model.Job, error) {
+	db := conn(ctx, u.db)
+	job := model.Job{}
+	if err := db.
+		WithContext(ctx).
+		Table("jobs").
+		Where("id = ?", id).
+		Find(&job).Error; err != nil {
+		return model.Job{}, err
+	}
+	return job, nil
+}
+
+func (u *jobRepositoryImpl) GetJobFile(ctx context.Context, jobID uint64, kind string) (model.JobFile, error) {
+	db := conn(ctx, u.db)
+	file := model.JobFile{}
+	if err := db.
+		WithContext(ctx).
+		Table("job_files").
+		Where("job_id = ? AND kind = ?", jobID, kind).
+		Find(&file).Error; err != nil {
+		return model.JobFile{}, err
+	}
+	return file, nil
+}
+
+func (u *jobRepositoryImpl) ClaimJob(ctx context.Context, worker string, now, lockedUntil time.Time) (model.Job, error) {
+	db := conn(ctx, u.db)
+	job := model.Job{}
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Table("jobs").
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ? AND attempts < max_attempts)",
+					model.JobStatusQueued, now, model.JobStatusRunning, now).
+				Order("run_at, id").
+				Limit(1).
+				Find(&job).Error; err != nil {
+				return err
+			}
+			if job.ID == 0 {
+				return nil
+			}
+
+			job.Status = model.JobStatusRunning
+			job.Attempts++
+			job.LockedBy = worker
+			job.LockedUntil = &lockedUntil
+			if job.StartedAt == nil {
+				job.StartedAt = &now
+			}
+			return tx.
+				Table("jobs").
+				Select("status", "attempts", "locked_by", "locked_until", "started_at").
+				Updates(&job).Error
+		}); err != nil {
+		return model.Job{}, err
+	}
+	return job, nil
+}
+
+func (u *jobRepositoryImpl) ExtendJob(ctx context.Context, id uint64, worker string, progress int, lockedUntil time.Time) (bool, error) {
+	db := conn(ctx, u.db)
+	res := db.
+		WithContext(ctx).
+		Table("jobs").
+		Where("id = ? AND status = ? AND locked_by = ?", id, model.JobStatusRunning, worker).
+		Updates(map[string]any{"progress": progress, "locked_until": lockedUntil})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (u *jobRepositoryImpl) FinishJob(ctx context.Context, job model.Job, worker string, output *model.JobFile) (bool, error) {
+	db := conn(ctx, u.db)
+	held := false
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			job.LockedBy = ""
+			job.LockedUntil = nil
+			job.HasOutput = output != nil
+			res := tx.
+				Table("jobs").
+				Where("status = ? AND locked_by = ?", model.JobStatusRunning, worker).
+				Select("status", "progress", "run_at", "locked_by", "locked_until", "error", "result", "has_output", "finished_at").
+				Updates(&job)
+			if res.Error != nil || res.RowsAffected != 1 {
+				return res.Error
+			}
+			held = true
+			if output == nil {
+				return nil
+			}
+			output.JobID = job.ID
+			output.Kind = model.JobFileOutput
+			return tx.
+				Table("job_files").
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "job_id"}, {Name: "kind"}},
+					DoUpdates: clause.AssignmentColumns([]string{"name", "content_type", "encoding", "data", "storage_key"}),
+				}).
+				Create(output).Error
+		}); err != nil {
+		return false, err
+	}
+	return held, nil
+}
+
+func (u *jobRepositoryImpl) FailExpiredJobs(ctx context.Context, now time.Time) error {
+	db := conn(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Table("jobs").
+		Where("status = ? AND locked_until < ? AND attempts >= max_attempts", model.JobStatusRunning, now).
+		Updates(map[string]any{
+			"status":       model.JobStatusFailed,
+			"error":        "the worker stopped responding and no attempts are left",
+			"locked_by":    "",
+			"locked_until": nil,
+			"finished_at":  now,
+		}).Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimJob(t *testing.T) {
+	t.Run("no job due", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "jobs" WHERE (status = $1 AND run_at <= $2) OR (status = $3 AND locked_until < $4 AND attempts < max_attempts) ORDER BY run_at, id LIMIT $5 FOR UPDATE SKIP LOCKED
+		`)).WithArgs(model.JobStatusQueued, now, model.JobStatusRunning, now, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		jobRepo := jobRepositoryImpl{db: postgresMock}
+		job, err := jobRepo.ClaimJob(context.Background(), "w1", now, now.Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), job.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success claim job", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		now := time.Now()
+		until := now.Add(time.Minute)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "jobs"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "type", "status", "attempts", "max_attempts"}).
+				AddRow(7, "export", model.JobStatusQueued, 0, 3))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "jobs" SET "status"=$1,"attempts"=$2,"locked_by"=$3,"locked_until"=$4,"started_at"=$5 WHERE "id" = $6
+		`)).WithArgs(model.JobStatusRunning, 1, "w1", until, now, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		jobRepo := jobRepositoryImpl{db: postgresMock}
+		job, err := jobRepo.ClaimJob(context.Background(), "w1", now, until)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(7), job.ID)
+		assert.Equal(t, model.JobStatusRunning, job.Status)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFinishJob(t *testing.T) {
+	t.Run("lock lost", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "jobs" SET "status"=$1,"progress"=$2,"run_at"=$3,"locked_by"=$4,"locked_until"=$5,"error"=$6,"result"=$7,"has_output"=$8,"finished_at"=$9 WHERE (status = $10 AND locked_by = $11) AND "id" = $12
+		`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		jobRepo := jobRepositoryImpl{db: postgresMock}
+		held, err := jobRepo.FinishJob(context.Background(), model.Job{ID: 7, Status: model.JobStatusSucceeded}, "w1", &model.JobFile{Data: []byte("x")})
+		assert.Nil(t, err)
+		assert.False(t, held)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success finish job with output", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "jobs" SET`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "job_files" ("job_id","kind","name","content_type","encoding","data","storage_key") VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT ("job_id","kind") DO UPDATE SET "name"="excluded"."name","content_type"="excluded"."content_type","encoding"="excluded"."encoding","data"="excluded"."data","storage_key"="excluded"."storage_key" RETURNING "id"
+		`)).WithArgs(7, model.JobFileOutput, "orders-7.csv", "text/csv", "gzip", sqlmock.AnyArg(), "file-1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		jobRepo := jobRepositoryImpl{db: postgresMock}
+		held, err := jobRepo.FinishJob(context.Background(), model.Job{ID: 7, Status: model.JobStatusSucceeded}, "w1",
+			&model.JobFile{Name: "orders-7.csv", ContentType: "text/csv", Encoding: "gzip", StorageKey: "file-1"})
+		assert.Nil(t, err)
+		assert.True(t, held)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
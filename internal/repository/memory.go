@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// MemoryStore keeps orders, their outbox events and audit entries in memory,
+// for demos and tests that do not need Postgres. The repositories built on
+// it behave like their GORM counterparts and are safe for concurrent use.
+// Transactions run one at a time and roll back by undoing their writes.
+// Rows are kept to the tenant of the context like the tenant scope of the
+// database does. Unlike the database, the store forgets old events: it keeps
+// the latest memoryOutboxRetention published outbox events for replay. Audit
+// entries are all kept, as the audit log is append-only.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data memoryData
+}
+
+const memoryOutboxRetention = 1000
+
+// memoryData holds the tables. Orders are stored without their items, which
+// live in items. Writes go through the methods that record how to undo them.
+type memoryData struct {
+	orders    map[uint64]model.Order
+	items     map[uint64]model.Item
+	summaries map[uint64]model.OrderSummary
+	// outbox and audit are ordered by ID.
+	outbox []model.OutboxEvent
+	audit  []model.AuditEntry
+	// published counts the published events of outbox.
+	published int
+	// lastID is the last ID handed out per table.
+	lastID map[string]uint64
+	// undo reverts the writes of the running write or transaction, the
+	// latest last.
+	undo []func()
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: memoryData{
+		orders:    map[uint64]model.Order{},
+		items:     map[uint64]model.Item{},
+		summaries: map[uint64]model.OrderSummary{},
+		lastID:    map[string]uint64{},
+	}}
+}
+
+func (d *memoryData) nextID(table string) uint64 {
+	d.setLastID(table, d.lastID[table]+1)
+	return d.lastID[table]
+}
+
+// useID keeps the sequence of table ahead of an ID chosen by the caller.
+func (d *memoryData) useID(table string, id uint64) {
+	if id > d.lastID[table] {
+		d.setLastID(table, id)
+	}
+}
+
+func (d *memoryData) setLastID(table string, id uint64) {
+	old := d.lastID[table]
+	d.lastID[table] = id
+	d.undo = append(d.undo, func() { d.lastID[table] = old })
+}
+
+// put stores a row of table.
+func put[V any](d *memoryData, table map[uint64]V, id uint64, row V) {
+	old, ok := table[id]
+	table[id] = row
+	d.undo = append(d.undo, func() {
+		if ok {
+			table[id] = old
+		} else {
+			delete(table, id)
+		}
+	})
+}
+
+// remove deletes a row of table.
+func remove[V any](d *memoryData, table map[uint64]V, id uint64) {
+	old, ok := table[id]
+	if !ok {
+		return
+	}
+	delete(table, id)
+	d.undo = append(d.undo, func() { table[id] = old })
+}
+
+// appendEvent adds an event to the outbox.
+func (d *memoryData) appendEvent(e model.OutboxEvent) {
+	n := len(d.outbox)
+	d.outbox = append(d.outbox, e)
+	d.undo = append(d.undo, func() { d.outbox = d.outbox[:n] })
+}
+
+// updateEvent applies fn to the outbox event with id, if any, and forgets
+// the oldest published events once more than memoryOutboxRetention are kept.
+func (d *memoryData) updateEvent(id uint64, fn func(e *model.OutboxEvent)) {
+	i := sort.Search(len(d.outbox), func(i int) bool { return d.outbox[i].ID >= id })
+	if i == len(d.outbox) || d.outbox[i].ID != id {
+		return
+	}
+	old, published := d.outbox[i], d.published
+	fn(&d.outbox[i])
+	if old.PublishedAt == nil && d.outbox[i].PublishedAt != nil {
+		d.published++
+	}
+	d.undo = append(d.undo, func() {
+		// the event is where it was: later trims are undone first
+		d.outbox[i], d.published = old, published
+	})
+	// trim in batches so the cost is spread over many writes
+	if d.published > memoryOutboxRetention+memoryOutboxRetention/4 {
+		d.trimOutbox()
+	}
+}
+
+// trimOutbox drops published events beyond the latest
+// memoryOutboxRetention, keeping those still pending.
+func (d *memoryData) trimOutbox() {
+	sequences := []uint64{}
+	for _, e := range d.outbox {
+		if e.Sequence != nil {
+			sequences = append(sequences, *e.Sequence)
+		}
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+	oldest := sequences[len(sequences)-memoryOutboxRetention]
+	kept := make([]model.OutboxEvent, 0, len(d.outbox)-len(sequences)+memoryOutboxRetention)
+	for _, e := range d.outbox {
+		if e.Sequence == nil || *e.Sequence >= oldest {
+			kept = append(kept, e)
+		}
+	}
+	old, published := d.outbox, d.published
+	d.outbox, d.published = kept, memoryOutboxRetention
+	d.undo = append(d.undo, func() { d.outbox, d.published = old, published })
+}
+
+// appendAudit adds an audit entry.
+func (d *memoryData) appendAudit(entry model.AuditEntry) {
+	old := d.audit
+	d.audit = append(d.audit, entry)
+	d.undo = append(d.undo, func() { d.audit = old })
+}
+
+// rollback undoes the writes recorded after the first mark of them.
+func (d *memoryData) rollback(mark int) {
+	for i := len(d.undo) - 1; i >= mark; i-- {
+		d.undo[i]()
+	}
+	d.undo = d.undo[:mark]
+}
+
+// orderItems returns the items of an order by ascending ID.
+func (d *memoryData) orderItems(orderID uint64) []model.Item {
+	items := []model.Item{}
+	for _, item := range d.items {
+		if item.OrderID == orderID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// refreshSummary recomputes the read model of an order, like its SQL
+// counterpart.
+func (d *memoryData) refreshSummary(orderID uint64) {
+	order, ok := d.orders[orderID]
+	if !ok {
+		remove(d, d.summaries, orderID)
+		return
+	}
+	summary := model.OrderSummary{
+		OrderID:      order.ID,
+		TenantID:     order.TenantID,
+		CustomerName: order.CustomerName,
+		OrderedAt:    order.OrderedAt,
+		UpdatedAt:    time.Now(),
+	}
+	for _, item := range d.orderItems(orderID) {
+		summary.ItemCount++
+		summary.TotalQuantity += item.Quantity
+	}
+	put(d, d.summaries, orderID, summary)
+}
+
+// visible tells whether a row of tenantID may be seen with ctx. A context
+// without a tenant sees every row.
+func visible(ctx context.Context, tenantID string) bool {
+	id := tenantOf(ctx)
+	return id == "" || id == tenantID
+}
+
+// tenantToStamp returns the tenant of the rows written with ctx, the column
+// default when it has none.
+func tenantToStamp(ctx context.Context) string {
+	if id := tenantOf(ctx); id != "" {
+		return id
+	}
+	return tenant.Default
+}
+
+type memoryTxKey struct{}
+
+// read runs fn with the tables, sharing them with other readers unless ctx
+// belongs to a transaction of s, which already holds them.
+func (s *MemoryStore) read(ctx context.Context, fn func(d *memoryData) error) error {
+	if ctx.Value(memoryTxKey{}) == s {
+		return fn(&s.data)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(&s.data)
+}
+
+// write runs fn with the tables held exclusively. A failed write outside a
+// transaction leaves no trace.
+func (s *MemoryStore) write(ctx context.Context, fn func(d *memoryData) error) error {
+	if ctx.Value(memoryTxKey{}) == s {
+		return fn(&s.data)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rollbackOnError(true, func() error { return fn(&s.data) })
+}
+
+// rollbackOnError undoes the writes of fn when it fails or panics. The
+// caller holds the lock. Once the outermost write or transaction succeeds
+// there is nothing left to undo; a savepoint keeps its writes undoable by
+// the transaction around it.
+func (s *MemoryStore) rollbackOnError(outermost bool, fn func() error) (err error) {
+	mark := len(s.data.undo)
+	defer func() {
+		p := recover()
+		switch {
+		case p != nil || err != nil:
+			s.data.rollback(mark)
+		case outermost:
+			s.data.undo = nil
+		}
+		if p != nil {
+			panic(p)
+		}
+	}()
+	return fn()
+}
+
+type memoryTxManagerImpl struct {
+	store *MemoryStore
+}
+
+func NewMemoryTxManager(store *MemoryStore) TxManager {
+	return &memoryTxManagerImpl{store: store}
+}
+
+func (t *memoryTxManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTxKey{}) == t.store {
+		// a savepoint
+		return t.store.rollbackOnError(false, func() error { return fn(ctx) })
+	}
+	hooks := &commitHooks{}
+	ctx = context.WithValue(ctx, commitHooksKey{}, hooks)
+	t.store.mu.Lock()
+	err := func() error {
+		defer t.store.mu.Unlock()
+		return t.store.rollbackOnError(true, func() error {
+			return fn(context.WithValue(ctx, memoryTxKey{}, t.store))
+		})
+	}()
+	if err != nil {
+		return err
+	}
+	for _, fn := range hooks.fns {
+		fn()
+	}
+	return nil
+}
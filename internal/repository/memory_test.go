@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySavepoint(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	command, query, tx := NewMemoryOrderCommand(store), NewMemoryOrderQuery(store), NewMemoryTxManager(store)
+	failed := errors.New("failed")
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := command.CreateOrder(ctx, model.Order{CustomerName: "alice"}); err != nil {
+			return err
+		}
+		// the failed savepoint leaves the writes before it
+		err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := command.CreateOrder(ctx, model.Order{CustomerName: "bob"}); err != nil {
+				return err
+			}
+			return failed
+		})
+		assert.ErrorIs(t, err, failed)
+		return nil
+	})
+	require.NoError(t, err)
+	orders, err := query.GetOrders(ctx, model.OrderFilter{})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, "alice", orders[0].CustomerName)
+
+	// a savepoint that succeeded is undone with its transaction
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := command.CreateOrder(ctx, model.Order{CustomerName: "carol"})
+			return err
+		}); err != nil {
+			return err
+		}
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	orders, err = query.GetOrders(ctx, model.OrderFilter{})
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Empty(t, store.data.undo)
+
+	// IDs handed out by the rolled back writes are handed out again
+	order, err := command.CreateOrder(ctx, model.Order{CustomerName: "dave"})
+	require.NoError(t, err)
+	assert.Equal(t, orders[0].ID+1, order.ID)
+}
+
+func TestMemoryOutboxRetention(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	outbox := NewMemoryOutboxRepository(store)
+	now := time.Now()
+
+	published := memoryOutboxRetention + memoryOutboxRetention/4 + 1
+	for i := 0; i <= published; i++ {
+		require.NoError(t, outbox.Append(ctx, event.New(event.OrderCreated, model.Order{ID: uint64(i + 1)})))
+	}
+	for id := uint64(1); id <= uint64(published); id++ {
+		_, err := outbox.MarkPublished(ctx, id, now)
+		require.NoError(t, err)
+	}
+
+	// the oldest published events are forgotten, the pending one is kept
+	events, err := outbox.GetSince(ctx, 0, 2*memoryOutboxRetention)
+	require.NoError(t, err)
+	require.Len(t, events, memoryOutboxRetention)
+	assert.Equal(t, uint64(published-memoryOutboxRetention+1), *events[0].Sequence)
+	pending, err := outbox.GetPending(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, uint64(published+1), pending[0].ID)
+}
+
+func TestMemoryAuditKeepsAllEntries(t *testing.T) {
+	ctx := context.Background()
+	audit := NewMemoryAuditRepository(NewMemoryStore())
+
+	const appended = 20000
+	for i := 0; i < appended; i++ {
+		require.NoError(t, audit.Append(ctx, model.AuditEntry{OrderID: 1, Operation: model.AuditUpdate}))
+	}
+	history, err := audit.GetOrderHistory(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, history, appended)
+	assert.Equal(t, uint64(1), history[0].ID)
+}
@@ -0,0 +1,130 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IdempotencyRepository is an autogenerated mock type for the IdempotencyRepository type
+type IdempotencyRepository struct {
+	mock.Mock
+}
+
+// Complete provides a mock function with given fields: ctx, id, statusCode, contentType, body
+func (_m *IdempotencyRepository) Complete(ctx context.Context, id uint64, statusCode int, contentType string, body []byte) error {
+	ret := _m.Called(ctx, id, statusCode, contentType, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int, string, []byte) error); ok {
+		r0 = rf(ctx, id, statusCode, contentType, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpired provides a mock function with given fields: ctx, expiredBefore, now
+func (_m *IdempotencyRepository) DeleteExpired(ctx context.Context, expiredBefore time.Time, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, expiredBefore, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) (int64, error)); ok {
+		return rf(ctx, expiredBefore, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) int64); ok {
+		r0 = rf(ctx, expiredBefore, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, expiredBefore, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, id
+func (_m *IdempotencyRepository) Release(ctx context.Context, id uint64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, key, expiredBefore
+func (_m *IdempotencyRepository) Reserve(ctx context.Context, key model.IdempotencyKey, expiredBefore time.Time) (model.IdempotencyKey, bool, error) {
+	ret := _m.Called(ctx, key, expiredBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserve")
+	}
+
+	var r0 model.IdempotencyKey
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.IdempotencyKey, time.Time) (model.IdempotencyKey, bool, error)); ok {
+		return rf(ctx, key, expiredBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.IdempotencyKey, time.Time) model.IdempotencyKey); ok {
+		r0 = rf(ctx, key, expiredBefore)
+	} else {
+		r0 = ret.Get(0).(model.IdempotencyKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.IdempotencyKey, time.Time) bool); ok {
+		r1 = rf(ctx, key, expiredBefore)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.IdempotencyKey, time.Time) error); ok {
+		r2 = rf(ctx, key, expiredBefore)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyRepository {
+	mock := &IdempotencyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
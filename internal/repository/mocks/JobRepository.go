@@ -0,0 +1,217 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// JobRepository is an autogenerated mock type for the JobRepository type
+type JobRepository struct {
+	mock.Mock
+}
+
+// ClaimJob provides a mock function with given fields: ctx, worker, now, lockedUntil
+func (_m *JobRepository) ClaimJob(ctx context.Context, worker string, now time.Time, lockedUntil time.Time) (model.Job, error) {
+	ret := _m.Called(ctx, worker, now, lockedUntil)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimJob")
+	}
+
+	var r0 model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (model.Job, error)); ok {
+		return rf(ctx, worker, now, lockedUntil)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) model.Job); ok {
+		r0 = rf(ctx, worker, now, lockedUntil)
+	} else {
+		r0 = ret.Get(0).(model.Job)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, worker, now, lockedUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateJob provides a mock function with given fields: ctx, job, input
+func (_m *JobRepository) CreateJob(ctx context.Context, job model.Job, input *model.JobFile) (model.Job, error) {
+	ret := _m.Called(ctx, job, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateJob")
+	}
+
+	var r0 model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Job, *model.JobFile) (model.Job, error)); ok {
+		return rf(ctx, job, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Job, *model.JobFile) model.Job); ok {
+		r0 = rf(ctx, job, input)
+	} else {
+		r0 = ret.Get(0).(model.Job)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Job, *model.JobFile) error); ok {
+		r1 = rf(ctx, job, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExtendJob provides a mock function with given fields: ctx, id, worker, progress, lockedUntil
+func (_m *JobRepository) ExtendJob(ctx context.Context, id uint64, worker string, progress int, lockedUntil time.Time) (bool, error) {
+	ret := _m.Called(ctx, id, worker, progress, lockedUntil)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtendJob")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string, int, time.Time) (bool, error)); ok {
+		return rf(ctx, id, worker, progress, lockedUntil)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string, int, time.Time) bool); ok {
+		r0 = rf(ctx, id, worker, progress, lockedUntil)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, string, int, time.Time) error); ok {
+		r1 = rf(ctx, id, worker, progress, lockedUntil)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FailExpiredJobs provides a mock function with given fields: ctx, now
+func (_m *JobRepository) FailExpiredJobs(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FailExpiredJobs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FinishJob provides a mock function with given fields: ctx, job, worker, output
+func (_m *JobRepository) FinishJob(ctx context.Context, job model.Job, worker string, output *model.JobFile) (bool, error) {
+	ret := _m.Called(ctx, job, worker, output)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishJob")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Job, string, *model.JobFile) (bool, error)); ok {
+		return rf(ctx, job, worker, output)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Job, string, *model.JobFile) bool); ok {
+		r0 = rf(ctx, job, worker, output)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Job, string, *model.JobFile) error); ok {
+		r1 = rf(ctx, job, worker, output)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJobByID provides a mock function with given fields: ctx, id
+func (_m *JobRepository) GetJobByID(ctx context.Context, id uint64) (model.Job, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobByID")
+	}
+
+	var r0 model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.Job, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.Job); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.Job)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJobFile provides a mock function with given fields: ctx, jobID, kind
+func (_m *JobRepository) GetJobFile(ctx context.Context, jobID uint64, kind string) (model.JobFile, error) {
+	ret := _m.Called(ctx, jobID, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobFile")
+	}
+
+	var r0 model.JobFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) (model.JobFile, error)); ok {
+		return rf(ctx, jobID, kind)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) model.JobFile); ok {
+		r0 = rf(ctx, jobID, kind)
+	} else {
+		r0 = ret.Get(0).(model.JobFile)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, string) error); ok {
+		r1 = rf(ctx, jobID, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewJobRepository creates a new instance of JobRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJobRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JobRepository {
+	mock := &JobRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,223 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderQuery is an autogenerated mock type for the OrderQuery type
+type OrderQuery struct {
+	mock.Mock
+}
+
+// ExportOrders provides a mock function with given fields: ctx, filter, chunkSize, fn
+func (_m *OrderQuery) ExportOrders(ctx context.Context, filter model.OrderFilter, chunkSize int, fn func([]model.Order) error) error {
+	ret := _m.Called(ctx, filter, chunkSize, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter, int, func([]model.Order) error) error); ok {
+		r0 = rf(ctx, filter, chunkSize, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetItemsByOrderIDs provides a mock function with given fields: ctx, orderIDs
+func (_m *OrderQuery) GetItemsByOrderIDs(ctx context.Context, orderIDs []uint64) ([]model.Item, error) {
+	ret := _m.Called(ctx, orderIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItemsByOrderIDs")
+	}
+
+	var r0 []model.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) ([]model.Item, error)); ok {
+		return rf(ctx, orderIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) []model.Item); ok {
+		r0 = rf(ctx, orderIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint64) error); ok {
+		r1 = rf(ctx, orderIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderSummaries provides a mock function with given fields: ctx
+func (_m *OrderQuery) GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderSummaries")
+	}
+
+	var r0 []model.OrderSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.OrderSummary, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.OrderSummary); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OrderSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrders provides a mock function with given fields: ctx, filter
+func (_m *OrderQuery) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrders")
+	}
+
+	var r0 []model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter) ([]model.Order, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter) []model.Order); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.OrderFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrdersByCustomers provides a mock function with given fields: ctx, names, filter
+func (_m *OrderQuery) GetOrdersByCustomers(ctx context.Context, names []string, filter model.OrderFilter) ([]model.Order, error) {
+	ret := _m.Called(ctx, names, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersByCustomers")
+	}
+
+	var r0 []model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, model.OrderFilter) ([]model.Order, error)); ok {
+		return rf(ctx, names, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, model.OrderFilter) []model.Order); ok {
+		r0 = rf(ctx, names, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, model.OrderFilter) error); ok {
+		r1 = rf(ctx, names, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrdersByID provides a mock function with given fields: ctx, id
+func (_m *OrderQuery) GetOrdersByID(ctx context.Context, id uint64) (model.Order, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersByID")
+	}
+
+	var r0 model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.Order, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.Order); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrdersByIDForUpdate provides a mock function with given fields: ctx, id
+func (_m *OrderQuery) GetOrdersByIDForUpdate(ctx context.Context, id uint64) (model.Order, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersByIDForUpdate")
+	}
+
+	var r0 model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.Order, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.Order); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrderQuery creates a new instance of OrderQuery. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrderQuery(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrderQuery {
+	mock := &OrderQuery{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,164 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	event "github.com/MidnightHelix/assignment-2/internal/event"
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+
+	time "time"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// Append provides a mock function with given fields: ctx, events
+func (_m *OutboxRepository) Append(ctx context.Context, events ...event.Event) error {
+	_va := make([]interface{}, len(events))
+	for _i := range events {
+		_va[_i] = events[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Append")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...event.Event) error); ok {
+		r0 = rf(ctx, events...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPending provides a mock function with given fields: ctx, now, limit
+func (_m *OutboxRepository) GetPending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPending")
+	}
+
+	var r0 []model.OutboxEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]model.OutboxEvent, error)); ok {
+		return rf(ctx, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []model.OutboxEvent); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OutboxEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSince provides a mock function with given fields: ctx, afterSequence, limit
+func (_m *OutboxRepository) GetSince(ctx context.Context, afterSequence uint64, limit int) ([]model.OutboxEvent, error) {
+	ret := _m.Called(ctx, afterSequence, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSince")
+	}
+
+	var r0 []model.OutboxEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) ([]model.OutboxEvent, error)); ok {
+		return rf(ctx, afterSequence, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) []model.OutboxEvent); ok {
+		r0 = rf(ctx, afterSequence, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OutboxEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, int) error); ok {
+		r1 = rf(ctx, afterSequence, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkFailed provides a mock function with given fields: ctx, id, reason, nextAttemptAt
+func (_m *OutboxRepository) MarkFailed(ctx context.Context, id uint64, reason string, nextAttemptAt time.Time) error {
+	ret := _m.Called(ctx, id, reason, nextAttemptAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string, time.Time) error); ok {
+		r0 = rf(ctx, id, reason, nextAttemptAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkPublished provides a mock function with given fields: ctx, id, at
+func (_m *OutboxRepository) MarkPublished(ctx context.Context, id uint64, at time.Time) (uint64, error) {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkPublished")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) (uint64, error)); ok {
+		return rf(ctx, id, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) uint64); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, time.Time) error); ok {
+		r1 = rf(ctx, id, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
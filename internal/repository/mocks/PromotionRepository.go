@@ -0,0 +1,227 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PromotionRepository is an autogenerated mock type for the PromotionRepository type
+type PromotionRepository struct {
+	mock.Mock
+}
+
+// CountCustomerRedemptions provides a mock function with given fields: ctx, promotionID, customer
+func (_m *PromotionRepository) CountCustomerRedemptions(ctx context.Context, promotionID uint64, customer string) (uint64, error) {
+	ret := _m.Called(ctx, promotionID, customer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountCustomerRedemptions")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) (uint64, error)); ok {
+		return rf(ctx, promotionID, customer)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) uint64); ok {
+		r0 = rf(ctx, promotionID, customer)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, string) error); ok {
+		r1 = rf(ctx, promotionID, customer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePromotion provides a mock function with given fields: ctx, promo
+func (_m *PromotionRepository) CreatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error) {
+	ret := _m.Called(ctx, promo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePromotion")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion) (model.Promotion, error)); ok {
+		return rf(ctx, promo)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion) model.Promotion); ok {
+		r0 = rf(ctx, promo)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Promotion) error); ok {
+		r1 = rf(ctx, promo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotionByCodeForUpdate provides a mock function with given fields: ctx, code
+func (_m *PromotionRepository) GetPromotionByCodeForUpdate(ctx context.Context, code string) (model.Promotion, error) {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromotionByCodeForUpdate")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.Promotion, error)); ok {
+		return rf(ctx, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Promotion); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotionByID provides a mock function with given fields: ctx, id
+func (_m *PromotionRepository) GetPromotionByID(ctx context.Context, id uint64) (model.Promotion, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromotionByID")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.Promotion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.Promotion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotions provides a mock function with given fields: ctx
+func (_m *PromotionRepository) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromotions")
+	}
+
+	var r0 []model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.Promotion, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Promotion); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redeem provides a mock function with given fields: ctx, redemption
+func (_m *PromotionRepository) Redeem(ctx context.Context, redemption model.PromotionRedemption) (bool, error) {
+	ret := _m.Called(ctx, redemption)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Redeem")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.PromotionRedemption) (bool, error)); ok {
+		return rf(ctx, redemption)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.PromotionRedemption) bool); ok {
+		r0 = rf(ctx, redemption)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.PromotionRedemption) error); ok {
+		r1 = rf(ctx, redemption)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePromotion provides a mock function with given fields: ctx, promo
+func (_m *PromotionRepository) UpdatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error) {
+	ret := _m.Called(ctx, promo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePromotion")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion) (model.Promotion, error)); ok {
+		return rf(ctx, promo)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion) model.Promotion); ok {
+		r0 = rf(ctx, promo)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Promotion) error); ok {
+		r1 = rf(ctx, promo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPromotionRepository creates a new instance of PromotionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPromotionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PromotionRepository {
+	mock := &PromotionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,36 +5,75 @@ import (
 
 	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
 	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// OrderQuery is the read side of the order repository.
 type OrderQuery interface {
-	GetOrders(ctx context.Context) ([]model.Order, error)
+	GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error)
+	// GetOrdersByCustomers pages through the orders of several customers in
+	// one query: up to filter.Limit orders of each, by ascending ID. The
+	// customer name of filter is ignored.
+	GetOrdersByCustomers(ctx context.Context, names []string, filter model.OrderFilter) ([]model.Order, error)
+	// ExportOrders hands the orders that match filter to fn in chunks of
+	// chunkSize, walking the table by primary key so a whole chunk is never
+	// held more than once.
+	ExportOrders(ctx context.Context, filter model.OrderFilter, chunkSize int, fn func(orders []model.Order) error) error
 	GetOrdersByID(ctx context.Context, id uint64) (model.Order, error)
-	DeleteOrder(ctx context.Context, id uint64) error
-	CreateOrder(ctx context.Context, order model.Order) (model.Order, error)
-	UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error)
-}
-
-type OrderCommand interface {
-	CreateOrder(ctx context.Context, order model.Order) (model.Order, error)
+	// GetOrdersByIDForUpdate is GetOrdersByID with a row lock held until the
+	// surrounding transaction ends. The items are loaded too.
+	GetOrdersByIDForUpdate(ctx context.Context, id uint64) (model.Order, error)
+	GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error)
+	// GetItemsByOrderIDs loads the items of several orders in one query,
+	// ordered by order and item ID.
+	GetItemsByOrderIDs(ctx context.Context, orderIDs []uint64) ([]model.Item, error)
 }
 
 type orderQueryImpl struct {
-	db infrastructure.GormPostgres
+	db infrastructure.GormDB
 }
 
-func NewOrderQuery(db infrastructure.GormPostgres) OrderQuery {
+func NewOrderQuery(db infrastructure.GormDB) OrderQuery {
 	return &orderQueryImpl{db: db}
 }
 
-func (u *orderQueryImpl) GetOrders(ctx context.Context) ([]model.Order, error) {
-	db := u.db.GetConnection()
+func (u *orderQueryImpl) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	db := conn(ctx, u.db)
+	orders := []model.Order{}
+	q := filterOrders(db.WithContext(ctx).Table("orders"), filter)
+	if filter.Limit > 0 {
+		q = q.Order("id").Limit(filter.Limit)
+	}
+	if err := q.Preload("Items").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (u *orderQueryImpl) GetOrdersByCustomers(ctx context.Context, names []string, filter model.OrderFilter) ([]model.Order, error) {
+	db := conn(ctx, u.db).WithContext(ctx)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter.CustomerName = ""
+	// the tenant scope does not reach subqueries, so the ranking filters
+	// by tenant itself
+	ranked := filterOrders(db.Table("orders"), filter).
+		Select("id, ROW_NUMBER() OVER (PARTITION BY customer_name ORDER BY id) AS page_rank").
+		Where("customer_name IN ?", names).
+		Where("(? = '' OR tenant_id = ?)", tenantID, tenantID)
+	page := db.Table("(?) AS ranked", ranked).Select("id")
+	if filter.Limit > 0 {
+		page = page.Where("page_rank <= ?", filter.Limit)
+	}
 	orders := []model.Order{}
 	if err := db.
-		WithContext(ctx).
 		Table("orders").
+		Where("id IN (?)", page).
+		Order("id").
 		Preload("Items").
 		Find(&orders).Error; err != nil {
 		return nil, err
@@ -42,8 +81,18 @@ func (u *orderQueryImpl) GetOrders(ctx context.Context) ([]model.Order, error) {
 	return orders, nil
 }
 
+func (u *orderQueryImpl) ExportOrders(ctx context.Context, filter model.OrderFilter, chunkSize int, fn func(orders []model.Order) error) error {
+	db := conn(ctx, u.db)
+	orders := []model.Order{}
+	return filterOrders(db.WithContext(ctx).Table("orders"), filter).
+		Preload("Items").
+		FindInBatches(&orders, chunkSize, func(tx *gorm.DB, batch int) error {
+			return fn(orders)
+		}).Error
+}
+
 func (u *orderQueryImpl) GetOrdersByID(ctx context.Context, id uint64) (model.Order, error) {
-	db := u.db.GetConnection()
+	db := conn(ctx, u.db)
 	order := model.Order{}
 	if err := db.
 		WithContext(ctx).
@@ -55,39 +104,69 @@ func (u *orderQueryImpl) GetOrdersByID(ctx context.Context, id uint64) (model.Or
 	return order, nil
 }
 
-func (u *orderQueryImpl) CreateOrder(ctx context.Context, order model.Order) (model.Order, error) {
-	db := u.db.GetConnection()
+func (u *orderQueryImpl) GetOrdersByIDForUpdate(ctx context.Context, id uint64) (model.Order, error) {
+	db := conn(ctx, u.db)
+	order := model.Order{}
 	if err := db.
 		WithContext(ctx).
 		Table("orders").
-		Save(&order).Error; err != nil {
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items").
+		Where("id = ?", id).
+		Find(&order).Error; err != nil {
 		return model.Order{}, err
 	}
 	return order, nil
 }
 
-func (u *orderQueryImpl) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
-	db := u.db.GetConnection()
+func (u *orderQueryImpl) GetItemsByOrderIDs(ctx context.Context, orderIDs []uint64) ([]model.Item, error) {
+	db := conn(ctx, u.db)
+	items := []model.Item{}
 	if err := db.
-		Session(&gorm.Session{FullSaveAssociations: true}).
 		WithContext(ctx).
-		Table("orders").
-		Save(&order).Error; err != nil {
-		return model.Order{}, err
+		Table("items").
+		Where("order_id IN ?", orderIDs).
+		Order("order_id, id").
+		Find(&items).Error; err != nil {
+		return nil, err
 	}
-	return order, nil
+	return items, nil
 }
 
-func (u *orderQueryImpl) DeleteOrder(ctx context.Context, id uint64) error {
-	db := u.db.GetConnection()
+func (u *orderQueryImpl) GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error) {
+	db := conn(ctx, u.db)
+	summaries := []model.OrderSummary{}
 	if err := db.
-		Session(&gorm.Session{FullSaveAssociations: true}).
 		WithContext(ctx).
-		Table("orders").
-		Where("id = ?", id).
-		Select(clause.Associations).
-		Delete(&model.Order{ID: id}).Error; err != nil {
-		return err
+		Table("order_summaries").
+		Order("order_id").
+		Find(&summaries).Error; err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func filterOrders(db *gorm.DB, filter model.OrderFilter) *gorm.DB {
+	if filter.CustomerName != "" {
+		db = db.Where("customer_name = ?", filter.CustomerName)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	// SQLite keeps times as text, which only compares right within one UTC
+	// offset
+	orderedAt, at := "ordered_at", "?"
+	if db.Dialector.Name() == "sqlite" {
+		orderedAt, at = "julianday(ordered_at)", "julianday(?)"
+	}
+	if !filter.OrderedFrom.IsZero() {
+		db = db.Where(orderedAt+" >= "+at, filter.OrderedFrom)
+	}
+	if !filter.OrderedTo.IsZero() {
+		db = db.Where(orderedAt+" < "+at, filter.OrderedTo)
+	}
+	if filter.AfterID > 0 {
+		db = db.Where("id > ?", filter.AfterID)
 	}
-	return nil
+	return db
 }
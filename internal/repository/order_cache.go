@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/cache"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cached orders are keyed by a generation token, and a write replaces the
+// token instead of deleting the entries. A read that raced with the write
+// and stores what it loaded before the commit stores it under the old
+// token, where nobody looks anymore. Entries are kept per tenant, since the
+// orders a tenant sees differ; order IDs are shared by the tenants, so the
+// generations of single orders are too.
+const (
+	// listGenerationKey versions every cached listing; any write changes it.
+	listGenerationKey = "orders:gen"
+
+	// sharedLoadTimeout bounds a database read shared by concurrent misses,
+	// which runs on after the caller that started it gives up.
+	sharedLoadTimeout = 30 * time.Second
+)
+
+func orderGenerationKey(id uint64) string {
+	return "order:" + strconv.FormatUint(id, 10) + ":gen"
+}
+
+// tenantKey prefixes key with the tenant of ctx.
+func tenantKey(ctx context.Context, key string) string {
+	if id := tenantOf(ctx); id != "" {
+		return "tenant:" + id + ":" + key
+	}
+	return key
+}
+
+type cachedOrderQueryImpl struct {
+	OrderQuery
+	cache cache.Cache
+	ttl   time.Duration
+	stats *cache.Stats
+	group singleflight.Group
+}
+
+// NewCachedOrderQuery caches the results of GetOrders and GetOrdersByID in c
+// for ttl. Concurrent misses of the same key share one database query.
+// Reads inside a transaction, locking reads and exports go straight to next.
+// Pair it with NewInvalidatingOrderCommand on the same cache.
+func NewCachedOrderQuery(next OrderQuery, c cache.Cache, ttl time.Duration, stats *cache.Stats) OrderQuery {
+	return &cachedOrderQueryImpl{OrderQuery: next, cache: c, ttl: ttl, stats: stats}
+}
+
+func (u *cachedOrderQueryImpl) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	if inTx(ctx) {
+		return u.OrderQuery.GetOrders(ctx, filter)
+	}
+	key, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	orders := []model.Order{}
+	err = u.readThrough(ctx, tenantKey(ctx, listGenerationKey), tenantKey(ctx, "orders:list:"+hex.EncodeToString(sum[:])), &orders, func(ctx context.Context) (any, error) {
+		return u.OrderQuery.GetOrders(ctx, filter)
+	})
+	return orders, err
+}
+
+func (u *cachedOrderQueryImpl) GetOrdersByID(ctx context.Context, id uint64) (model.Order, error) {
+	if inTx(ctx) {
+		return u.OrderQuery.GetOrdersByID(ctx, id)
+	}
+	order := model.Order{}
+	err := u.readThrough(ctx, orderGenerationKey(id), tenantKey(ctx, "order:"+strconv.FormatUint(id, 10)), &order, func(ctx context.Context) (any, error) {
+		return u.OrderQuery.GetOrdersByID(ctx, id)
+	})
+	return order, err
+}
+
+// readThrough decodes the cached value of key into dst, or loads, caches and
+// decodes it. Cache failures are counted and fall back to load. A load
+// shared by concurrent misses does not run under the ctx of any one of
+// them, so that a caller that gives up does not fail the others; each
+// caller still returns as soon as its own ctx is done.
+func (u *cachedOrderQueryImpl) readThrough(ctx context.Context, genKey, key string, dst any, load func(ctx context.Context) (any, error)) error {
+	gen, err := u.generation(ctx, genKey)
+	if err != nil {
+		u.cacheError("generation", err)
+		return u.decode(ctx, load, dst)
+	}
+	key = key + ":" + gen
+
+	if data, ok, err := u.cache.Get(ctx, key); err != nil {
+		u.cacheError("get", err)
+	} else if ok {
+		if err := json.Unmarshal(data, dst); err == nil {
+			u.stats.Hit()
+			return nil
+		}
+		u.cacheError("decode", err)
+	}
+	u.stats.Miss()
+
+	loaded := u.group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedLoadTimeout)
+		defer cancel()
+		res, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(res)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.cache.Set(ctx, key, data, u.ttl); err != nil {
+			u.cacheError("set", err)
+		}
+		return data, nil
+	})
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-loaded:
+		if res.Err != nil {
+			return res.Err
+		}
+		return json.Unmarshal(res.Val.([]byte), dst)
+	}
+}
+
+func (u *cachedOrderQueryImpl) decode(ctx context.Context, load func(ctx context.Context) (any, error), dst any) error {
+	res, err := load(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// generation returns the token that versions the entries under genKey,
+// starting a new generation when the token was evicted or expired.
+func (u *cachedOrderQueryImpl) generation(ctx context.Context, genKey string) (string, error) {
+	gen, ok, err := u.cache.Get(ctx, genKey)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(gen), nil
+	}
+	token := newGeneration()
+	if err := u.cache.Set(ctx, genKey, []byte(token), 0); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (u *cachedOrderQueryImpl) cacheError(op string, err error) {
+	u.stats.Error()
+	log.Printf("order cache: %s: %v", op, err)
+}
+
+type invalidatingOrderCommandImpl struct {
+	OrderCommand
+	cache cache.Cache
+}
+
+// NewInvalidatingOrderCommand drops the cached reads of NewCachedOrderQuery
+// that a write makes stale, once the write has committed.
+func NewInvalidatingOrderCommand(next OrderCommand, c cache.Cache) OrderCommand {
+	return &invalidatingOrderCommandImpl{OrderCommand: next, cache: c}
+}
+
+func (u *invalidatingOrderCommandImpl) CreateOrder(ctx context.Context, order model.Order) (model.Order, error) {
+	res, err := u.OrderCommand.CreateOrder(ctx, order)
+	if err == nil {
+		u.invalidate(ctx, res.ID)
+	}
+	return res, err
+}
+
+func (u *invalidatingOrderCommandImpl) CreateOrders(ctx context.Context, orders []model.Order) ([]model.Order, error) {
+	res, err := u.OrderCommand.CreateOrders(ctx, orders)
+	if err == nil {
+		ids := make([]uint64, 0, len(res))
+		for _, order := range res {
+			ids = append(ids, order.ID)
+		}
+		u.invalidate(ctx, ids...)
+	}
+	return res, err
+}
+
+func (u *invalidatingOrderCommandImpl) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
+	res, err := u.OrderCommand.UpdateOrder(ctx, order, id)
+	if err == nil {
+		u.invalidate(ctx, id)
+	}
+	return res, err
+}
+
+func (u *invalidatingOrderCommandImpl) DeleteItems(ctx context.Context, orderID uint64, itemIDs []uint64) error {
+	err := u.OrderCommand.DeleteItems(ctx, orderID, itemIDs)
+	if err == nil {
+		u.invalidate(ctx, orderID)
+	}
+	return err
+}
+
+func (u *invalidatingOrderCommandImpl) DeleteOrder(ctx context.Context, id uint64) error {
+	err := u.OrderCommand.DeleteOrder(ctx, id)
+	if err == nil {
+		u.invalidate(ctx, id)
+	}
+	return err
+}
+
+// invalidate starts new generations for the listings of the tenant of ctx,
+// those read without a tenant and the given orders. New orders are
+// invalidated too: a lookup that missed before the insert may have cached
+// the zero order.
+func (u *invalidatingOrderCommandImpl) invalidate(ctx context.Context, ids ...uint64) {
+	keys := []string{listGenerationKey}
+	if key := tenantKey(ctx, listGenerationKey); key != listGenerationKey {
+		keys = append(keys, key)
+	}
+	for _, id := range ids {
+		keys = append(keys, orderGenerationKey(id))
+	}
+	afterCommit(ctx, func() {
+		// the request may be over by the time the transaction commits
+		ctx := context.WithoutCancel(ctx)
+		if err := u.cache.Delete(ctx, keys...); err != nil {
+			log.Printf("order cache: invalidate %v: %v", keys, err)
+		}
+	})
+}
+
+func newGeneration() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
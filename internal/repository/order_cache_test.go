@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/cache"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingOrders serves orders from a map and counts the reads that reach
+// it. When release is set, reads wait for it to be closed or for their ctx
+// to be done.
+type countingOrders struct {
+	OrderQuery
+	OrderCommand
+	mu      sync.Mutex
+	orders  map[uint64]model.Order
+	reads   atomic.Int64
+	release chan struct{}
+}
+
+func (f *countingOrders) GetOrdersByID(ctx context.Context, id uint64) (model.Order, error) {
+	f.reads.Add(1)
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return model.Order{}, ctx.Err()
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.orders[id], nil
+}
+
+func (f *countingOrders) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	f.reads.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	orders := []model.Order{}
+	for _, o := range f.orders {
+		if filter.Status == "" || o.Status == filter.Status {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (f *countingOrders) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.orders[id] = order
+	return order, nil
+}
+
+func newCachedOrders() (*countingOrders, OrderQuery, OrderCommand, *cache.Stats) {
+	db := &countingOrders{orders: map[uint64]model.Order{1: {ID: 1, CustomerName: "alice", Status: "pending"}}}
+	c := cache.NewLRU(100)
+	stats := &cache.Stats{}
+	return db, NewCachedOrderQuery(db, c, time.Minute, stats), NewInvalidatingOrderCommand(db, c), stats
+}
+
+func TestCachedOrderQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads through", func(t *testing.T) {
+		db, query, _, stats := newCachedOrders()
+
+		for i := 0; i < 3; i++ {
+			order, err := query.GetOrdersByID(ctx, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, "alice", order.CustomerName)
+		}
+		for i := 0; i < 2; i++ {
+			orders, err := query.GetOrders(ctx, model.OrderFilter{Status: "pending"})
+			assert.Nil(t, err)
+			assert.Equal(t, 1, len(orders))
+		}
+		assert.Equal(t, int64(2), db.reads.Load())
+		assert.Equal(t, map[string]int64{"hits": 3, "misses": 2, "errors": 0}, stats.Snapshot())
+	})
+
+	t.Run("invalidates on write", func(t *testing.T) {
+		db, query, command, _ := newCachedOrders()
+
+		query.GetOrdersByID(ctx, 1)
+		query.GetOrders(ctx, model.OrderFilter{})
+		_, err := command.UpdateOrder(ctx, model.Order{ID: 1, CustomerName: "bob"}, 1)
+		assert.Nil(t, err)
+
+		order, _ := query.GetOrdersByID(ctx, 1)
+		assert.Equal(t, "bob", order.CustomerName)
+		orders, _ := query.GetOrders(ctx, model.OrderFilter{})
+		assert.Equal(t, "bob", orders[0].CustomerName)
+		assert.Equal(t, int64(4), db.reads.Load())
+	})
+
+	t.Run("keeps tenants apart", func(t *testing.T) {
+		db, query, command, _ := newCachedOrders()
+		shopA := tenant.With(ctx, "shop-a")
+		shopB := tenant.With(ctx, "shop-b")
+
+		query.GetOrders(shopA, model.OrderFilter{})
+		query.GetOrders(shopB, model.OrderFilter{})
+		query.GetOrdersByID(shopB, 1)
+		assert.Equal(t, int64(3), db.reads.Load())
+
+		// a write of one tenant leaves the listings of the others cached
+		_, err := command.UpdateOrder(shopA, model.Order{ID: 1, CustomerName: "bob"}, 1)
+		assert.Nil(t, err)
+		query.GetOrders(shopA, model.OrderFilter{})
+		query.GetOrders(shopB, model.OrderFilter{})
+		assert.Equal(t, int64(4), db.reads.Load())
+		order, _ := query.GetOrdersByID(shopB, 1)
+		assert.Equal(t, "bob", order.CustomerName)
+		assert.Equal(t, int64(5), db.reads.Load())
+	})
+
+	t.Run("concurrent misses share one read", func(t *testing.T) {
+		db, query, _, _ := newCachedOrders()
+		db.release = make(chan struct{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				order, err := query.GetOrdersByID(ctx, 1)
+				assert.Nil(t, err)
+				assert.Equal(t, "alice", order.CustomerName)
+			}()
+		}
+		// let the goroutines pile up on the first read
+		time.Sleep(20 * time.Millisecond)
+		close(db.release)
+		wg.Wait()
+		assert.Equal(t, int64(1), db.reads.Load())
+	})
+
+	t.Run("a canceled miss does not fail the others", func(t *testing.T) {
+		db, query, _, _ := newCachedOrders()
+		db.release = make(chan struct{})
+
+		first, cancel := context.WithCancel(ctx)
+		canceled := make(chan error)
+		go func() {
+			_, err := query.GetOrdersByID(first, 1)
+			canceled <- err
+		}()
+		assert.Eventually(t, func() bool { return db.reads.Load() == 1 }, time.Second, time.Millisecond)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				order, err := query.GetOrdersByID(ctx, 1)
+				assert.Nil(t, err)
+				assert.Equal(t, "alice", order.CustomerName)
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-canceled, context.Canceled)
+
+		close(db.release)
+		wg.Wait()
+		assert.Equal(t, int64(1), db.reads.Load())
+	})
+}
+
+func TestInvalidationAfterCommit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("waits for the commit", func(t *testing.T) {
+		sqlDB, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(sqlDB)
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		db, query, command, _ := newCachedOrders()
+		query.GetOrdersByID(ctx, 1)
+
+		err := NewTxManager(postgresMock).WithinTx(ctx, func(ctx context.Context) error {
+			command.UpdateOrder(ctx, model.Order{ID: 1, CustomerName: "bob"}, 1)
+			// still cached until the commit
+			order, _ := query.GetOrdersByID(context.Background(), 1)
+			assert.Equal(t, "alice", order.CustomerName)
+			return nil
+		})
+		assert.Nil(t, err)
+
+		order, _ := query.GetOrdersByID(ctx, 1)
+		assert.Equal(t, "bob", order.CustomerName)
+		assert.Equal(t, int64(2), db.reads.Load())
+	})
+
+	t.Run("dropped on rollback", func(t *testing.T) {
+		sqlDB, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(sqlDB)
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		db, query, command, _ := newCachedOrders()
+		query.GetOrdersByID(ctx, 1)
+
+		err := NewTxManager(postgresMock).WithinTx(ctx, func(ctx context.Context) error {
+			command.UpdateOrder(ctx, model.Order{ID: 1, CustomerName: "bob"}, 1)
+			return sqlmock.ErrCancelled
+		})
+		assert.NotNil(t, err)
+
+		query.GetOrdersByID(ctx, 1)
+		assert.Equal(t, int64(1), db.reads.Load())
+	})
+}
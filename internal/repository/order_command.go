@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderCommand is the write side of the order repository. Every mutation
+// also refreshes the order_summaries read model in the same transaction.
+type OrderCommand interface {
+	CreateOrder(ctx context.Context, order model.Order) (model.Order, error)
+	// CreateOrders inserts all orders with one statement, and all of their
+	// items with another.
+	CreateOrders(ctx context.Context, orders []model.Order) ([]model.Order, error)
+	// UpdateOrder saves the order and upserts the items it lists. Items left
+	// out are kept; remove them with DeleteItems.
+	UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error)
+	// DeleteItems removes the given items of an order.
+	DeleteItems(ctx context.Context, orderID uint64, itemIDs []uint64) error
+	DeleteOrder(ctx context.Context, id uint64) error
+}
+
+type orderCommandImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewOrderCommand(db infrastructure.GormDB) OrderCommand {
+	return &orderCommandImpl{db: db}
+}
+
+func (u *orderCommandImpl) CreateOrder(ctx context.Context, order model.Order) (model.Order, error) {
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Table("orders").
+				Save(&order).Error; err != nil {
+				return err
+			}
+			return refreshSummary(tx, order.ID)
+		}); err != nil {
+		return model.Order{}, err
+	}
+	return order, nil
+}
+
+func (u *orderCommandImpl) CreateOrders(ctx context.Context, orders []model.Order) ([]model.Order, error) {
+	if len(orders) == 0 {
+		return orders, nil
+	}
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Table("orders").
+				Create(&orders).Error; err != nil {
+				return err
+			}
+			ids := make([]uint64, 0, len(orders))
+			for _, order := range orders {
+				ids = append(ids, order.ID)
+			}
+			return refreshSummary(tx, ids...)
+		}); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (u *orderCommandImpl) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Session(&gorm.Session{FullSaveAssociations: true}).
+				Table("orders").
+				Save(&order).Error; err != nil {
+				return err
+			}
+			return refreshSummary(tx, order.ID)
+		}); err != nil {
+		return model.Order{}, err
+	}
+	return order, nil
+}
+
+func (u *orderCommandImpl) DeleteItems(ctx context.Context, orderID uint64, itemIDs []uint64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Where("order_id = ? AND id IN ?", orderID, itemIDs).
+				Delete(&model.Item{}).Error; err != nil {
+				return err
+			}
+			return refreshSummary(tx, orderID)
+		}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *orderCommandImpl) DeleteOrder(ctx context.Context, id uint64) error {
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Where("order_id = ?", id).
+				Delete(&model.OrderSummary{}).Error; err != nil {
+				return err
+			}
+			return tx.
+				Session(&gorm.Session{FullSaveAssociations: true}).
+				Table("orders").
+				Where("id = ?", id).
+				Select(clause.Associations).
+				Delete(&model.Order{ID: id}).Error
+		}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// refreshSummary recomputes the order_summaries rows of the given orders from
+// the orders and items tables.
+func refreshSummary(tx *gorm.DB, orderIDs ...uint64) error {
+	return tx.Exec(`
+		INSERT INTO order_summaries (order_id, tenant_id, customer_name, ordered_at, item_count, total_quantity, updated_at)
+		SELECT o.id, o.tenant_id, o.customer_name, o.ordered_at, COUNT(i.id), COALESCE(SUM(i.quantity), 0), CURRENT_TIMESTAMP
+		FROM orders o
+		LEFT JOIN items i ON i.order_id = o.id
+		WHERE o.id IN ?
+		GROUP BY o.id, o.tenant_id, o.customer_name, o.ordered_at
+		ON CONFLICT (order_id) DO UPDATE SET
+			customer_name = EXCLUDED.customer_name,
+			ordered_at = EXCLUDED.ordered_at,
+			item_count = EXCLUDED.item_count,
+			total_quantity = EXCLUDED.total_quantity,
+			updated_at = EXCLUDED.updated_at
+	`, orderIDs).Error
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOrder(t *testing.T) {
+	t.Run("error create order", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "orders"
+		`)).WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		order := model.Order{CustomerName: "test", OrderedAt: time.Now()}
+		res, err := orderRepo.CreateOrder(context.Background(), order)
+		assert.NotNil(t, err)
+		assert.Equal(t, model.Order{}, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success create order refreshes summary", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "orders"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			INSERT INTO order_summaries
+		`)).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		order := model.Order{CustomerName: "test", OrderedAt: time.Now()}
+		res, err := orderRepo.CreateOrder(context.Background(), order)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(7), res.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateOrders(t *testing.T) {
+	t.Run("success create orders with multi-row inserts", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "orders" .* VALUES \(.*\),\(.*\) RETURNING`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectQuery(`INSERT INTO "items" .* VALUES \(.*\),\(.*\),\(.*\) ON CONFLICT`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10).AddRow(11).AddRow(12))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			INSERT INTO order_summaries
+		`)).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		orders := []model.Order{
+			{CustomerName: "a", Items: []model.Item{{ItemCode: "A", Quantity: 1}, {ItemCode: "B", Quantity: 2}}},
+			{CustomerName: "b", Items: []model.Item{{ItemCode: "C", Quantity: 3}}},
+		}
+		res, err := orderRepo.CreateOrders(context.Background(), orders)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), res[0].ID)
+		assert.Equal(t, uint64(2), res[1].ID)
+		assert.Equal(t, uint64(12), res[1].Items[0].ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error create orders", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "orders"
+		`)).WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		res, err := orderRepo.CreateOrders(context.Background(), []model.Order{{CustomerName: "a"}})
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateOrder(t *testing.T) {
+	t.Run("success update order", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "orders"
+		`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "items"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			INSERT INTO order_summaries
+		`)).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		order := model.Order{ID: 7, CustomerName: "test", OrderedAt: time.Now(), Items: []model.Item{{ID: 10, ItemCode: "A", Quantity: 1}}}
+		res, err := orderRepo.UpdateOrder(context.Background(), order, 7)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(7), res.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteItems(t *testing.T) {
+	t.Run("success delete items", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`
+			DELETE FROM "items" WHERE order_id = $1 AND id IN ($2,$3)
+		`)).WithArgs(7, 10, 11).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			INSERT INTO order_summaries
+		`)).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		assert.Nil(t, orderRepo.DeleteItems(context.Background(), 7, []uint64{10, 11}))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to delete", func(t *testing.T) {
+		postgresMock := mocks.NewGormDB(t)
+
+		orderRepo := orderCommandImpl{db: postgresMock}
+		assert.Nil(t, orderRepo.DeleteItems(context.Background(), 7, nil))
+	})
+}
+
+func TestDeleteOrder(t *testing.T) {
+	t.Run("error deleting order", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`
+			DELETE FROM "order_summaries" WHERE order_id = $1
+		`)).WithArgs(123).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			DELETE FROM "items" WHERE "items"."order_id" = $1
+		`)).WithArgs(123).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`
+			DELETE FROM "orders" WHERE id = $1
+		`)).WithArgs(123, 123).WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		u := orderCommandImpl{db: postgresMock}
+		err := u.DeleteOrder(context.Background(), 123)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/config"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderRepos is one implementation of the order repositories.
+type orderRepos struct {
+	query   OrderQuery
+	command OrderCommand
+	tx      TxManager
+}
+
+func TestMemoryOrderContract(t *testing.T) {
+	testOrderContract(t, func(t *testing.T) orderRepos {
+		store := NewMemoryStore()
+		return orderRepos{NewMemoryOrderQuery(store), NewMemoryOrderCommand(store), NewMemoryTxManager(store)}
+	})
+}
+
+func TestSQLiteOrderContract(t *testing.T) {
+	testOrderContract(t, func(t *testing.T) orderRepos {
+		db, err := infrastructure.NewGormDB(config.Database{Driver: "sqlite", URL: "file::memory:", ConnectDeadline: time.Second})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			sqlDB, _ := db.GetConnection().DB()
+			sqlDB.Close()
+		})
+		return orderRepos{NewOrderQuery(db), NewOrderCommand(db), NewTxManager(db)}
+	})
+}
+
+// TestPostgresOrderContract runs against the database of the DB_* variables
+// when REPOSITORY_TEST_POSTGRES is set. It empties the order tables.
+func TestPostgresOrderContract(t *testing.T) {
+	if os.Getenv("REPOSITORY_TEST_POSTGRES") == "" {
+		t.Skip("set REPOSITORY_TEST_POSTGRES to run against Postgres")
+	}
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Database.Driver = "postgres"
+	db, err := infrastructure.NewGormDB(cfg.Database)
+	require.NoError(t, err)
+
+	testOrderContract(t, func(t *testing.T) orderRepos {
+		require.NoError(t, db.GetConnection().Exec(`TRUNCATE orders, items, order_summaries RESTART IDENTITY`).Error)
+		return orderRepos{NewOrderQuery(db), NewOrderCommand(db), NewTxManager(db)}
+	})
+}
+
+// testOrderContract checks the behavior every implementation of the order
+// repositories shares. newRepos returns repositories over empty tables.
+func testOrderContract(t *testing.T, newRepos func(t *testing.T) orderRepos) {
+	ctx := tenant.WithAllTenants(context.Background())
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newOrder := func(customer string, orderedAt time.Time, codes ...string) model.Order {
+		order := model.Order{CustomerName: customer, OrderedAt: orderedAt}
+		for i, code := range codes {
+			order.Items = append(order.Items, model.Item{ItemCode: code, Quantity: uint64(i + 1)})
+		}
+		return order
+	}
+
+	t.Run("create assigns IDs", func(t *testing.T) {
+		r := newRepos(t)
+		first, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1", "B2"))
+		require.NoError(t, err)
+		second, err := r.command.CreateOrder(ctx, newOrder("bob", day))
+		require.NoError(t, err)
+
+		assert.NotZero(t, first.ID)
+		assert.Greater(t, second.ID, first.ID)
+		assert.Equal(t, model.OrderStatusPending, first.Status)
+		require.Len(t, first.Items, 2)
+		assert.NotZero(t, first.Items[0].ID)
+		assert.Greater(t, first.Items[1].ID, first.Items[0].ID)
+		assert.Equal(t, first.ID, first.Items[1].OrderID)
+
+		orders, err := r.command.CreateOrders(ctx, []model.Order{newOrder("carol", day, "C3"), newOrder("dave", day)})
+		require.NoError(t, err)
+		assert.Greater(t, orders[0].ID, second.ID)
+		assert.Greater(t, orders[1].ID, orders[0].ID)
+		assert.Equal(t, orders[0].ID, orders[0].Items[0].OrderID)
+	})
+
+	t.Run("get by ID", func(t *testing.T) {
+		r := newRepos(t)
+		created, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1"))
+		require.NoError(t, err)
+
+		order, err := r.query.GetOrdersByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, order.ID)
+		assert.Equal(t, "alice", order.CustomerName)
+		assert.True(t, day.Equal(order.OrderedAt))
+
+		err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+			order, err = r.query.GetOrdersByIDForUpdate(ctx, created.ID)
+			return err
+		})
+		require.NoError(t, err)
+		require.Len(t, order.Items, 1)
+		assert.Equal(t, "A1", order.Items[0].ItemCode)
+
+		// a missing order is the zero order, not an error
+		missing, err := r.query.GetOrdersByID(ctx, created.ID+100)
+		assert.NoError(t, err)
+		assert.Zero(t, missing.ID)
+		missing, err = r.query.GetOrdersByIDForUpdate(ctx, created.ID+100)
+		assert.NoError(t, err)
+		assert.Zero(t, missing.ID)
+	})
+
+	t.Run("list with filters and pages", func(t *testing.T) {
+		r := newRepos(t)
+		ids := []uint64{}
+		for i, customer := range []string{"alice", "bob", "alice", "alice"} {
+			order, err := r.command.CreateOrder(ctx, newOrder(customer, day.AddDate(0, 0, i), "A1"))
+			require.NoError(t, err)
+			ids = append(ids, order.ID)
+		}
+
+		orders, err := r.query.GetOrders(ctx, model.OrderFilter{CustomerName: "alice", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+		assert.Equal(t, []uint64{ids[0], ids[2]}, []uint64{orders[0].ID, orders[1].ID})
+		assert.Len(t, orders[0].Items, 1)
+
+		orders, err = r.query.GetOrders(ctx, model.OrderFilter{CustomerName: "alice", AfterID: ids[2], Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, ids[3], orders[0].ID)
+
+		// OrderedFrom is inclusive and OrderedTo exclusive
+		orders, err = r.query.GetOrders(ctx, model.OrderFilter{OrderedFrom: day.AddDate(0, 0, 1), OrderedTo: day.AddDate(0, 0, 3), Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+		assert.Equal(t, []uint64{ids[1], ids[2]}, []uint64{orders[0].ID, orders[1].ID})
+
+		// times compare as instants, whatever their offset
+		jakarta := time.FixedZone("WIB", 7*60*60)
+		late, err := r.command.CreateOrder(ctx, newOrder("erin", time.Date(2024, 3, 2, 6, 0, 0, 0, jakarta)))
+		require.NoError(t, err)
+		orders, err = r.query.GetOrders(ctx, model.OrderFilter{CustomerName: "erin", OrderedTo: day.AddDate(0, 0, 1)})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, late.ID, orders[0].ID)
+		assert.True(t, late.OrderedAt.Equal(orders[0].OrderedAt))
+
+		orders, err = r.query.GetOrders(ctx, model.OrderFilter{Status: model.OrderStatusPaid})
+		require.NoError(t, err)
+		assert.Empty(t, orders)
+	})
+
+	t.Run("pages of several customers", func(t *testing.T) {
+		r := newRepos(t)
+		// another tenant's orders do not take a place in the page
+		_, err := r.command.CreateOrder(tenant.With(ctx, "shop-b"), newOrder("alice", day))
+		require.NoError(t, err)
+		ids := []uint64{}
+		for i, customer := range []string{"alice", "bob", "alice", "alice", "carol"} {
+			order, err := r.command.CreateOrder(ctx, newOrder(customer, day.AddDate(0, 0, i), "A1"))
+			require.NoError(t, err)
+			ids = append(ids, order.ID)
+		}
+
+		orders, err := r.query.GetOrdersByCustomers(tenant.With(ctx, tenant.Default), []string{"alice", "bob"}, model.OrderFilter{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, orders, 3)
+		assert.Equal(t, []uint64{ids[0], ids[1], ids[2]}, []uint64{orders[0].ID, orders[1].ID, orders[2].ID})
+		assert.Len(t, orders[0].Items, 1)
+
+		orders, err = r.query.GetOrdersByCustomers(tenant.With(ctx, tenant.Default), []string{"alice", "bob"}, model.OrderFilter{AfterID: ids[1], Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+		assert.Equal(t, []uint64{ids[2], ids[3]}, []uint64{orders[0].ID, orders[1].ID})
+	})
+
+	t.Run("update upserts the items", func(t *testing.T) {
+		r := newRepos(t)
+		created, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1", "B2"))
+		require.NoError(t, err)
+
+		change := created
+		change.CustomerName = "alicia"
+		change.Status = model.OrderStatusPaid
+		change.Items = []model.Item{created.Items[1], {ItemCode: "C3", Quantity: 5}}
+		change.Items[0].Quantity = 7
+		updated, err := r.command.UpdateOrder(ctx, change, created.ID)
+		require.NoError(t, err)
+		assert.NotZero(t, updated.Items[1].ID)
+
+		// the item left out is kept
+		items, err := r.query.GetItemsByOrderIDs(ctx, []uint64{created.ID})
+		require.NoError(t, err)
+		require.Len(t, items, 3)
+		assert.Equal(t, created.Items[0].ID, items[0].ID)
+		assert.Equal(t, created.Items[1].ID, items[1].ID)
+		assert.Equal(t, uint64(7), items[1].Quantity)
+		assert.Equal(t, "C3", items[2].ItemCode)
+		assert.Equal(t, created.ID, items[2].OrderID)
+
+		order, err := r.query.GetOrdersByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "alicia", order.CustomerName)
+		assert.Equal(t, model.OrderStatusPaid, order.Status)
+	})
+
+	t.Run("delete items removes only those of the order", func(t *testing.T) {
+		r := newRepos(t)
+		created, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1", "B2"))
+		require.NoError(t, err)
+		other, err := r.command.CreateOrder(ctx, newOrder("bob", day, "C3"))
+		require.NoError(t, err)
+
+		require.NoError(t, r.command.DeleteItems(ctx, created.ID, []uint64{created.Items[0].ID, other.Items[0].ID}))
+		items, err := r.query.GetItemsByOrderIDs(ctx, []uint64{created.ID, other.ID})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, created.Items[1].ID, items[0].ID)
+		assert.Equal(t, other.Items[0].ID, items[1].ID)
+
+		summaries, err := r.query.GetOrderSummaries(ctx)
+		require.NoError(t, err)
+		for _, s := range summaries {
+			if s.OrderID == created.ID {
+				assert.Equal(t, uint64(1), s.ItemCount)
+			}
+		}
+	})
+
+	t.Run("delete removes the order and its items", func(t *testing.T) {
+		r := newRepos(t)
+		created, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1"))
+		require.NoError(t, err)
+
+		require.NoError(t, r.command.DeleteOrder(ctx, created.ID))
+		order, err := r.query.GetOrdersByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Zero(t, order.ID)
+		items, err := r.query.GetItemsByOrderIDs(ctx, []uint64{created.ID})
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		summaries, err := r.query.GetOrderSummaries(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, summaries)
+
+		assert.NoError(t, r.command.DeleteOrder(ctx, created.ID))
+	})
+
+	t.Run("items of several orders", func(t *testing.T) {
+		r := newRepos(t)
+		first, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1", "B2"))
+		require.NoError(t, err)
+		_, err = r.command.CreateOrder(ctx, newOrder("bob", day, "C3"))
+		require.NoError(t, err)
+		third, err := r.command.CreateOrder(ctx, newOrder("carol", day, "D4"))
+		require.NoError(t, err)
+
+		items, err := r.query.GetItemsByOrderIDs(ctx, []uint64{third.ID, first.ID})
+		require.NoError(t, err)
+		codes := []string{}
+		for _, item := range items {
+			codes = append(codes, item.ItemCode)
+		}
+		assert.Equal(t, []string{"A1", "B2", "D4"}, codes)
+	})
+
+	t.Run("summaries follow writes", func(t *testing.T) {
+		r := newRepos(t)
+		first, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1", "B2"))
+		require.NoError(t, err)
+		second, err := r.command.CreateOrder(ctx, newOrder("bob", day))
+		require.NoError(t, err)
+
+		summaries, err := r.query.GetOrderSummaries(ctx)
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+		assert.Equal(t, first.ID, summaries[0].OrderID)
+		assert.Equal(t, uint64(2), summaries[0].ItemCount)
+		assert.Equal(t, uint64(3), summaries[0].TotalQuantity)
+		assert.Equal(t, second.ID, summaries[1].OrderID)
+		assert.Zero(t, summaries[1].ItemCount)
+	})
+
+	t.Run("export in chunks", func(t *testing.T) {
+		r := newRepos(t)
+		for i := 0; i < 5; i++ {
+			_, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1"))
+			require.NoError(t, err)
+		}
+		_, err := r.command.CreateOrder(ctx, newOrder("bob", day))
+		require.NoError(t, err)
+
+		sizes := []int{}
+		err = r.query.ExportOrders(ctx, model.OrderFilter{CustomerName: "alice"}, 2, func(orders []model.Order) error {
+			sizes = append(sizes, len(orders))
+			assert.Len(t, orders[0].Items, 1)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 2, 1}, sizes)
+
+		stop := errors.New("client gone")
+		err = r.query.ExportOrders(ctx, model.OrderFilter{}, 2, func(orders []model.Order) error {
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+	})
+
+	t.Run("rollback discards writes", func(t *testing.T) {
+		r := newRepos(t)
+		kept, err := r.command.CreateOrder(ctx, newOrder("alice", day, "A1"))
+		require.NoError(t, err)
+
+		failed := errors.New("failed")
+		err = r.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := r.command.CreateOrder(ctx, newOrder("bob", day, "B2")); err != nil {
+				return err
+			}
+			if err := r.command.DeleteOrder(ctx, kept.ID); err != nil {
+				return err
+			}
+			return failed
+		})
+		assert.ErrorIs(t, err, failed)
+
+		orders, err := r.query.GetOrders(ctx, model.OrderFilter{})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, kept.ID, orders[0].ID)
+		assert.Len(t, orders[0].Items, 1)
+	})
+
+	t.Run("tenants are isolated", func(t *testing.T) {
+		r := newRepos(t)
+		shopA := tenant.With(ctx, "shop-a")
+		shopB := tenant.With(ctx, "shop-b")
+		a, err := r.command.CreateOrder(shopA, newOrder("alice", day, "A1"))
+		require.NoError(t, err)
+		b, err := r.command.CreateOrder(shopB, newOrder("bob", day, "B2"))
+		require.NoError(t, err)
+
+		orders, err := r.query.GetOrders(shopA, model.OrderFilter{})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, a.ID, orders[0].ID)
+		assert.Len(t, orders[0].Items, 1)
+
+		order, err := r.query.GetOrdersByID(shopA, b.ID)
+		require.NoError(t, err)
+		assert.Zero(t, order.ID)
+		err = r.tx.WithinTx(shopA, func(ctx context.Context) error {
+			order, err = r.query.GetOrdersByIDForUpdate(ctx, b.ID)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Zero(t, order.ID)
+		items, err := r.query.GetItemsByOrderIDs(shopA, []uint64{a.ID, b.ID})
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, "A1", items[0].ItemCode)
+		summaries, err := r.query.GetOrderSummaries(shopB)
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, b.ID, summaries[0].OrderID)
+
+		// deleting the order of another tenant is a no-op
+		require.NoError(t, r.command.DeleteOrder(shopA, b.ID))
+		order, err = r.query.GetOrdersByID(shopB, b.ID)
+		require.NoError(t, err)
+		assert.Equal(t, b.ID, order.ID)
+
+		// a context without a tenant sees every tenant
+		orders, err = r.query.GetOrders(ctx, model.OrderFilter{})
+		require.NoError(t, err)
+		assert.Len(t, orders, 2)
+	})
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+type memoryOrderQueryImpl struct {
+	store *MemoryStore
+}
+
+func NewMemoryOrderQuery(store *MemoryStore) OrderQuery {
+	return &memoryOrderQueryImpl{store: store}
+}
+
+func (u *memoryOrderQueryImpl) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	orders := []model.Order{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		orders = d.findOrders(tenantOf(ctx), filter, filter.Limit)
+		return nil
+	})
+	return orders, err
+}
+
+func (u *memoryOrderQueryImpl) GetOrdersByCustomers(ctx context.Context, names []string, filter model.OrderFilter) ([]model.Order, error) {
+	orders := []model.Order{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		for _, name := range names {
+			filter.CustomerName = name
+			orders = append(orders, d.findOrders(tenantOf(ctx), filter, filter.Limit)...)
+		}
+		return nil
+	})
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders, err
+}
+
+func (u *memoryOrderQueryImpl) ExportOrders(ctx context.Context, filter model.OrderFilter, chunkSize int, fn func(orders []model.Order) error) error {
+	for {
+		var chunk []model.Order
+		if err := u.store.read(ctx, func(d *memoryData) error {
+			chunk = d.findOrders(tenantOf(ctx), filter, chunkSize)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+		// fn runs without the lock, so it may take its time
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		if len(chunk) < chunkSize {
+			return nil
+		}
+		filter.AfterID = chunk[len(chunk)-1].ID
+	}
+}
+
+func (u *memoryOrderQueryImpl) GetOrdersByID(ctx context.Context, id uint64) (model.Order, error) {
+	order := model.Order{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		if o, ok := d.orders[id]; ok && visible(ctx, o.TenantID) {
+			order = o
+		}
+		return nil
+	})
+	return order, err
+}
+
+// GetOrdersByIDForUpdate needs no row lock: a transaction holds the whole
+// store.
+func (u *memoryOrderQueryImpl) GetOrdersByIDForUpdate(ctx context.Context, id uint64) (model.Order, error) {
+	order := model.Order{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		if o, ok := d.orders[id]; ok && visible(ctx, o.TenantID) {
+			order = o
+			order.Items = d.orderItems(id)
+		}
+		return nil
+	})
+	return order, err
+}
+
+func (u *memoryOrderQueryImpl) GetItemsByOrderIDs(ctx context.Context, orderIDs []uint64) ([]model.Item, error) {
+	items := []model.Item{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		wanted := make(map[uint64]bool, len(orderIDs))
+		for _, id := range orderIDs {
+			wanted[id] = true
+		}
+		for _, item := range d.items {
+			if wanted[item.OrderID] && visible(ctx, item.TenantID) {
+				items = append(items, item)
+			}
+		}
+		return nil
+	})
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].OrderID != items[j].OrderID {
+			return items[i].OrderID < items[j].OrderID
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, err
+}
+
+func (u *memoryOrderQueryImpl) GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error) {
+	summaries := []model.OrderSummary{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		for _, s := range d.summaries {
+			if visible(ctx, s.TenantID) {
+				summaries = append(summaries, s)
+			}
+		}
+		return nil
+	})
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].OrderID < summaries[j].OrderID })
+	return summaries, err
+}
+
+// findOrders returns up to limit orders of tenantID matching filter, by
+// ascending ID, with their items. A limit of zero returns them all; an empty
+// tenantID, the orders of every tenant.
+func (d *memoryData) findOrders(tenantID string, filter model.OrderFilter, limit int) []model.Order {
+	orders := []model.Order{}
+	for _, order := range d.orders {
+		if (tenantID == "" || order.TenantID == tenantID) && matchOrder(order, filter) {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	if limit > 0 && len(orders) > limit {
+		orders = orders[:limit]
+	}
+	for i := range orders {
+		orders[i].Items = d.orderItems(orders[i].ID)
+	}
+	return orders
+}
+
+// matchOrder is the in-memory counterpart of filterOrders.
+func matchOrder(order model.Order, filter model.OrderFilter) bool {
+	switch {
+	case filter.CustomerName != "" && order.CustomerName != filter.CustomerName,
+		filter.Status != "" && order.Status != filter.Status,
+		!filter.OrderedFrom.IsZero() && order.OrderedAt.Before(filter.OrderedFrom),
+		!filter.OrderedTo.IsZero() && !order.OrderedAt.Before(filter.OrderedTo),
+		order.ID <= filter.AfterID:
+		return false
+	}
+	return true
+}
+
+type memoryOrderCommandImpl struct {
+	store *MemoryStore
+}
+
+func NewMemoryOrderCommand(store *MemoryStore) OrderCommand {
+	return &memoryOrderCommandImpl{store: store}
+}
+
+func (u *memoryOrderCommandImpl) CreateOrder(ctx context.Context, order model.Order) (model.Order, error) {
+	order.TenantID = tenantToStamp(ctx)
+	err := u.store.write(ctx, func(d *memoryData) error {
+		order = d.saveOrder(order)
+		return nil
+	})
+	if err != nil {
+		return model.Order{}, err
+	}
+	return order, nil
+}
+
+func (u *memoryOrderCommandImpl) CreateOrders(ctx context.Context, orders []model.Order) ([]model.Order, error) {
+	if len(orders) == 0 {
+		return orders, nil
+	}
+	err := u.store.write(ctx, func(d *memoryData) error {
+		for i := range orders {
+			orders[i].TenantID = tenantToStamp(ctx)
+			orders[i] = d.saveOrder(orders[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (u *memoryOrderCommandImpl) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
+	order.TenantID = tenantToStamp(ctx)
+	err := u.store.write(ctx, func(d *memoryData) error {
+		order = d.saveOrder(order)
+		d.refreshSummary(order.ID)
+		return nil
+	})
+	if err != nil {
+		return model.Order{}, err
+	}
+	return order, nil
+}
+
+func (u *memoryOrderCommandImpl) DeleteItems(ctx context.Context, orderID uint64, itemIDs []uint64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	return u.store.write(ctx, func(d *memoryData) error {
+		if o, ok := d.orders[orderID]; !ok || !visible(ctx, o.TenantID) {
+			return nil
+		}
+		for _, id := range itemIDs {
+			if item, ok := d.items[id]; ok && item.OrderID == orderID {
+				remove(d, d.items, id)
+			}
+		}
+		d.refreshSummary(orderID)
+		return nil
+	})
+}
+
+func (u *memoryOrderCommandImpl) DeleteOrder(ctx context.Context, id uint64) error {
+	return u.store.write(ctx, func(d *memoryData) error {
+		if o, ok := d.orders[id]; !ok || !visible(ctx, o.TenantID) {
+			return nil
+		}
+		remove(d, d.orders, id)
+		for itemID, item := range d.items {
+			if item.OrderID == id {
+				remove(d, d.items, itemID)
+			}
+		}
+		d.refreshSummary(id)
+		return nil
+	})
+}
+
+// saveOrder inserts or replaces an order and upserts its items, handing out
+// IDs to those that have none.
+func (d *memoryData) saveOrder(order model.Order) model.Order {
+	if order.ID == 0 {
+		order.ID = d.nextID("orders")
+	} else {
+		d.useID("orders", order.ID)
+	}
+	if order.Status == "" {
+		order.Status = model.OrderStatusPending
+	}
+	items := make([]model.Item, len(order.Items))
+	for i, item := range order.Items {
+		if item.ID == 0 {
+			item.ID = d.nextID("items")
+		} else {
+			d.useID("items", item.ID)
+		}
+		item.OrderID = order.ID
+		item.TenantID = order.TenantID
+		put(d, d.items, item.ID, item)
+		items[i] = item
+	}
+	stored := order
+	stored.Items = nil
+	put(d, d.orders, order.ID, stored)
+	d.refreshSummary(order.ID)
+	if order.Items != nil {
+		order.Items = items
+	}
+	return order
+}
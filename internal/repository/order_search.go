@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// OrderSearch finds orders by the words of their customer name, item codes
+// and item descriptions.
+type OrderSearch interface {
+	// SearchOrders returns up to limit orders containing every word of
+	// text, each word also matching as a prefix, ranked best first. Customer
+	// names outrank item codes, which outrank descriptions.
+	SearchOrders(ctx context.Context, text string, limit int) ([]model.OrderSearchHit, error)
+	// FuzzySearchOrders returns up to limit orders whose customer name, item
+	// code or description contains words spelled like text, most similar
+	// first.
+	FuzzySearchOrders(ctx context.Context, text string, limit int) ([]model.OrderSearchHit, error)
+}
+
+type orderSearchImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewOrderSearch(db infrastructure.GormDB) OrderSearch {
+	return &orderSearchImpl{db: db}
+}
+
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// prefixQuery turns free text into a tsquery that requires every word as a
+// prefix. Operators typed by the user are dropped rather than interpreted.
+func prefixQuery(text string) string {
+	words := searchWord.FindAllString(strings.ToLower(text), -1)
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+type searchRank struct {
+	OrderID uint64
+	Rank    float64
+}
+
+type searchFragment struct {
+	OrderID  uint64
+	ItemID   uint64
+	Field    string
+	Fragment string
+}
+
+func (u *orderSearchImpl) SearchOrders(ctx context.Context, text string, limit int) ([]model.OrderSearchHit, error) {
+	query := prefixQuery(text)
+	if query == "" {
+		return []model.OrderSearchHit{}, nil
+	}
+	db := conn(ctx, u.db).WithContext(ctx)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// raw reads end in Find rather than Scan, which row-level security
+	// would leave without a tenant
+	ranks := []searchRank{}
+	if err := db.Raw(`
+		WITH q AS (SELECT to_tsquery('simple', ?) AS query),
+		matches AS (
+			SELECT o.id AS order_id, ts_rank(o.search_vector, q.query) AS rank
+			FROM orders o, q WHERE o.search_vector @@ q.query AND (? = '' OR o.tenant_id = ?)
+			UNION ALL
+			SELECT i.order_id, ts_rank(i.search_vector, q.query)
+			FROM items i, q WHERE i.search_vector @@ q.query AND (? = '' OR i.tenant_id = ?)
+		)
+		SELECT order_id, MAX(rank) AS rank FROM matches
+		GROUP BY order_id
+		ORDER BY rank DESC, order_id
+		LIMIT ?
+	`, query, tenantID, tenantID, tenantID, tenantID, limit).Find(&ranks).Error; err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return []model.OrderSearchHit{}, nil
+	}
+
+	fragments := []searchFragment{}
+	if err := db.Raw(`
+		WITH q AS (SELECT to_tsquery('simple', ?) AS query)
+		SELECT o.id AS order_id, 0 AS item_id, 'customer_name' AS field,
+			ts_headline('simple', o.customer_name, q.query, ?) AS fragment
+		FROM orders o, q
+		WHERE o.id IN ? AND o.search_vector @@ q.query
+		UNION ALL
+		SELECT i.order_id, i.id, 'item_code', ts_headline('simple', i.item_code, q.query, ?)
+		FROM items i, q
+		WHERE i.order_id IN ? AND to_tsvector('simple', i.item_code) @@ q.query
+		UNION ALL
+		SELECT i.order_id, i.id, 'description', ts_headline('simple', i.description, q.query, ?)
+		FROM items i, q
+		WHERE i.order_id IN ? AND to_tsvector('simple', i.description) @@ q.query
+		ORDER BY order_id, item_id
+	`, query, headlineOptions, rankIDs(ranks), headlineOptions, rankIDs(ranks), headlineOptions, rankIDs(ranks)).
+		Find(&fragments).Error; err != nil {
+		return nil, err
+	}
+	return u.hits(ctx, ranks, fragments)
+}
+
+func (u *orderSearchImpl) FuzzySearchOrders(ctx context.Context, text string, limit int) ([]model.OrderSearchHit, error) {
+	db := conn(ctx, u.db).WithContext(ctx)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// <% compares text with the best matching words of each field, so a
+	// misspelled first name still finds "first last"
+	ranks := []searchRank{}
+	if err := db.Raw(`
+		WITH matches AS (
+			SELECT o.id AS order_id, word_similarity(?, o.customer_name) AS rank
+			FROM orders o WHERE ? <% o.customer_name AND (? = '' OR o.tenant_id = ?)
+			UNION ALL
+			SELECT i.order_id, word_similarity(?, i.item_code)
+			FROM items i WHERE ? <% i.item_code AND (? = '' OR i.tenant_id = ?)
+			UNION ALL
+			SELECT i.order_id, word_similarity(?, i.description)
+			FROM items i WHERE ? <% i.description AND (? = '' OR i.tenant_id = ?)
+		)
+		SELECT order_id, MAX(rank) AS rank FROM matches
+		GROUP BY order_id
+		ORDER BY rank DESC, order_id
+		LIMIT ?
+	`, text, text, tenantID, tenantID, text, text, tenantID, tenantID, text, text, tenantID, tenantID, limit).
+		Find(&ranks).Error; err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return []model.OrderSearchHit{}, nil
+	}
+
+	// only the fields of the orders that made the cut, best match first
+	fragments := []searchFragment{}
+	if err := db.Raw(`
+		SELECT order_id, item_id, field, fragment FROM (
+			SELECT o.id AS order_id, 0 AS item_id, 'customer_name' AS field, o.customer_name AS fragment,
+				word_similarity(?, o.customer_name) AS rank
+			FROM orders o WHERE o.id IN ? AND ? <% o.customer_name
+			UNION ALL
+			SELECT i.order_id, i.id, 'item_code', i.item_code, word_similarity(?, i.item_code)
+			FROM items i WHERE i.order_id IN ? AND ? <% i.item_code
+			UNION ALL
+			SELECT i.order_id, i.id, 'description', i.description, word_similarity(?, i.description)
+			FROM items i WHERE i.order_id IN ? AND ? <% i.description
+		) f
+		ORDER BY rank DESC, order_id, item_id
+	`, text, rankIDs(ranks), text, text, rankIDs(ranks), text, text, rankIDs(ranks), text).
+		Find(&fragments).Error; err != nil {
+		return nil, err
+	}
+	return u.hits(ctx, ranks, fragments)
+}
+
+// hits loads the ranked orders with their items and attaches the fragments.
+func (u *orderSearchImpl) hits(ctx context.Context, ranks []searchRank, fragments []searchFragment) ([]model.OrderSearchHit, error) {
+	db := conn(ctx, u.db)
+	orders := []model.Order{}
+	if err := db.
+		WithContext(ctx).
+		Table("orders").
+		Preload("Items").
+		Where("id IN ?", rankIDs(ranks)).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	byID := map[uint64]model.Order{}
+	for _, order := range orders {
+		byID[order.ID] = order
+	}
+
+	hits := []model.OrderSearchHit{}
+	index := map[uint64]int{}
+	for _, r := range ranks {
+		order, ok := byID[r.OrderID]
+		if !ok {
+			// deleted since it was ranked
+			continue
+		}
+		index[r.OrderID] = len(hits)
+		hits = append(hits, model.OrderSearchHit{Order: order, Rank: r.Rank, Highlights: []model.SearchHighlight{}})
+	}
+	for _, f := range fragments {
+		i, ok := index[f.OrderID]
+		if !ok {
+			continue
+		}
+		hits[i].Highlights = append(hits[i].Highlights, model.SearchHighlight{Field: f.Field, ItemID: f.ItemID, Fragment: f.Fragment})
+	}
+	return hits, nil
+}
+
+func rankIDs(ranks []searchRank) []uint64 {
+	ids := make([]uint64, 0, len(ranks))
+	for _, r := range ranks {
+		ids = append(ids, r.OrderID)
+	}
+	return ids
+}
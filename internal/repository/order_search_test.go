@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	assert.Equal(t, "jon:* & smi:*", prefixQuery("Jon  Smi"))
+	assert.Equal(t, "blue:* & t:* & shirt:*", prefixQuery("blue t-shirt"))
+	assert.Equal(t, "drop:* & table:*", prefixQuery("'drop' & | !table:*"))
+	assert.Equal(t, "", prefixQuery("&|!"))
+}
+
+func TestSearchOrders(t *testing.T) {
+	t.Run("no match", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`WITH q AS (SELECT to_tsquery('simple', $1) AS query)`)).
+			WithArgs("jon:*", "", "", "", "", 20).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "rank"}))
+
+		searchRepo := orderSearchImpl{db: postgresMock}
+		hits, err := searchRepo.SearchOrders(tenant.WithAllTenants(context.Background()), "jon", 20)
+		assert.Nil(t, err)
+		assert.Empty(t, hits)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success search orders", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_id, MAX(rank) AS rank FROM matches`)).
+			WithArgs("jon:*", "shop-a", "shop-a", "shop-a", "shop-a", 20).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "rank"}).AddRow(2, 0.6).AddRow(1, 0.1))
+		mock.ExpectQuery(regexp.QuoteMeta(`ts_headline('simple', o.customer_name, q.query, $2)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "item_id", "field", "fragment"}).
+				AddRow(1, 5, "description", "<mark>jon</mark>quil bulbs").
+				AddRow(2, 0, "customer_name", "<mark>Jon</mark> Smith"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders" WHERE id IN ($1,$2)`)).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "customer_name"}).AddRow(1, "Ann").AddRow(2, "Jon Smith"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items" WHERE "items"."order_id" IN ($1,$2)`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "description"}).AddRow(5, 1, "jonquil bulbs"))
+
+		searchRepo := orderSearchImpl{db: postgresMock}
+		hits, err := searchRepo.SearchOrders(tenant.With(context.Background(), "shop-a"), "jon", 20)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(hits))
+		assert.Equal(t, "Jon Smith", hits[0].Order.CustomerName)
+		assert.Equal(t, "<mark>Jon</mark> Smith", hits[0].Highlights[0].Fragment)
+		assert.Equal(t, uint64(5), hits[1].Highlights[0].ItemID)
+		assert.Equal(t, 1, len(hits[1].Order.Items))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFuzzySearchOrders(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM orders o WHERE $2 <% o.customer_name`)+`.*`+regexp.QuoteMeta(`LIMIT $13`)).
+		WithArgs("jonatan", "jonatan", "", "", "jonatan", "jonatan", "", "", "jonatan", "jonatan", "", "", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "rank"}).AddRow(3, 0.7))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM orders o WHERE o.id IN ($2) AND $3 <% o.customer_name`)).
+		WithArgs("jonatan", 3, "jonatan", "jonatan", 3, "jonatan", "jonatan", 3, "jonatan").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "item_id", "field", "fragment"}).
+			AddRow(3, 0, "customer_name", "Jonathan").
+			AddRow(3, 8, "item_code", "JONATH-1"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders" WHERE id IN ($1)`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer_name"}).AddRow(3, "Jonathan"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}).AddRow(8, 3))
+
+	searchRepo := orderSearchImpl{db: postgresMock}
+	hits, err := searchRepo.FuzzySearchOrders(tenant.WithAllTenants(context.Background()), "jonatan", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, 0.7, hits[0].Rank)
+	assert.Equal(t, 2, len(hits[0].Highlights))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
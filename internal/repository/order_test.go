@@ -36,7 +36,7 @@ func TestGetUsers(t *testing.T) {
 	t.Run("error get orders", func(t *testing.T) {
 		db, mock := newMockGorm()
 
-		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock := mocks.NewGormDB(t)
 		postgresMock.On("GetConnection").Return(db)
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
@@ -44,7 +44,7 @@ func TestGetUsers(t *testing.T) {
 		`)).WillReturnError(errors.New("some error"))
 
 		userRepo := orderQueryImpl{db: postgresMock}
-		res, err := userRepo.GetOrders(context.Background())
+		res, err := userRepo.GetOrders(context.Background(), model.OrderFilter{})
 		assert.NotNil(t, err)
 		assert.Equal(t, 0, len(res))
 	})
@@ -52,7 +52,7 @@ func TestGetUsers(t *testing.T) {
 	t.Run("success get orders", func(t *testing.T) {
 		db, mock := newMockGorm()
 
-		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock := mocks.NewGormDB(t)
 		postgresMock.On("GetConnection").Return(db)
 
 		orderRow := sqlmock.
@@ -72,49 +72,130 @@ func TestGetUsers(t *testing.T) {
 		`)).WithArgs(1).WillReturnRows(itemRow)
 
 		userRepo := orderQueryImpl{db: postgresMock}
-		res, err := userRepo.GetOrders(context.Background())
+		res, err := userRepo.GetOrders(context.Background(), model.OrderFilter{})
 		assert.Nil(t, err)
 		assert.Equal(t, 1, len(res))
 	})
 
-}
-
-func TestCreateOrder(t *testing.T) {
-	t.Run("error create order", func(t *testing.T) {
+	t.Run("success get a page of orders", func(t *testing.T) {
 		db, mock := newMockGorm()
 
-		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock := mocks.NewGormDB(t)
 		postgresMock.On("GetConnection").Return(db)
 
-		mock.ExpectExec(regexp.QuoteMeta(`
-			INSERT INTO "orders" VALUES ($1, $2, $3)
-		`)).WillReturnError(errors.New("some error"))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "orders" WHERE status = $1 AND id > $2 ORDER BY id LIMIT $3
+		`)).WithArgs("paid", 10, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
 		userRepo := orderQueryImpl{db: postgresMock}
-		order := model.Order{ID: 1, CustomerName: "test", OrderedAt: time.Now()}
-		res, err := userRepo.CreateOrder(context.Background(), order)
-		assert.NotNil(t, err)
-		assert.Equal(t, model.Order{}, res)
+		res, err := userRepo.GetOrders(context.Background(), model.OrderFilter{Status: "paid", AfterID: 10, Limit: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(res))
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 }
 
-func TestDeleteOrder(t *testing.T) {
-	t.Run("error deleting order", func(t *testing.T) {
+func TestGetOrderSummaries(t *testing.T) {
+	t.Run("success get order summaries", func(t *testing.T) {
 		db, mock := newMockGorm()
-		postgresMock := mocks.NewGormPostgres(t)
+
+		postgresMock := mocks.NewGormDB(t)
 		postgresMock.On("GetConnection").Return(db)
 
-		mock.ExpectBegin()
-		mock.ExpectExec(regexp.QuoteMeta(`
-			DELETE FROM "orders" WHERE "id" = $1
-		`)).WithArgs(123).WillReturnError(errors.New("some error"))
-		mock.ExpectRollback()
+		summaryRow := sqlmock.
+			NewRows([]string{"order_id", "customer_name", "ordered_at", "item_count", "total_quantity"}).
+			AddRow(1, "testing", time.Now(), 2, 5)
 
-		u := orderQueryImpl{db: postgresMock}
-		err := u.DeleteOrder(context.Background(), 123)
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "order_summaries" ORDER BY order_id
+		`)).WillReturnRows(summaryRow)
 
-		assert.NotNil(t, err)
+		orderRepo := orderQueryImpl{db: postgresMock}
+		res, err := orderRepo.GetOrderSummaries(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(res))
+		assert.Equal(t, uint64(5), res[0].TotalQuantity)
 	})
+}
+
+func TestGetItemsByOrderIDs(t *testing.T) {
+	t.Run("success get items of several orders", func(t *testing.T) {
+		db, mock := newMockGorm()
 
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		itemRow := sqlmock.
+			NewRows([]string{"id", "item_code", "order_id"}).
+			AddRow(1, "A1", 1).
+			AddRow(2, "B2", 3)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "items" WHERE order_id IN ($1,$2) ORDER BY order_id, id
+		`)).WithArgs(1, 3).WillReturnRows(itemRow)
+
+		orderRepo := orderQueryImpl{db: postgresMock}
+		res, err := orderRepo.GetItemsByOrderIDs(context.Background(), []uint64{1, 3})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(res))
+		assert.Equal(t, uint64(3), res[1].OrderID)
+	})
+}
+
+func TestExportOrders(t *testing.T) {
+	t.Run("success export orders in chunks", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "orders" WHERE status = $1 ORDER BY "orders"."id" LIMIT $2
+		`)).WithArgs("paid", 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "items" WHERE "items"."order_id" IN ($1,$2)
+		`)).WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}).AddRow(10, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "orders" WHERE status = $1 AND "orders"."id" > $2 ORDER BY "orders"."id" LIMIT $3
+		`)).WithArgs("paid", 2, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "items" WHERE "items"."order_id" = $1
+		`)).WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}))
+
+		repo := orderQueryImpl{db: postgresMock}
+		chunks := [][]uint64{}
+		err := repo.ExportOrders(context.Background(), model.OrderFilter{Status: "paid"}, 2, func(orders []model.Order) error {
+			ids := []uint64{}
+			for _, o := range orders {
+				ids = append(ids, o.ID)
+			}
+			chunks = append(chunks, ids)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, [][]uint64{{1, 2}, {3}}, chunks)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("callback error stops the export", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "orders"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM "items"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}))
+
+		repo := orderQueryImpl{db: postgresMock}
+		err := repo.ExportOrders(context.Background(), model.OrderFilter{}, 1, func(orders []model.Order) error {
+			return errors.New("client gone")
+		})
+		assert.EqualError(t, err, "client gone")
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
 }
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	// Append stores events. Call it with the context of the transaction that
+	// makes the change so events are written if and only if it commits.
+	Append(ctx context.Context, events ...event.Event) error
+	// GetPending returns unpublished events that are due at now, oldest
+	// first, skipping events queued behind an earlier event of the same
+	// order that is still waiting for a retry.
+	GetPending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error)
+	// GetSince returns published events with a sequence number greater than
+	// afterSequence, in the order they were published.
+	GetSince(ctx context.Context, afterSequence uint64, limit int) ([]model.OutboxEvent, error)
+	// MarkPublished records that the event was published and gives it the
+	// next sequence number, which it returns.
+	MarkPublished(ctx context.Context, id uint64, at time.Time) (uint64, error)
+	MarkFailed(ctx context.Context, id uint64, reason string, nextAttemptAt time.Time) error
+}
+
+type outboxRepositoryImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewOutboxRepository(db infrastructure.GormDB) OutboxRepository {
+	return &outboxRepositoryImpl{db: db}
+}
+
+func (u *outboxRepositoryImpl) Append(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	rows := make([]model.OutboxEvent, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, model.OutboxEvent{
+			AggregateID:   e.OrderID,
+			EventType:     string(e.Type),
+			Payload:       string(payload),
+			NextAttemptAt: e.OccurredAt,
+			CreatedAt:     e.OccurredAt,
+		})
+	}
+
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Table("outbox_events").
+		Create(&rows).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *outboxRepositoryImpl) GetPending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	db := conn(ctx, u.db)
+	events := []model.OutboxEvent{}
+	if err := db.
+		WithContext(ctx).
+		Table("outbox_events AS e").
+		Where("e.published_at IS NULL AND e.next_attempt_at <= ?", now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox_events p
+			WHERE p.aggregate_id = e.aggregate_id AND p.published_at IS NULL AND p.id < e.id AND p.next_attempt_at > ?
+		)`, now).
+		Order("e.id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (u *outboxRepositoryImpl) GetSince(ctx context.Context, afterSequence uint64, limit int) ([]model.OutboxEvent, error) {
+	db := conn(ctx, u.db)
+	events := []model.OutboxEvent{}
+	if err := db.
+		WithContext(ctx).
+		Table("outbox_events").
+		Where("sequence > ?", afterSequence).
+		Order("sequence").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (u *outboxRepositoryImpl) MarkPublished(ctx context.Context, id uint64, at time.Time) (uint64, error) {
+	db := conn(ctx, u.db)
+	var sequence uint64
+	if err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			// a single dispatcher publishes, so the next number is free; the
+			// unique index rejects it should a second one race for it
+			if err := tx.
+				Table("outbox_events").
+				Select("COALESCE(MAX(sequence), 0) + 1").
+				Scan(&sequence).Error; err != nil {
+				return err
+			}
+			return tx.
+				Table("outbox_events").
+				Where("id = ?", id).
+				Updates(map[string]any{
+					"published_at": at,
+					"sequence":     sequence,
+					"attempts":     gorm.Expr("attempts + 1"),
+					"last_error":   "",
+				}).Error
+		}); err != nil {
+		return 0, err
+	}
+	return sequence, nil
+}
+
+func (u *outboxRepositoryImpl) MarkFailed(ctx context.Context, id uint64, reason string, nextAttemptAt time.Time) error {
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Table("outbox_events").
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": nextAttemptAt,
+		}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+type memoryOutboxRepositoryImpl struct {
+	store *MemoryStore
+}
+
+func NewMemoryOutboxRepository(store *MemoryStore) OutboxRepository {
+	return &memoryOutboxRepositoryImpl{store: store}
+}
+
+func (u *memoryOutboxRepositoryImpl) Append(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return u.store.write(ctx, func(d *memoryData) error {
+		for _, e := range events {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			d.appendEvent(model.OutboxEvent{
+				ID:            d.nextID("outbox_events"),
+				AggregateID:   e.OrderID,
+				TenantID:      tenantToStamp(ctx),
+				EventType:     string(e.Type),
+				Payload:       string(payload),
+				NextAttemptAt: e.OccurredAt,
+				CreatedAt:     e.OccurredAt,
+			})
+		}
+		return nil
+	})
+}
+
+func (u *memoryOutboxRepositoryImpl) GetPending(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	events := []model.OutboxEvent{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		// orders with an earlier unpublished event that waits for a retry
+		blocked := map[uint64]bool{}
+		for _, e := range d.outbox {
+			if len(events) == limit {
+				break
+			}
+			if e.PublishedAt != nil || !visible(ctx, e.TenantID) {
+				continue
+			}
+			if !blocked[e.AggregateID] && !e.NextAttemptAt.After(now) {
+				events = append(events, e)
+			}
+			if e.NextAttemptAt.After(now) {
+				blocked[e.AggregateID] = true
+			}
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (u *memoryOutboxRepositoryImpl) GetSince(ctx context.Context, afterSequence uint64, limit int) ([]model.OutboxEvent, error) {
+	events := []model.OutboxEvent{}
+	err := u.store.read(ctx, func(d *memoryData) error {
+		for _, e := range d.outbox {
+			if e.Sequence != nil && *e.Sequence > afterSequence && visible(ctx, e.TenantID) {
+				events = append(events, e)
+			}
+		}
+		return nil
+	})
+	sort.Slice(events, func(i, j int) bool { return *events[i].Sequence < *events[j].Sequence })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, err
+}
+
+func (u *memoryOutboxRepositoryImpl) MarkPublished(ctx context.Context, id uint64, at time.Time) (uint64, error) {
+	var sequence uint64
+	err := u.updateEvent(ctx, id, func(d *memoryData, e *model.OutboxEvent) {
+		sequence = d.nextID("outbox_sequence")
+		e.PublishedAt = &at
+		e.Sequence = &sequence
+		e.Attempts++
+		e.LastError = ""
+	})
+	return sequence, err
+}
+
+func (u *memoryOutboxRepositoryImpl) MarkFailed(ctx context.Context, id uint64, reason string, nextAttemptAt time.Time) error {
+	return u.updateEvent(ctx, id, func(d *memoryData, e *model.OutboxEvent) {
+		e.Attempts++
+		e.LastError = reason
+		e.NextAttemptAt = nextAttemptAt
+	})
+}
+
+func (u *memoryOutboxRepositoryImpl) updateEvent(ctx context.Context, id uint64, fn func(d *memoryData, e *model.OutboxEvent)) error {
+	return u.store.write(ctx, func(d *memoryData) error {
+		d.updateEvent(id, func(e *model.OutboxEvent) { fn(d, e) })
+		return nil
+	})
+}
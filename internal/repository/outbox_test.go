@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendOutbox(t *testing.T) {
+	t.Run("error append events", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "outbox_events"
+		`)).WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		outboxRepo := outboxRepositoryImpl{db: postgresMock}
+		err := outboxRepo.Append(context.Background(), event.New(event.OrderCreated, model.Order{ID: 1}))
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success append events", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			INSERT INTO "outbox_events"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectCommit()
+
+		outboxRepo := outboxRepositoryImpl{db: postgresMock}
+		err := outboxRepo.Append(context.Background(),
+			event.New(event.OrderUpdated, model.Order{ID: 1}),
+			event.New(event.OrderStatusChanged, model.Order{ID: 1}),
+		)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetPendingOutbox(t *testing.T) {
+	t.Run("success get pending events", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		now := time.Now()
+		eventRow := sqlmock.
+			NewRows([]string{"id", "aggregate_id", "event_type", "payload"}).
+			AddRow(1, 1, "order.created", `{"type":"order.created","order_id":1}`)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT * FROM outbox_events AS e WHERE (e.published_at IS NULL AND e.next_attempt_at <= $1)
+		`)).WithArgs(now, now, 10).WillReturnRows(eventRow)
+
+		outboxRepo := outboxRepositoryImpl{db: postgresMock}
+		res, err := outboxRepo.GetPending(context.Background(), now, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(res))
+	})
+}
+
+func TestGetSinceOutbox(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		SELECT * FROM "outbox_events" WHERE sequence > $1 ORDER BY sequence LIMIT $2
+	`)).WithArgs(5, 10).WillReturnRows(sqlmock.
+		NewRows([]string{"id", "sequence", "event_type", "payload"}).
+		AddRow(3, 6, "order.created", `{"type":"order.created","order_id":1}`))
+
+	outboxRepo := outboxRepositoryImpl{db: postgresMock}
+	res, err := outboxRepo.GetSince(context.Background(), 5, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, uint64(6), *res[0].Sequence)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkPublishedOutbox(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	at := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(sequence), 0) + 1 FROM "outbox_events"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"next"}).AddRow(7))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox_events" SET "attempts"=attempts + 1,"last_error"=$1,"published_at"=$2,"sequence"=$3 WHERE id = $4`)).
+		WithArgs("", at, 7, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	outboxRepo := outboxRepositoryImpl{db: postgresMock}
+	sequence, err := outboxRepo.MarkPublished(context.Background(), 3, at)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), sequence)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepository interface {
+	GetPromotions(ctx context.Context) ([]model.Promotion, error)
+	GetPromotionByID(ctx context.Context, id uint64) (model.Promotion, error)
+	// GetPromotionByCodeForUpdate looks a promotion up by its normalized
+	// code and holds its row lock until the surrounding transaction ends,
+	// so the limits checked before a redemption cannot change under it.
+	GetPromotionByCodeForUpdate(ctx context.Context, code string) (model.Promotion, error)
+	CreatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error)
+	// UpdatePromotion saves every field of promo except its use count.
+	UpdatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error)
+	// CountCustomerRedemptions returns how many orders of customer used the
+	// promotion.
+	CountCustomerRedemptions(ctx context.Context, promotionID uint64, customer string) (uint64, error)
+	// Redeem records a redemption and counts it against the promotion. It
+	// returns false, recording nothing, when the promotion has no uses left.
+	Redeem(ctx context.Context, redemption model.PromotionRedemption) (bool, error)
+}
+
+type promotionRepositoryImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewPromotionRepository(db infrastructure.GormDB) PromotionRepository {
+	return &promotionRepositoryImpl{db: db}
+}
+
+func (u *promotionRepositoryImpl) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	db := conn(ctx, u.db)
+	promos := []model.Promotion{}
+	if err := db.
+		WithContext(ctx).
+		Table("promotions").
+		Order("id").
+		Find(&promos).Error; err != nil {
+		return nil, err
+	}
+	return promos, nil
+}
+
+func (u *promotionRepositoryImpl) GetPromotionByID(ctx context.Context, id uint64) (model.Promotion, error) {
+	db := conn(ctx, u.db)
+	promo := model.Promotion{}
+	if err := db.
+		WithContext(ctx).
+		Table("promotions").
+		Where("id = ?", id).
+		Find(&promo).Error; err != nil {
+		return model.Promotion{}, err
+	}
+	return promo, nil
+}
+
+func (u *promotionRepositoryImpl) GetPromotionByCodeForUpdate(ctx context.Context, code string) (model.Promotion, error) {
+	db := conn(ctx, u.db)
+	promo := model.Promotion{}
+	if err := db.
+		WithContext(ctx).
+		Table("promotions").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		Find(&promo).Error; err != nil {
+		return model.Promotion{}, err
+	}
+	return promo, nil
+}
+
+func (u *promotionRepositoryImpl) CreatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error) {
+	db := conn(ctx, u.db)
+	promo.Uses = 0
+	if err := db.
+		WithContext(ctx).
+		Table("promotions").
+		Create(&promo).Error; err != nil {
+		return model.Promotion{}, err
+	}
+	return promo, nil
+}
+
+func (u *promotionRepositoryImpl) UpdatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error) {
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Table("promotions").
+		Omit("uses", "created_at").
+		Save(&promo).Error; err != nil {
+		return model.Promotion{}, err
+	}
+	return u.GetPromotionByID(ctx, promo.ID)
+}
+
+func (u *promotionRepositoryImpl) CountCustomerRedemptions(ctx context.Context, promotionID uint64, customer string) (uint64, error) {
+	db := conn(ctx, u.db)
+	var count int64
+	if err := db.
+		WithContext(ctx).
+		Model(&model.PromotionRedemption{}).
+		Where("promotion_id = ? AND customer_name = ?", promotionID, customer).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return uint64(count), nil
+}
+
+func (u *promotionRepositoryImpl) Redeem(ctx context.Context, redemption model.PromotionRedemption) (bool, error) {
+	db := conn(ctx, u.db)
+	redeemed := false
+	err := db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			// the use is only counted while the limit allows it, which
+			// holds even for a caller that did not lock the promotion
+			res := tx.
+				Model(&model.Promotion{}).
+				Where("id = ? AND (max_uses = 0 OR uses < max_uses)", redemption.PromotionID).
+				Update("uses", gorm.Expr("uses + 1"))
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			if err := tx.Create(&redemption).Error; err != nil {
+				return err
+			}
+			redeemed = true
+			return nil
+		})
+	return redeemed, err
+}
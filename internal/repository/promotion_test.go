@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedeem(t *testing.T) {
+	redemption := model.PromotionRedemption{PromotionID: 3, CustomerName: "alice", OrderID: 9, Discount: 250}
+
+	t.Run("success redeem", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`
+			UPDATE "promotions" SET "uses"=uses + 1,"updated_at"=$1 WHERE id = $2 AND (max_uses = 0 OR uses < max_uses)
+		`)).WithArgs(sqlmock.AnyArg(), 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "promotion_redemptions"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		promotionRepo := promotionRepositoryImpl{db: postgresMock}
+		redeemed, err := promotionRepo.Redeem(context.Background(), redemption)
+		assert.Nil(t, err)
+		assert.True(t, redeemed)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("promotion used up", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "promotions" SET "uses"=uses + 1`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		promotionRepo := promotionRepositoryImpl{db: postgresMock}
+		redeemed, err := promotionRepo.Redeem(context.Background(), redemption)
+		assert.Nil(t, err)
+		assert.False(t, redeemed)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdatePromotion(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "promotions" SET "tenant_id"=$1,"code"=$2,"kind"=$3,"value"=$4,"min_order_amount"=$5,"item_codes"=$6,"max_uses"=$7,"max_uses_per_customer"=$8,"starts_at"=$9,"ends_at"=$10,"active"=$11,"updated_at"=$12 WHERE "id" = $13`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "promotions" WHERE id = $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "uses"}).AddRow(3, "SPRING10", 7))
+
+	promotionRepo := promotionRepositoryImpl{db: postgresMock}
+	promo, err := promotionRepo.UpdatePromotion(context.Background(), model.Promotion{ID: 3, Code: "SPRING10", Kind: model.PromotionFixed, Value: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), promo.Uses)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
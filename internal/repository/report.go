@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// ReportRepository aggregates the orders placed in [from, to). Order counts
+// come from the order_summaries read model; item codes from the items
+// themselves.
+type ReportRepository interface {
+	// OrderVolumes buckets orders by the calendar day, week or month they
+	// were placed in, in the IANA time zone tz. Periods without orders are
+	// left out.
+	OrderVolumes(ctx context.Context, interval, tz string, from, to time.Time) ([]model.OrderVolume, error)
+	// TopItems returns the limit item codes ordered in the largest
+	// quantities.
+	TopItems(ctx context.Context, from, to time.Time, limit int) ([]model.ItemVolume, error)
+	OrderSizes(ctx context.Context, from, to time.Time) (model.OrderSizeReport, error)
+	// CustomerOrders returns the limit customers with the most orders.
+	CustomerOrders(ctx context.Context, from, to time.Time, limit int) ([]model.CustomerOrders, error)
+}
+
+type reportRepositoryImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewReportRepository(db infrastructure.GormDB) ReportRepository {
+	return &reportRepositoryImpl{db: db}
+}
+
+func (u *reportRepositoryImpl) OrderVolumes(ctx context.Context, interval, tz string, from, to time.Time) ([]model.OrderVolume, error) {
+	db := conn(ctx, u.db)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	volumes := []model.OrderVolume{}
+	// raw reads end in Find rather than Scan, which row-level security
+	// would leave without a tenant. Truncate the local wall time, then turn
+	// the local start of the period back into an instant
+	if err := db.
+		WithContext(ctx).
+		Raw(`
+			SELECT date_trunc(?, s.ordered_at AT TIME ZONE ?) AT TIME ZONE ? AS period_start,
+				COUNT(*) AS orders,
+				COALESCE(SUM(s.item_count), 0) AS items,
+				COALESCE(SUM(s.total_quantity), 0) AS quantity
+			FROM order_summaries s
+			WHERE s.ordered_at >= ? AND s.ordered_at < ? AND (? = '' OR s.tenant_id = ?)
+			GROUP BY 1
+			ORDER BY 1
+		`, interval, tz, tz, from, to, tenantID, tenantID).
+		Find(&volumes).Error; err != nil {
+		return nil, err
+	}
+	return volumes, nil
+}
+
+func (u *reportRepositoryImpl) TopItems(ctx context.Context, from, to time.Time, limit int) ([]model.ItemVolume, error) {
+	db := conn(ctx, u.db)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := []model.ItemVolume{}
+	if err := db.
+		WithContext(ctx).
+		Raw(`
+			SELECT i.item_code, SUM(i.quantity) AS quantity, COUNT(DISTINCT i.order_id) AS orders
+			FROM items i
+			JOIN orders o ON o.id = i.order_id
+			WHERE o.ordered_at >= ? AND o.ordered_at < ? AND (? = '' OR o.tenant_id = ?)
+			GROUP BY i.item_code
+			ORDER BY quantity DESC, i.item_code
+			LIMIT ?
+		`, from, to, tenantID, tenantID, limit).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (u *reportRepositoryImpl) OrderSizes(ctx context.Context, from, to time.Time) (model.OrderSizeReport, error) {
+	db := conn(ctx, u.db)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return model.OrderSizeReport{}, err
+	}
+	rep := model.OrderSizeReport{}
+	if err := db.
+		WithContext(ctx).
+		Raw(`
+			SELECT COUNT(*) AS orders,
+				COALESCE(SUM(s.item_count), 0) AS items,
+				COALESCE(SUM(s.total_quantity), 0) AS quantity,
+				COALESCE(AVG(s.item_count), 0) AS average_items,
+				COALESCE(AVG(s.total_quantity), 0) AS average_quantity
+			FROM order_summaries s
+			WHERE s.ordered_at >= ? AND s.ordered_at < ? AND (? = '' OR s.tenant_id = ?)
+		`, from, to, tenantID, tenantID).
+		Find(&rep).Error; err != nil {
+		return model.OrderSizeReport{}, err
+	}
+	return rep, nil
+}
+
+func (u *reportRepositoryImpl) CustomerOrders(ctx context.Context, from, to time.Time, limit int) ([]model.CustomerOrders, error) {
+	db := conn(ctx, u.db)
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	customers := []model.CustomerOrders{}
+	if err := db.
+		WithContext(ctx).
+		Raw(`
+			SELECT s.customer_name,
+				COUNT(*) AS orders,
+				COALESCE(SUM(s.total_quantity), 0) AS quantity,
+				MIN(s.ordered_at) AS first_ordered_at,
+				MAX(s.ordered_at) AS last_ordered_at
+			FROM order_summaries s
+			WHERE s.ordered_at >= ? AND s.ordered_at < ? AND (? = '' OR s.tenant_id = ?)
+			GROUP BY s.customer_name
+			ORDER BY orders DESC, s.customer_name
+			LIMIT ?
+		`, from, to, tenantID, tenantID, limit).
+		Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderVolumes(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	start := time.Date(2024, 3, 3, 17, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		SELECT date_trunc($1, s.ordered_at AT TIME ZONE $2) AT TIME ZONE $3 AS period_start
+	`)).WithArgs("week", "Asia/Jakarta", "Asia/Jakarta", from, to, "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"period_start", "orders", "items", "quantity"}).AddRow(start, 2, 3, 7))
+
+	reportRepo := reportRepositoryImpl{db: postgresMock}
+	res, err := reportRepo.OrderVolumes(tenant.WithAllTenants(context.Background()), "week", "Asia/Jakarta", from, to)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, uint64(7), res[0].Quantity)
+
+	// a context with neither a tenant nor the mark never reaches the database
+	_, err = reportRepo.OrderVolumes(context.Background(), "week", "Asia/Jakarta", from, to)
+	assert.ErrorIs(t, err, tenant.ErrUnscoped)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestTopItems(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		SELECT i.item_code, SUM(i.quantity) AS quantity, COUNT(DISTINCT i.order_id) AS orders
+	`)).WithArgs(from, to, "shop-a", "shop-a", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"item_code", "quantity", "orders"}).AddRow("SKU-1", 40, 9).AddRow("SKU-2", 12, 4))
+
+	reportRepo := reportRepositoryImpl{db: postgresMock}
+	res, err := reportRepo.TopItems(tenant.With(context.Background(), "shop-a"), from, to, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, "SKU-1", res[0].ItemCode)
+	assert.Equal(t, uint64(9), res[0].Orders)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
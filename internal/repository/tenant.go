@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// tenantOf returns the tenant of ctx, for the memory store and the keys of
+// the order cache. An empty tenant matches every row. The raw SQL the tenant
+// scope of the database leaves alone uses tenant.Scope instead, which
+// refuses a context that is not marked for every tenant.
+func tenantOf(ctx context.Context) string {
+	id, _ := tenant.FromContext(ctx)
+	return id
+}
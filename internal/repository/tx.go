@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"gorm.io/gorm"
+)
+
+// TxManager runs a unit of work in a database transaction. Repository calls
+// made with the context handed to fn participate in that transaction.
+type TxManager interface {
+	// WithinTx commits when fn returns nil and rolls back when it returns an
+	// error or panics. Nested calls run inside a savepoint of the outer
+	// transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type commitHooksKey struct{}
+
+// commitHooks are run once the outermost transaction commits.
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+type txManagerImpl struct {
+	db infrastructure.GormDB
+}
+
+func NewTxManager(db infrastructure.GormDB) TxManager {
+	return &txManagerImpl{db: db}
+}
+
+func (t *txManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	hooks, nested := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !nested {
+		hooks = &commitHooks{}
+		ctx = context.WithValue(ctx, commitHooksKey{}, hooks)
+	}
+	err := conn(ctx, t.db).
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	if err != nil || nested {
+		return err
+	}
+	for _, fn := range hooks.fns {
+		fn()
+	}
+	return nil
+}
+
+// afterCommit runs fn once the transaction bound to ctx has committed, or
+// right away when there is none. Hooks of a transaction that rolls back are
+// dropped; hooks added inside a savepoint that rolls back still run when
+// the outer transaction commits.
+func afterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !ok {
+		fn()
+		return
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok || ctx.Value(memoryTxKey{}) != nil
+}
+
+// conn returns the transaction bound to ctx by WithinTx, or the shared
+// connection when there is none.
+func conn(ctx context.Context, db infrastructure.GormDB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.GetConnection()
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinTx(t *testing.T) {
+	t.Run("commit on success", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db).Once()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "customer_name", "ordered_at"}))
+		mock.ExpectCommit()
+
+		txManager := NewTxManager(postgresMock)
+		orderRepo := orderQueryImpl{db: postgresMock}
+		err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+			_, err := orderRepo.GetOrders(ctx, model.OrderFilter{})
+			return err
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		someErr := errors.New("some error")
+		err := NewTxManager(postgresMock).WithinTx(context.Background(), func(ctx context.Context) error {
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			NewTxManager(postgresMock).WithinTx(context.Background(), func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nested rolls back to savepoint", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db).Once()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT sp`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		txManager := NewTxManager(postgresMock)
+		err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+			inner := txManager.WithinTx(ctx, func(ctx context.Context) error {
+				return errors.New("inner failed")
+			})
+			assert.NotNil(t, inner)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/secretbox"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	GetSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetSubscriptionByID(ctx context.Context, id uint64) (model.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uint64) error
+	// RecordSubscriptionResult resets the failure streak of a subscription on
+	// success. On failure it extends the streak and deactivates the
+	// subscription once the streak reaches disableAfter.
+	RecordSubscriptionResult(ctx context.Context, id uint64, success bool, disableAfter int, at time.Time) error
+
+	// CreateDeliveries queues deliveries, skipping ones whose DedupKey is
+	// already queued.
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	// GetDueDeliveries returns pending deliveries of active subscriptions
+	// that are due at now, with their subscription loaded.
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, subscriptionID uint64) ([]model.WebhookDelivery, error)
+	GetDeliveryByID(ctx context.Context, subscriptionID, id uint64) (model.WebhookDelivery, error)
+	// RecordAttempt saves the new state of delivery along with the attempt
+	// that produced it.
+	RecordAttempt(ctx context.Context, delivery model.WebhookDelivery, attempt model.WebhookDeliveryAttempt) error
+}
+
+type webhookRepositoryImpl struct {
+	db infrastructure.GormDB
+	// secrets seals the signing secrets of subscriptions; they are stored in
+	// plaintext when it is nil.
+	secrets *secretbox.Box
+}
+
+// NewWebhookRepository returns a WebhookRepository that encrypts the signing
+// secrets of subscriptions with secrets, when not nil. The subscriptions it
+// returns carry their secrets in plaintext.
+func NewWebhookRepository(db infrastructure.GormDB, secrets *secretbox.Box) WebhookRepository {
+	return &webhookRepositoryImpl{db: db, secrets: secrets}
+}
+
+func (u *webhookRepositoryImpl) GetSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	db := conn(ctx, u.db)
+	subs := []model.WebhookSubscription{}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_subscriptions").
+		Order("id").
+		Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if err := u.openSecret(&subs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
+func (u *webhookRepositoryImpl) GetActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	db := conn(ctx, u.db)
+	subs := []model.WebhookSubscription{}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_subscriptions").
+		Where("active = ?", true).
+		Order("id").
+		Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		if err := u.openSecret(&subs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return subs, nil
+}
+
+func (u *webhookRepositoryImpl) GetSubscriptionByID(ctx context.Context, id uint64) (model.WebhookSubscription, error) {
+	db := conn(ctx, u.db)
+	sub := model.WebhookSubscription{}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_subscriptions").
+		Where("id = ?", id).
+		Find(&sub).Error; err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if err := u.openSecret(&sub); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	return sub, nil
+}
+
+func (u *webhookRepositoryImpl) CreateSubscription(ctx context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error) {
+	secret := sub.Secret
+	if err := u.sealSecret(&sub); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_subscriptions").
+		Create(&sub).Error; err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	sub.Secret = secret
+	return sub, nil
+}
+
+func (u *webhookRepositoryImpl) UpdateSubscription(ctx context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error) {
+	secret := sub.Secret
+	if err := u.sealSecret(&sub); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_subscriptions").
+		Save(&sub).Error; err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	sub.Secret = secret
+	return sub, nil
+}
+
+func (u *webhookRepositoryImpl) DeleteSubscription(ctx context.Context, id uint64) error {
+	db := conn(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			deliveries := tx.
+				Table("webhook_deliveries").
+				Select("id").
+				Where("subscription_id = ?", id)
+			if err := tx.
+				Where("delivery_id IN (?)", deliveries).
+				Delete(&model.WebhookDeliveryAttempt{}).Error; err != nil {
+				return err
+			}
+			if err := tx.
+				Where("subscription_id = ?", id).
+				Delete(&model.WebhookDelivery{}).Error; err != nil {
+				return err
+			}
+			return tx.
+				Where("id = ?", id).
+				Delete(&model.WebhookSubscription{}).Error
+		})
+}
+
+func (u *webhookRepositoryImpl) RecordSubscriptionResult(ctx context.Context, id uint64, success bool, disableAfter int, at time.Time) error {
+	db := conn(ctx, u.db)
+	updates := map[string]any{"consecutive_failures": 0}
+	if !success {
+		updates = map[string]any{
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"active":               gorm.Expr("CASE WHEN consecutive_failures + 1 >= ? THEN ? ELSE active END", disableAfter, false),
+			"disabled_at":          gorm.Expr("CASE WHEN consecutive_failures + 1 >= ? THEN ? ELSE disabled_at END", disableAfter, at),
+		}
+	}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_subscriptions").
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *webhookRepositoryImpl) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	db := conn(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_deliveries").
+		Omit("Subscription").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&deliveries).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *webhookRepositoryImpl) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	db := conn(ctx, u.db)
+	deliveries := []model.WebhookDelivery{}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_deliveries").
+		Joins("JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_deliveries.subscription_id").
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", model.DeliveryStatusPending, now).
+		Where("webhook_subscriptions.active = ?", true).
+		Preload("Subscription").
+		Order("webhook_deliveries.id").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	for i := range deliveries {
+		if err := u.openSecret(&deliveries[i].Subscription); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+func (u *webhookRepositoryImpl) GetDeliveries(ctx context.Context, subscriptionID uint64) ([]model.WebhookDelivery, error) {
+	db := conn(ctx, u.db)
+	deliveries := []model.WebhookDelivery{}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_deliveries").
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (u *webhookRepositoryImpl) GetDeliveryByID(ctx context.Context, subscriptionID, id uint64) (model.WebhookDelivery, error) {
+	db := conn(ctx, u.db)
+	delivery := model.WebhookDelivery{}
+	if err := db.
+		WithContext(ctx).
+		Table("webhook_deliveries").
+		Where("id = ? AND subscription_id = ?", id, subscriptionID).
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempt")
+		}).
+		Find(&delivery).Error; err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (u *webhookRepositoryImpl) RecordAttempt(ctx context.Context, delivery model.WebhookDelivery, attempt model.WebhookDeliveryAttempt) error {
+	db := conn(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Transaction(func(tx *gorm.DB) error {
+			if err := tx.
+				Table("webhook_deliveries").
+				Where("id = ?", delivery.ID).
+				Updates(map[string]any{
+					"status":          delivery.Status,
+					"attempts":        delivery.Attempts,
+					"response_code":   delivery.ResponseCode,
+					"last_error":      delivery.LastError,
+					"next_attempt_at": delivery.NextAttemptAt,
+					"delivered_at":    delivery.DeliveredAt,
+				}).Error; err != nil {
+				return err
+			}
+			attempt.DeliveryID = delivery.ID
+			return tx.Create(&attempt).Error
+		})
+}
+
+func (u *webhookRepositoryImpl) sealSecret(sub *model.WebhookSubscription) error {
+	if u.secrets == nil || sub.Secret == "" {
+		return nil
+	}
+	sealed, err := u.secrets.Seal(sub.Secret)
+	if err != nil {
+		return err
+	}
+	sub.Secret = sealed
+	return nil
+}
+
+// openSecret decrypts the secret of a stored subscription. Secrets stored
+// before encryption was turned on are read as they are.
+func (u *webhookRepositoryImpl) openSecret(sub *model.WebhookSubscription) error {
+	if u.secrets == nil || sub.Secret == "" {
+		return nil
+	}
+	secret, err := u.secrets.Open(sub.Secret)
+	if err != nil {
+		return fmt.Errorf("secret of webhook %d: %w", sub.ID, err)
+	}
+	sub.Secret = secret
+	return nil
+}
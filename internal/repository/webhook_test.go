@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/MidnightHelix/assignment-2/internal/infrastructure/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/secretbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSecretBox(t *testing.T) *secretbox.Box {
+	box, err := secretbox.New(bytes.Repeat([]byte{7}, secretbox.KeySize))
+	assert.Nil(t, err)
+	return box
+}
+
+// sealedSecret matches a secret sealed by box.
+type sealedSecret struct {
+	box    *secretbox.Box
+	secret string
+}
+
+func (s sealedSecret) Match(v driver.Value) bool {
+	sealed, ok := v.(string)
+	if !ok || sealed == s.secret {
+		return false
+	}
+	opened, err := s.box.Open(sealed)
+	return err == nil && opened == s.secret
+}
+
+func TestCreateSubscription(t *testing.T) {
+	box := newTestSecretBox(t)
+
+	t.Run("success create subscription with sealed secret", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "webhook_subscriptions"`)).
+			WithArgs("default", "https://partner.example.com/hooks", sqlmock.AnyArg(), sealedSecret{box: box, secret: "s3cret"}, true, 0, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		webhookRepo := webhookRepositoryImpl{db: postgresMock, secrets: box}
+		sub, err := webhookRepo.CreateSubscription(context.Background(), model.WebhookSubscription{
+			URL:    "https://partner.example.com/hooks",
+			Secret: "s3cret",
+			Active: true,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), sub.ID)
+		assert.Equal(t, "s3cret", sub.Secret)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error create subscription", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "webhook_subscriptions"`)).
+			WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		webhookRepo := webhookRepositoryImpl{db: postgresMock, secrets: box}
+		_, err := webhookRepo.CreateSubscription(context.Background(), model.WebhookSubscription{URL: "https://partner.example.com/hooks"})
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetActiveSubscriptions(t *testing.T) {
+	box := newTestSecretBox(t)
+	sealed, err := box.Seal("s3cret")
+	assert.Nil(t, err)
+
+	t.Run("success get subscriptions with opened secrets", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_subscriptions" WHERE active = $1 ORDER BY id`)).
+			WithArgs(true).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "active"}).
+				AddRow(1, "https://a.example.com", sealed, true).
+				AddRow(2, "https://b.example.com", "stored-before-encryption", true))
+
+		webhookRepo := webhookRepositoryImpl{db: postgresMock, secrets: box}
+		subs, err := webhookRepo.GetActiveSubscriptions(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(subs))
+		assert.Equal(t, "s3cret", subs[0].Secret)
+		assert.Equal(t, "stored-before-encryption", subs[1].Secret)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("secret sealed with another key", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		other, err := secretbox.New(bytes.Repeat([]byte{8}, secretbox.KeySize))
+		assert.Nil(t, err)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_subscriptions"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "secret"}).AddRow(1, sealed))
+
+		webhookRepo := webhookRepositoryImpl{db: postgresMock, secrets: other}
+		_, err = webhookRepo.GetActiveSubscriptions(context.Background())
+		assert.True(t, errors.Is(err, secretbox.ErrCorrupt))
+	})
+}
+
+func TestGetDueDeliveries(t *testing.T) {
+	box := newTestSecretBox(t)
+	sealed, err := box.Seal("s3cret")
+	assert.Nil(t, err)
+	now := time.Now()
+
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "webhook_deliveries"."id"`)).
+		WithArgs(model.DeliveryStatusPending, now, true, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "status"}).AddRow(5, 1, model.DeliveryStatusPending))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "webhook_subscriptions" WHERE "webhook_subscriptions"."id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret"}).AddRow(1, "https://a.example.com", sealed))
+
+	webhookRepo := webhookRepositoryImpl{db: postgresMock, secrets: box}
+	deliveries, err := webhookRepo.GetDueDeliveries(context.Background(), now, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(deliveries))
+	assert.Equal(t, "s3cret", deliveries[0].Subscription.Secret)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordSubscriptionResult(t *testing.T) {
+	at := time.Now()
+
+	t.Run("success resets the failure streak", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "webhook_subscriptions" SET "consecutive_failures"=$1 WHERE id = $2`)).
+			WithArgs(0, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		webhookRepo := webhookRepositoryImpl{db: postgresMock}
+		assert.Nil(t, webhookRepo.RecordSubscriptionResult(context.Background(), 1, true, 5, at))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failure extends the streak and may disable", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		postgresMock := mocks.NewGormDB(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "webhook_subscriptions" SET "active"=CASE WHEN consecutive_failures + 1 >= $1 THEN $2 ELSE active END,"consecutive_failures"=consecutive_failures + 1,"disabled_at"=CASE WHEN consecutive_failures + 1 >= $3 THEN $4 ELSE disabled_at END WHERE id = $5`)).
+			WithArgs(5, false, 5, at, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		webhookRepo := webhookRepositoryImpl{db: postgresMock}
+		assert.Nil(t, webhookRepo.RecordSubscriptionResult(context.Background(), 1, false, 5, at))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateDeliveries(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	key := "1:42"
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "webhook_deliveries"`) + `.*` + regexp.QuoteMeta(`ON CONFLICT DO NOTHING`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	webhookRepo := webhookRepositoryImpl{db: postgresMock}
+	err := webhookRepo.CreateDeliveries(context.Background(), []model.WebhookDelivery{
+		{SubscriptionID: 1, EventID: 42, EventType: "order.created", DedupKey: &key, Status: model.DeliveryStatusPending},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	db, mock := newMockGorm()
+
+	postgresMock := mocks.NewGormDB(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "webhook_delivery_attempts" WHERE delivery_id IN (SELECT id FROM "webhook_deliveries" WHERE subscription_id = $1)`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "webhook_deliveries" WHERE subscription_id = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "webhook_subscriptions" WHERE id = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	webhookRepo := webhookRepositoryImpl{db: postgresMock}
+	assert.Nil(t, webhookRepo.DeleteSubscription(context.Background(), 1))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
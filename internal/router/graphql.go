@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLRouter interface {
+	Mount()
+}
+
+type graphQLRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.GraphQLHandler
+}
+
+func NewGraphQLRouter(v *gin.RouterGroup, handler handler.GraphQLHandler) GraphQLRouter {
+	return &graphQLRouterImpl{v: v, handler: handler}
+}
+
+func (g *graphQLRouterImpl) Mount() {
+	// /graphql
+	g.v.GET("", g.handler.Query)
+	g.v.POST("", g.handler.Query)
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type JobRouter interface {
+	Mount()
+}
+
+type jobRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.JobHandler
+}
+
+func NewJobRouter(v *gin.RouterGroup, handler handler.JobHandler) JobRouter {
+	return &jobRouterImpl{v: v, handler: handler}
+}
+
+func (j *jobRouterImpl) Mount() {
+	// /jobs
+	j.v.POST("", j.handler.CreateJob)
+	// /jobs/:id
+	j.v.GET("/:id", j.handler.GetJobByID)
+	j.v.GET("/:id/result", j.handler.GetJobResult)
+}
@@ -25,8 +25,13 @@ func (o *orderRouterImpl) Mount() {
 
 	// /users
 	o.v.GET("", o.handler.GetOrders)
+	o.v.GET("/summaries", o.handler.GetOrderSummaries)
+	o.v.GET("/export", o.handler.ExportOrders)
 	// /users/:id
+	o.v.GET("/:id", o.handler.GetOrdersByID)
 	o.v.PUT("/:id", o.handler.UpdateOrder)
+	o.v.PATCH("/:id", o.handler.PatchOrder)
+	o.v.GET("/:id/history", o.handler.GetOrderHistory)
 
 	o.v.DELETE("/:id", o.handler.DeleteOrder)
 }
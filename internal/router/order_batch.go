@@ -0,0 +1,44 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderBatchRouter interface {
+	Mount()
+}
+
+type orderBatchRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.OrderHandler
+}
+
+// NewOrderBatchRouter mounts the /orders:batch routes on v, the group that
+// contains /orders.
+func NewOrderBatchRouter(v *gin.RouterGroup, handler handler.OrderHandler) OrderBatchRouter {
+	return &orderBatchRouterImpl{v: v, handler: handler}
+}
+
+func (o *orderBatchRouterImpl) Mount() {
+	// gin has no literal colons in paths, so /orders:batch is matched as a
+	// parameter that follows /orders and holds ":batch"
+	o.v.POST("/orders:action", customMethod("batch", o.handler.CreateOrders))
+	o.v.PATCH("/orders:action", customMethod("batch", o.handler.PatchOrders))
+	o.v.DELETE("/orders:action", customMethod("batch", o.handler.DeleteOrders))
+}
+
+// customMethod serves h only for the given custom method of a
+// /resource:method route.
+func customMethod(name string, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Param("action") != ":"+name {
+			ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "Not Found"})
+			return
+		}
+		h(ctx)
+	}
+}
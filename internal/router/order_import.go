@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderImportRouter interface {
+	Mount()
+}
+
+type orderImportRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.OrderImportHandler
+}
+
+func NewOrderImportRouter(v *gin.RouterGroup, handler handler.OrderImportHandler) OrderImportRouter {
+	return &orderImportRouterImpl{v: v, handler: handler}
+}
+
+func (o *orderImportRouterImpl) Mount() {
+	// /orders/import
+	o.v.POST("/import", o.handler.ImportOrders)
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderSearchRouter interface {
+	Mount()
+}
+
+type orderSearchRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.OrderSearchHandler
+}
+
+func NewOrderSearchRouter(v *gin.RouterGroup, handler handler.OrderSearchHandler) OrderSearchRouter {
+	return &orderSearchRouterImpl{v: v, handler: handler}
+}
+
+func (o *orderSearchRouterImpl) Mount() {
+	// /orders/search
+	o.v.GET("/search", o.handler.SearchOrders)
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderSocketRouter interface {
+	Mount()
+}
+
+type orderSocketRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.OrderSocketHandler
+}
+
+func NewOrderSocketRouter(v *gin.RouterGroup, handler handler.OrderSocketHandler) OrderSocketRouter {
+	return &orderSocketRouterImpl{v: v, handler: handler}
+}
+
+func (o *orderSocketRouterImpl) Mount() {
+	// /ws
+	o.v.GET("/ws", o.handler.ServeWS)
+}
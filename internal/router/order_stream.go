@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderStreamRouter interface {
+	Mount()
+}
+
+type orderStreamRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.OrderStreamHandler
+}
+
+func NewOrderStreamRouter(v *gin.RouterGroup, handler handler.OrderStreamHandler) OrderStreamRouter {
+	return &orderStreamRouterImpl{v: v, handler: handler}
+}
+
+func (o *orderStreamRouterImpl) Mount() {
+	// /orders/stream
+	o.v.GET("/stream", o.handler.StreamOrders)
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionRouter interface {
+	Mount()
+}
+
+type promotionRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.PromotionHandler
+}
+
+func NewPromotionRouter(v *gin.RouterGroup, handler handler.PromotionHandler) PromotionRouter {
+	return &promotionRouterImpl{v: v, handler: handler}
+}
+
+func (p *promotionRouterImpl) Mount() {
+	// /promotions
+	p.v.POST("", p.handler.CreatePromotion)
+	p.v.GET("", p.handler.GetPromotions)
+	// /promotions/:id
+	p.v.GET("/:id", p.handler.GetPromotionByID)
+	p.v.PUT("/:id", p.handler.UpdatePromotion)
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportRouter interface {
+	Mount()
+}
+
+type reportRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.ReportHandler
+}
+
+func NewReportRouter(v *gin.RouterGroup, handler handler.ReportHandler) ReportRouter {
+	return &reportRouterImpl{v: v, handler: handler}
+}
+
+func (r *reportRouterImpl) Mount() {
+	// /reports
+	r.v.GET("/orders", r.handler.GetOrderVolumes)
+	r.v.GET("/top-items", r.handler.GetTopItems)
+	r.v.GET("/items-per-order", r.handler.GetOrderSizes)
+	r.v.GET("/customers", r.handler.GetCustomerOrders)
+}
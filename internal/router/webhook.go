@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/MidnightHelix/assignment-2/internal/handler"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookRouter interface {
+	Mount()
+}
+
+type webhookRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.WebhookHandler
+}
+
+func NewWebhookRouter(v *gin.RouterGroup, handler handler.WebhookHandler) WebhookRouter {
+	return &webhookRouterImpl{v: v, handler: handler}
+}
+
+func (w *webhookRouterImpl) Mount() {
+	// /webhooks
+	w.v.POST("", w.handler.CreateWebhook)
+	w.v.GET("", w.handler.GetWebhooks)
+	// /webhooks/:id
+	w.v.GET("/:id", w.handler.GetWebhookByID)
+	w.v.PUT("/:id", w.handler.UpdateWebhook)
+	w.v.DELETE("/:id", w.handler.DeleteWebhook)
+	// /webhooks/:id/deliveries
+	w.v.GET("/:id/deliveries", w.handler.GetDeliveries)
+	w.v.GET("/:id/deliveries/:delivery_id", w.handler.GetDeliveryByID)
+	w.v.POST("/:id/deliveries/:delivery_id/redeliver", w.handler.Redeliver)
+}
@@ -0,0 +1,85 @@
+// Package secretbox encrypts secrets the application has to read back, such
+// as the signing secrets of webhook subscriptions, before they are stored.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks sealed values, telling them apart from the plaintext stored
+// before encryption was turned on.
+const prefix = "enc:v1:"
+
+// KeySize is the length of the key: AES-256.
+const KeySize = 32
+
+var ErrCorrupt = errors.New("sealed value is corrupt or was sealed with another key")
+
+// Box seals values with AES-GCM.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New returns a Box for a key of KeySize bytes.
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// ParseKey decodes a base64 key, the way keys are configured.
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with a fresh nonce. Sealing the same value twice
+// gives different results.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value returned by Seal. Values without the mark of a
+// sealed value were stored in plaintext and are returned as they are.
+func (b *Box) Open(value string) (string, error) {
+	encoded, sealed := strings.CutPrefix(value, prefix)
+	if !sealed {
+		return value, nil
+	}
+	data, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrCorrupt
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrCorrupt
+	}
+	return string(plaintext), nil
+}
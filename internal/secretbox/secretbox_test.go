@@ -0,0 +1,50 @@
+package secretbox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBox(t *testing.T) {
+	box, err := New(bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("s3cret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, prefix))
+	assert.NotContains(t, sealed, "s3cret")
+	again, err := box.Seal("s3cret")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own nonce")
+
+	opened, err := box.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", opened)
+
+	opened, err = box.Open("stored-before-encryption")
+	require.NoError(t, err)
+	assert.Equal(t, "stored-before-encryption", opened)
+
+	other, err := New(bytes.Repeat([]byte{2}, KeySize))
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.Equal(t, ErrCorrupt, err)
+	_, err = box.Open(prefix + "not base64!")
+	assert.Equal(t, ErrCorrupt, err)
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)))
+	require.NoError(t, err)
+	assert.Len(t, key, KeySize)
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.EqualError(t, err, "key must be 32 bytes, got 5")
+	_, err = ParseKey("%%%")
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/requestid"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// anonymousActor is recorded for changes made without authentication, such
+// as when API keys are disabled or orders are imported from the command
+// line.
+const anonymousActor = "anonymous"
+
+// newAuditEntry describes a change of an order made on behalf of the caller
+// in ctx. Before is nil for a create and after for a delete.
+func newAuditEntry(ctx context.Context, op string, before, after *model.Order) (model.AuditEntry, error) {
+	entry := model.AuditEntry{
+		Operation: op,
+		Actor:     anonymousActor,
+		RequestID: requestid.FromContext(ctx),
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		entry.Actor = p.Name
+	}
+
+	var err error
+	if before != nil {
+		entry.OrderID = before.ID
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return model.AuditEntry{}, err
+		}
+	}
+	if after != nil {
+		entry.OrderID = after.ID
+		if entry.After, err = json.Marshal(after); err != nil {
+			return model.AuditEntry{}, err
+		}
+	}
+
+	switch {
+	case before == nil:
+		entry.Changes = entry.After
+	case after == nil:
+		entry.Changes = model.RawJSON("null")
+	default:
+		changes, err := jsonpatch.CreateMergePatch(entry.Before, entry.After)
+		if err != nil {
+			return model.AuditEntry{}, err
+		}
+		entry.Changes = changes
+	}
+	return entry, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuditEntry(t *testing.T) {
+	ctx := auth.WithPrincipal(requestid.With(context.Background(), "req-1"), auth.Principal{Name: "alice"})
+	before := model.Order{ID: 1, CustomerName: "bob", Status: "pending", Items: []model.Item{{ID: 2, ItemCode: "A", Quantity: 1, OrderID: 1}}}
+	after := before
+	after.Status = "paid"
+
+	entry, err := newAuditEntry(ctx, model.AuditUpdate, &before, &after)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), entry.OrderID)
+	assert.Equal(t, "alice", entry.Actor)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.JSONEq(t, `{"status":"paid"}`, string(entry.Changes))
+
+	entry, err = newAuditEntry(context.Background(), model.AuditDelete, &before, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, anonymousActor, entry.Actor)
+	assert.Nil(t, entry.After)
+	assert.Equal(t, "null", string(entry.Changes))
+}
+
+func TestOrderAuditTrail(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Name: "alice"})
+
+	results, err := svc.CreateOrders(ctx, []model.Order{{CustomerName: "bob", Items: []model.Item{{ItemCode: "A", Quantity: 1}}}}, BatchAtomic)
+	assert.Nil(t, err)
+	order := results[0].Order
+	_, err = svc.PatchOrder(ctx, order.ID, []byte(`{"status":"paid"}`), MergePatch)
+	assert.Nil(t, err)
+	assert.Nil(t, svc.DeleteOrder(ctx, order.ID))
+
+	history, err := svc.GetOrderHistory(ctx, order.ID)
+	assert.Nil(t, err)
+	ops := []string{}
+	for _, e := range history {
+		ops = append(ops, e.Operation)
+	}
+	assert.Equal(t, []string{model.AuditCreate, model.AuditUpdate, model.AuditDelete}, ops)
+
+	_, err = svc.GetOrderHistory(ctx, 99)
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+}
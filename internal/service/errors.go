@@ -0,0 +1,12 @@
+package service
+
+import "strings"
+
+// ValidationError lists every problem found in a request.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed: " + strings.Join(e.Errors, "; ")
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+// EventService gives access to the order events produced by OrderService,
+// both stored ones for catching up and live ones as they are published.
+type EventService interface {
+	// GetEventsSince returns up to limit published events with a sequence
+	// number after afterSequence, in the order they were published.
+	GetEventsSince(ctx context.Context, afterSequence uint64, limit int) ([]event.Event, error)
+	// Subscribe starts receiving the live events of the tenant of ctx.
+	// Callers must Close the subscription.
+	Subscribe(ctx context.Context) *event.Subscription
+}
+
+type eventServiceImpl struct {
+	outbox repository.OutboxRepository
+	broker *event.Broker
+}
+
+func NewEventService(outbox repository.OutboxRepository, broker *event.Broker) EventService {
+	return &eventServiceImpl{outbox: outbox, broker: broker}
+}
+
+func (u *eventServiceImpl) GetEventsSince(ctx context.Context, afterSequence uint64, limit int) ([]event.Event, error) {
+	rows, err := u.outbox.GetSince(ctx, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]event.Event, 0, len(rows))
+	for _, row := range rows {
+		e, err := event.FromOutbox(row)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (u *eventServiceImpl) Subscribe(ctx context.Context) *event.Subscription {
+	id, _ := tenant.FromContext(ctx)
+	return u.broker.SubscribeTenant(id)
+}
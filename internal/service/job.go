@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/filestore"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobOutputNotReady is returned for the output of a job that has not
+	// succeeded, or that produces no file.
+	ErrJobOutputNotReady = errors.New("job output is not available")
+)
+
+// JobTypes knows the job types the workers can run.
+type JobTypes interface {
+	// Validate checks the parameters and input of a job of type typ before
+	// it is queued, and returns how many attempts the job gets.
+	Validate(typ string, params []byte, input []byte) (maxAttempts int, err error)
+}
+
+type JobService interface {
+	// CreateJob queues a job. Input is the uploaded file of jobs that read
+	// one and may be nil.
+	CreateJob(ctx context.Context, typ string, params []byte, input *model.JobFile) (model.Job, error)
+	GetJobByID(ctx context.Context, id uint64) (model.Job, error)
+	// GetJobOutput returns the file produced by a job that succeeded, and
+	// its content, which the caller has to close.
+	GetJobOutput(ctx context.Context, id uint64) (model.JobFile, io.ReadCloser, error)
+}
+
+type jobServiceImpl struct {
+	repo  repository.JobRepository
+	files filestore.Store
+	types JobTypes
+}
+
+// NewJobService returns a JobService that reads job outputs from files.
+func NewJobService(repo repository.JobRepository, files filestore.Store, types JobTypes) JobService {
+	return &jobServiceImpl{repo: repo, files: files, types: types}
+}
+
+func (u *jobServiceImpl) CreateJob(ctx context.Context, typ string, params []byte, input *model.JobFile) (model.Job, error) {
+	if len(params) == 0 {
+		params = []byte("{}")
+	}
+	var data []byte
+	if input != nil {
+		data = input.Data
+	}
+	maxAttempts, err := u.types.Validate(typ, params, data)
+	if err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			return model.Job{}, err
+		}
+		return model.Job{}, &ValidationError{Errors: []string{err.Error()}}
+	}
+
+	job := model.Job{
+		Type:        typ,
+		Status:      model.JobStatusQueued,
+		Params:      model.RawJSON(params),
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now().UTC(),
+	}
+	return u.repo.CreateJob(ctx, job, input)
+}
+
+func (u *jobServiceImpl) GetJobByID(ctx context.Context, id uint64) (model.Job, error) {
+	job, err := u.repo.GetJobByID(ctx, id)
+	if err != nil {
+		return model.Job{}, err
+	}
+	if job.ID == 0 {
+		return model.Job{}, ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (u *jobServiceImpl) GetJobOutput(ctx context.Context, id uint64) (model.JobFile, io.ReadCloser, error) {
+	job, err := u.GetJobByID(ctx, id)
+	if err != nil {
+		return model.JobFile{}, nil, err
+	}
+	if job.Status != model.JobStatusSucceeded || !job.HasOutput {
+		return model.JobFile{}, nil, fmt.Errorf("%w: job is %s", ErrJobOutputNotReady, job.Status)
+	}
+	file, err := u.repo.GetJobFile(ctx, id, model.JobFileOutput)
+	if err != nil {
+		return model.JobFile{}, nil, err
+	}
+	if file.ID == 0 {
+		return model.JobFile{}, nil, ErrJobOutputNotReady
+	}
+	// outputs of jobs that ran before the file store are in the row
+	if file.StorageKey == "" {
+		return file, io.NopCloser(bytes.NewReader(file.Data)), nil
+	}
+	content, err := u.files.Open(ctx, file.StorageKey)
+	if errors.Is(err, filestore.ErrNotFound) {
+		return model.JobFile{}, nil, fmt.Errorf("%w: the output file is gone", ErrJobOutputNotReady)
+	}
+	if err != nil {
+		return model.JobFile{}, nil, err
+	}
+	return file, content, nil
+}
@@ -0,0 +1,59 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package service
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockTaxCalculator is an autogenerated mock type for the TaxCalculator type
+type MockTaxCalculator struct {
+	mock.Mock
+}
+
+// CalculateTax provides a mock function with given fields: ctx, order
+func (_m *MockTaxCalculator) CalculateTax(ctx context.Context, order model.Order) ([]model.TaxLine, error) {
+	ret := _m.Called(ctx, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CalculateTax")
+	}
+
+	var r0 []model.TaxLine
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Order) ([]model.TaxLine, error)); ok {
+		return rf(ctx, order)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Order) []model.TaxLine); ok {
+		r0 = rf(ctx, order)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.TaxLine)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Order) error); ok {
+		r1 = rf(ctx, order)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMockTaxCalculator creates a new instance of MockTaxCalculator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTaxCalculator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTaxCalculator {
+	mock := &MockTaxCalculator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
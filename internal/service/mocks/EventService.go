@@ -0,0 +1,79 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	event "github.com/MidnightHelix/assignment-2/internal/event"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventService is an autogenerated mock type for the EventService type
+type EventService struct {
+	mock.Mock
+}
+
+// GetEventsSince provides a mock function with given fields: ctx, afterSequence, limit
+func (_m *EventService) GetEventsSince(ctx context.Context, afterSequence uint64, limit int) ([]event.Event, error) {
+	ret := _m.Called(ctx, afterSequence, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEventsSince")
+	}
+
+	var r0 []event.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) ([]event.Event, error)); ok {
+		return rf(ctx, afterSequence, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) []event.Event); ok {
+		r0 = rf(ctx, afterSequence, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]event.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, int) error); ok {
+		r1 = rf(ctx, afterSequence, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: ctx
+func (_m *EventService) Subscribe(ctx context.Context) *event.Subscription {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 *event.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context) *event.Subscription); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*event.Subscription)
+		}
+	}
+
+	return r0
+}
+
+// NewEventService creates a new instance of EventService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventService {
+	mock := &EventService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
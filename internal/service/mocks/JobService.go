@@ -0,0 +1,124 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// JobService is an autogenerated mock type for the JobService type
+type JobService struct {
+	mock.Mock
+}
+
+// CreateJob provides a mock function with given fields: ctx, typ, params, input
+func (_m *JobService) CreateJob(ctx context.Context, typ string, params []byte, input *model.JobFile) (model.Job, error) {
+	ret := _m.Called(ctx, typ, params, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateJob")
+	}
+
+	var r0 model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, *model.JobFile) (model.Job, error)); ok {
+		return rf(ctx, typ, params, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, *model.JobFile) model.Job); ok {
+		r0 = rf(ctx, typ, params, input)
+	} else {
+		r0 = ret.Get(0).(model.Job)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, *model.JobFile) error); ok {
+		r1 = rf(ctx, typ, params, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJobByID provides a mock function with given fields: ctx, id
+func (_m *JobService) GetJobByID(ctx context.Context, id uint64) (model.Job, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobByID")
+	}
+
+	var r0 model.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.Job, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.Job); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.Job)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJobOutput provides a mock function with given fields: ctx, id
+func (_m *JobService) GetJobOutput(ctx context.Context, id uint64) (model.JobFile, io.ReadCloser, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobOutput")
+	}
+
+	var r0 model.JobFile
+	var r1 io.ReadCloser
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.JobFile, io.ReadCloser, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.JobFile); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.JobFile)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) io.ReadCloser); ok {
+		r1 = rf(ctx, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint64) error); ok {
+		r2 = rf(ctx, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewJobService creates a new instance of JobService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJobService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JobService {
+	mock := &JobService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrderSearchService is an autogenerated mock type for the OrderSearchService type
+type OrderSearchService struct {
+	mock.Mock
+}
+
+// SearchOrders provides a mock function with given fields: ctx, q, limit
+func (_m *OrderSearchService) SearchOrders(ctx context.Context, q string, limit int) (model.OrderSearchResponse, error) {
+	ret := _m.Called(ctx, q, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchOrders")
+	}
+
+	var r0 model.OrderSearchResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (model.OrderSearchResponse, error)); ok {
+		return rf(ctx, q, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) model.OrderSearchResponse); ok {
+		r0 = rf(ctx, q, limit)
+	} else {
+		r0 = ret.Get(0).(model.OrderSearchResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, q, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrderSearchService creates a new instance of OrderSearchService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrderSearchService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrderSearchService {
+	mock := &OrderSearchService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 
 	model "github.com/MidnightHelix/assignment-2/internal/model"
 	mock "github.com/stretchr/testify/mock"
+
+	service "github.com/MidnightHelix/assignment-2/internal/service"
 )
 
 // OrderService is an autogenerated mock type for the OrderService type
@@ -42,6 +44,36 @@ func (_m *OrderService) CreateOrder(ctx context.Context, order model.Order) (mod
 	return r0, r1
 }
 
+// CreateOrders provides a mock function with given fields: ctx, orders, mode
+func (_m *OrderService) CreateOrders(ctx context.Context, orders []model.Order, mode service.BatchMode) ([]service.BatchResult, error) {
+	ret := _m.Called(ctx, orders, mode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrders")
+	}
+
+	var r0 []service.BatchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Order, service.BatchMode) ([]service.BatchResult, error)); ok {
+		return rf(ctx, orders, mode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Order, service.BatchMode) []service.BatchResult); ok {
+		r0 = rf(ctx, orders, mode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.BatchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []model.Order, service.BatchMode) error); ok {
+		r1 = rf(ctx, orders, mode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteOrder provides a mock function with given fields: ctx, id
 func (_m *OrderService) DeleteOrder(ctx context.Context, id uint64) error {
 	ret := _m.Called(ctx, id)
@@ -60,24 +92,162 @@ func (_m *OrderService) DeleteOrder(ctx context.Context, id uint64) error {
 	return r0
 }
 
-// GetOrders provides a mock function with given fields: ctx
-func (_m *OrderService) GetOrders(ctx context.Context) ([]model.Order, error) {
+// DeleteOrders provides a mock function with given fields: ctx, ids, mode
+func (_m *OrderService) DeleteOrders(ctx context.Context, ids []uint64, mode service.BatchMode) ([]service.BatchResult, error) {
+	ret := _m.Called(ctx, ids, mode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOrders")
+	}
+
+	var r0 []service.BatchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64, service.BatchMode) ([]service.BatchResult, error)); ok {
+		return rf(ctx, ids, mode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64, service.BatchMode) []service.BatchResult); ok {
+		r0 = rf(ctx, ids, mode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.BatchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint64, service.BatchMode) error); ok {
+		r1 = rf(ctx, ids, mode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportOrders provides a mock function with given fields: ctx, filter, fn
+func (_m *OrderService) ExportOrders(ctx context.Context, filter model.OrderFilter, fn func([]model.Order) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter, func([]model.Order) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCustomerOrders provides a mock function with given fields: ctx, names, filter
+func (_m *OrderService) GetCustomerOrders(ctx context.Context, names []string, filter model.OrderFilter) (map[string][]model.Order, error) {
+	ret := _m.Called(ctx, names, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomerOrders")
+	}
+
+	var r0 map[string][]model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, model.OrderFilter) (map[string][]model.Order, error)); ok {
+		return rf(ctx, names, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, model.OrderFilter) map[string][]model.Order); ok {
+		r0 = rf(ctx, names, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, model.OrderFilter) error); ok {
+		r1 = rf(ctx, names, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderHistory provides a mock function with given fields: ctx, id
+func (_m *OrderService) GetOrderHistory(ctx context.Context, id uint64) ([]model.AuditEntry, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderHistory")
+	}
+
+	var r0 []model.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) ([]model.AuditEntry, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []model.AuditEntry); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderItems provides a mock function with given fields: ctx, orderIDs
+func (_m *OrderService) GetOrderItems(ctx context.Context, orderIDs []uint64) (map[uint64][]model.Item, error) {
+	ret := _m.Called(ctx, orderIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderItems")
+	}
+
+	var r0 map[uint64][]model.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) (map[uint64][]model.Item, error)); ok {
+		return rf(ctx, orderIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) map[uint64][]model.Item); ok {
+		r0 = rf(ctx, orderIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uint64][]model.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint64) error); ok {
+		r1 = rf(ctx, orderIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderSummaries provides a mock function with given fields: ctx
+func (_m *OrderService) GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error) {
 	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetOrders")
+		panic("no return value specified for GetOrderSummaries")
 	}
 
-	var r0 []model.Order
+	var r0 []model.OrderSummary
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]model.Order, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.OrderSummary, error)); ok {
 		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []model.Order); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) []model.OrderSummary); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]model.Order)
+			r0 = ret.Get(0).([]model.OrderSummary)
 		}
 	}
 
@@ -90,6 +260,36 @@ func (_m *OrderService) GetOrders(ctx context.Context) ([]model.Order, error) {
 	return r0, r1
 }
 
+// GetOrders provides a mock function with given fields: ctx, filter
+func (_m *OrderService) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrders")
+	}
+
+	var r0 []model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter) ([]model.Order, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter) []model.Order); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.OrderFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOrdersById provides a mock function with given fields: ctx, id
 func (_m *OrderService) GetOrdersById(ctx context.Context, id uint64) (model.Order, error) {
 	ret := _m.Called(ctx, id)
@@ -118,6 +318,64 @@ func (_m *OrderService) GetOrdersById(ctx context.Context, id uint64) (model.Ord
 	return r0, r1
 }
 
+// PatchOrder provides a mock function with given fields: ctx, id, patch, typ
+func (_m *OrderService) PatchOrder(ctx context.Context, id uint64, patch []byte, typ service.PatchType) (model.Order, error) {
+	ret := _m.Called(ctx, id, patch, typ)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchOrder")
+	}
+
+	var r0 model.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []byte, service.PatchType) (model.Order, error)); ok {
+		return rf(ctx, id, patch, typ)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []byte, service.PatchType) model.Order); ok {
+		r0 = rf(ctx, id, patch, typ)
+	} else {
+		r0 = ret.Get(0).(model.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, []byte, service.PatchType) error); ok {
+		r1 = rf(ctx, id, patch, typ)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchOrders provides a mock function with given fields: ctx, patches, mode
+func (_m *OrderService) PatchOrders(ctx context.Context, patches []service.OrderPatch, mode service.BatchMode) ([]service.BatchResult, error) {
+	ret := _m.Called(ctx, patches, mode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchOrders")
+	}
+
+	var r0 []service.BatchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []service.OrderPatch, service.BatchMode) ([]service.BatchResult, error)); ok {
+		return rf(ctx, patches, mode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []service.OrderPatch, service.BatchMode) []service.BatchResult); ok {
+		r0 = rf(ctx, patches, mode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.BatchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []service.OrderPatch, service.BatchMode) error); ok {
+		r1 = rf(ctx, patches, mode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateOrder provides a mock function with given fields: ctx, order, id
 func (_m *OrderService) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
 	ret := _m.Called(ctx, order, id)
@@ -146,6 +404,24 @@ func (_m *OrderService) UpdateOrder(ctx context.Context, order model.Order, id u
 	return r0, r1
 }
 
+// ValidateOrder provides a mock function with given fields: ctx, order
+func (_m *OrderService) ValidateOrder(ctx context.Context, order model.Order) error {
+	ret := _m.Called(ctx, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateOrder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Order) error); ok {
+		r0 = rf(ctx, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewOrderService creates a new instance of OrderService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewOrderService(t interface {
@@ -0,0 +1,143 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PromotionService is an autogenerated mock type for the PromotionService type
+type PromotionService struct {
+	mock.Mock
+}
+
+// CreatePromotion provides a mock function with given fields: ctx, promo
+func (_m *PromotionService) CreatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error) {
+	ret := _m.Called(ctx, promo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePromotion")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion) (model.Promotion, error)); ok {
+		return rf(ctx, promo)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion) model.Promotion); ok {
+		r0 = rf(ctx, promo)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Promotion) error); ok {
+		r1 = rf(ctx, promo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotionByID provides a mock function with given fields: ctx, id
+func (_m *PromotionService) GetPromotionByID(ctx context.Context, id uint64) (model.Promotion, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromotionByID")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (model.Promotion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) model.Promotion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromotions provides a mock function with given fields: ctx
+func (_m *PromotionService) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromotions")
+	}
+
+	var r0 []model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.Promotion, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Promotion); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Promotion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePromotion provides a mock function with given fields: ctx, promo, id
+func (_m *PromotionService) UpdatePromotion(ctx context.Context, promo model.Promotion, id uint64) (model.Promotion, error) {
+	ret := _m.Called(ctx, promo, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePromotion")
+	}
+
+	var r0 model.Promotion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion, uint64) (model.Promotion, error)); ok {
+		return rf(ctx, promo, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Promotion, uint64) model.Promotion); ok {
+		r0 = rf(ctx, promo, id)
+	} else {
+		r0 = ret.Get(0).(model.Promotion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Promotion, uint64) error); ok {
+		r1 = rf(ctx, promo, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPromotionService creates a new instance of PromotionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPromotionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PromotionService {
+	mock := &PromotionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,141 @@
+// Code generated by mockery v2.42.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	model "github.com/MidnightHelix/assignment-2/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ReportService is an autogenerated mock type for the ReportService type
+type ReportService struct {
+	mock.Mock
+}
+
+// CustomerOrders provides a mock function with given fields: ctx, req
+func (_m *ReportService) CustomerOrders(ctx context.Context, req model.ReportRequest) (model.CustomerOrdersReport, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CustomerOrders")
+	}
+
+	var r0 model.CustomerOrdersReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) (model.CustomerOrdersReport, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) model.CustomerOrdersReport); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(model.CustomerOrdersReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ReportRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrderSizes provides a mock function with given fields: ctx, req
+func (_m *ReportService) OrderSizes(ctx context.Context, req model.ReportRequest) (model.OrderSizeReport, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrderSizes")
+	}
+
+	var r0 model.OrderSizeReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) (model.OrderSizeReport, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) model.OrderSizeReport); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(model.OrderSizeReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ReportRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrderVolumes provides a mock function with given fields: ctx, req
+func (_m *ReportService) OrderVolumes(ctx context.Context, req model.ReportRequest) (model.OrderVolumeReport, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrderVolumes")
+	}
+
+	var r0 model.OrderVolumeReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) (model.OrderVolumeReport, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) model.OrderVolumeReport); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(model.OrderVolumeReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ReportRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TopItems provides a mock function with given fields: ctx, req
+func (_m *ReportService) TopItems(ctx context.Context, req model.ReportRequest) (model.TopItemsReport, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopItems")
+	}
+
+	var r0 model.TopItemsReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) (model.TopItemsReport, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ReportRequest) model.TopItemsReport); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(model.TopItemsReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ReportRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReportService creates a new instance of ReportService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReportService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReportService {
+	mock := &ReportService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,70 +2,435 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/MidnightHelix/assignment-2/internal/event"
 	"github.com/MidnightHelix/assignment-2/internal/model"
 	"github.com/MidnightHelix/assignment-2/internal/repository"
 )
 
+// ErrOrderNotFound is returned when the requested order does not exist.
+var ErrOrderNotFound = errors.New("order not found")
+
 type OrderService interface {
-	GetOrders(ctx context.Context) ([]model.Order, error)
+	GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error)
+	// GetCustomerOrders returns up to filter.Limit orders of each customer
+	// keyed by customer name, for callers that batch order loading.
+	GetCustomerOrders(ctx context.Context, names []string, filter model.OrderFilter) (map[string][]model.Order, error)
+	// ExportOrders hands every order that matches filter to fn, a chunk at a
+	// time, until fn returns an error.
+	ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(orders []model.Order) error) error
 	GetOrdersById(ctx context.Context, id uint64) (model.Order, error)
 	CreateOrder(ctx context.Context, order model.Order) (model.Order, error)
+	// ValidateOrder checks order with the rules CreateOrder applies, without
+	// storing it or redeeming its promo code.
+	ValidateOrder(ctx context.Context, order model.Order) error
+	// UpdateOrder updates order id. Items without an ID are added, listed
+	// items are updated, keeping their price and tax category when order
+	// gives none, and the items left out are kept. An order without a region
+	// keeps the stored one.
 	UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error)
+	// PatchOrder applies a merge patch or JSON patch to the stored order and
+	// saves the result.
+	PatchOrder(ctx context.Context, id uint64, patch []byte, typ PatchType) (model.Order, error)
 	DeleteOrder(ctx context.Context, id uint64) error
+	// CreateOrders, PatchOrders and DeleteOrders apply a batch of operations
+	// and report the outcome of each one in the order they were given.
+	CreateOrders(ctx context.Context, orders []model.Order, mode BatchMode) ([]BatchResult, error)
+	PatchOrders(ctx context.Context, patches []OrderPatch, mode BatchMode) ([]BatchResult, error)
+	DeleteOrders(ctx context.Context, ids []uint64, mode BatchMode) ([]BatchResult, error)
+	GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error)
+	// GetOrderItems returns the items of several orders keyed by order ID,
+	// for callers that batch item loading.
+	GetOrderItems(ctx context.Context, orderIDs []uint64) (map[uint64][]model.Item, error)
+	// GetOrderHistory returns the audit trail of an order, oldest first. The
+	// trail of a deleted order remains available.
+	GetOrderHistory(ctx context.Context, id uint64) ([]model.AuditEntry, error)
 }
 
 type orderServiceImpl struct {
-	repo repository.OrderQuery
+	query   repository.OrderQuery
+	command repository.OrderCommand
+	tx      repository.TxManager
+	outbox  repository.OutboxRepository
+	audit   repository.AuditRepository
+	// promotions is nil when the storage backend has no promotions; orders
+	// with a promo code are rejected then.
+	promotions repository.PromotionRepository
+	// taxes is nil when orders are not taxed.
+	taxes    TaxCalculator
+	maxBatch int
 }
 
-func NewOrderService(repo repository.OrderQuery) OrderService {
-	return &orderServiceImpl{repo: repo}
+// NewOrderService returns an OrderService that accepts batches of up to
+// maxBatch operations. Orders are not taxed when taxes is nil.
+func NewOrderService(query repository.OrderQuery, command repository.OrderCommand, tx repository.TxManager, outbox repository.OutboxRepository, audit repository.AuditRepository, promotions repository.PromotionRepository, taxes TaxCalculator, maxBatch int) OrderService {
+	return &orderServiceImpl{query: query, command: command, tx: tx, outbox: outbox, audit: audit, promotions: promotions, taxes: taxes, maxBatch: maxBatch}
 }
 
-func (u *orderServiceImpl) GetOrders(ctx context.Context) ([]model.Order, error) {
-	users, err := u.repo.GetOrders(ctx)
+func (u *orderServiceImpl) GetOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, error) {
+	users, err := u.query.GetOrders(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	return users, err
 }
 
+func (u *orderServiceImpl) GetCustomerOrders(ctx context.Context, names []string, filter model.OrderFilter) (map[string][]model.Order, error) {
+	orders, err := u.query.GetOrdersByCustomers(ctx, names, filter)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]model.Order, len(names))
+	for _, order := range orders {
+		res[order.CustomerName] = append(res[order.CustomerName], order)
+	}
+	return res, nil
+}
+
+// exportChunkSize is how many orders an export reads per query.
+const exportChunkSize = 1000
+
+func (u *orderServiceImpl) ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(orders []model.Order) error) error {
+	return u.query.ExportOrders(ctx, filter, exportChunkSize, fn)
+}
+
 func (u *orderServiceImpl) GetOrdersById(ctx context.Context, id uint64) (model.Order, error) {
-	order, err := u.repo.GetOrdersByID(ctx, id)
+	order, err := u.query.GetOrdersByID(ctx, id)
 	if err != nil {
 		return model.Order{}, err
 	}
+	if order.ID == 0 {
+		return model.Order{}, ErrOrderNotFound
+	}
 	return order, err
 }
 
 func (u *orderServiceImpl) CreateOrder(ctx context.Context, req model.Order) (model.Order, error) {
-	order := model.Order{
-		CustomerName: req.CustomerName,
-		OrderedAt:    req.OrderedAt,
-		Items:        req.Items,
-	}
+	order := newOrder(req)
 
-	// store to db
-	res, err := u.repo.CreateOrder(ctx, order)
+	// store to db together with its event and audit entry
+	var res model.Order
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		res, err = u.storeOrder(ctx, order)
+		return err
+	})
 	if err != nil {
 		return model.Order{}, err
 	}
 	return res, err
 }
 
+func (u *orderServiceImpl) ValidateOrder(ctx context.Context, req model.Order) error {
+	// the promotion is looked up as CreateOrder does, locked only briefly
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, _, err := u.prepareOrder(ctx, newOrder(req))
+		return err
+	})
+}
+
 func (u *orderServiceImpl) UpdateOrder(ctx context.Context, order model.Order, id uint64) (model.Order, error) {
-	res, err := u.repo.UpdateOrder(ctx, order, id)
+	var res model.Order
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := u.lockOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if order.Status == "" {
+			order.Status = existing.Status
+		}
+		errs := append(statusErrors(order.Status), foreignItems(order.Items, existing.Items)...)
+		if len(errs) > 0 {
+			return &ValidationError{Errors: errs}
+		}
+		// items left out are kept, so the totals have to count them
+		order.Items = withUnlistedItems(withStoredPricing(order.Items, existing.Items), existing.Items)
+		res, err = u.saveOrder(ctx, existing, order)
+		return err
+	})
+	if err != nil {
+		return model.Order{}, err
+	}
+	return res, err
+}
+
+func (u *orderServiceImpl) PatchOrder(ctx context.Context, id uint64, patch []byte, typ PatchType) (model.Order, error) {
+	var res model.Order
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := u.lockOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		order, err := applyPatch(existing, patch, typ)
+		if err != nil {
+			return err
+		}
+		if err := validateOrder(order, existing.Items); err != nil {
+			return err
+		}
+		// the patched item list is the whole list
+		if err := u.command.DeleteItems(ctx, id, droppedItems(order.Items, existing.Items)); err != nil {
+			return err
+		}
+		res, err = u.saveOrder(ctx, existing, order)
+		return err
+	})
 	if err != nil {
 		return model.Order{}, err
 	}
 	return res, err
 }
 
+// saveOrder reprices and stores the new state of a locked order and appends
+// its events and audit entry. The order keeps the promotion it was created
+// with, and its region unless order names another.
+func (u *orderServiceImpl) saveOrder(ctx context.Context, existing, order model.Order) (model.Order, error) {
+	order.ID = existing.ID
+	order.PromoCode = existing.PromoCode
+	order.PromotionID = existing.PromotionID
+	if order.Region == "" {
+		order.Region = existing.Region
+	}
+	promo, err := u.promotionOf(ctx, existing)
+	if err != nil {
+		return model.Order{}, err
+	}
+	if order, err = u.totalOrder(ctx, order, promo); err != nil {
+		return model.Order{}, err
+	}
+
+	res, err := u.command.UpdateOrder(ctx, order, existing.ID)
+	if err != nil {
+		return model.Order{}, err
+	}
+	if err := u.recordChange(ctx, model.AuditUpdate, &existing, &res); err != nil {
+		return model.Order{}, err
+	}
+
+	events := []event.Event{event.New(event.OrderUpdated, res)}
+	if res.Status != existing.Status {
+		changed := event.New(event.OrderStatusChanged, res)
+		changed.PreviousStatus = existing.Status
+		events = append(events, changed)
+	}
+	if err := u.outbox.Append(ctx, events...); err != nil {
+		return model.Order{}, err
+	}
+	return res, nil
+}
+
+func (u *orderServiceImpl) GetOrderSummaries(ctx context.Context) ([]model.OrderSummary, error) {
+	summaries, err := u.query.GetOrderSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (u *orderServiceImpl) GetOrderItems(ctx context.Context, orderIDs []uint64) (map[uint64][]model.Item, error) {
+	items, err := u.query.GetItemsByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uint64][]model.Item, len(orderIDs))
+	for _, item := range items {
+		res[item.OrderID] = append(res[item.OrderID], item)
+	}
+	return res, nil
+}
+
 func (u *orderServiceImpl) DeleteOrder(ctx context.Context, id uint64) error {
-	err := u.repo.DeleteOrder(ctx, id)
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := u.lockOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := u.command.DeleteOrder(ctx, id); err != nil {
+			return err
+		}
+		if err := u.recordChange(ctx, model.AuditDelete, &existing, nil); err != nil {
+			return err
+		}
+		return u.outbox.Append(ctx, event.New(event.OrderDeleted, existing))
+	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (u *orderServiceImpl) GetOrderHistory(ctx context.Context, id uint64) ([]model.AuditEntry, error) {
+	entries, err := u.audit.GetOrderHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+	// orders created before auditing started have no entries yet
+	if _, err := u.GetOrdersById(ctx, id); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (u *orderServiceImpl) recordChange(ctx context.Context, op string, before, after *model.Order) error {
+	entry, err := newAuditEntry(ctx, op, before, after)
+	if err != nil {
+		return err
+	}
+	return u.audit.Append(ctx, entry)
+}
+
+// lockOrder loads the order and holds its row lock for the rest of the
+// transaction, so the existence check and the write that follows it cannot
+// interleave with a concurrent change.
+func (u *orderServiceImpl) lockOrder(ctx context.Context, id uint64) (model.Order, error) {
+	order, err := u.query.GetOrdersByIDForUpdate(ctx, id)
+	if err != nil {
+		return model.Order{}, err
+	}
+	if order.ID == 0 {
+		return model.Order{}, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// newOrder copies the client supplied fields of req into a new order.
+func newOrder(req model.Order) model.Order {
+	order := model.Order{
+		CustomerName: req.CustomerName,
+		Status:       req.Status,
+		OrderedAt:    req.OrderedAt,
+		Items:        req.Items,
+		PromoCode:    normalizePromoCode(req.PromoCode),
+		Region:       req.Region,
+	}
+	if order.Status == "" {
+		order.Status = model.OrderStatusPending
+	}
+	return order
+}
+
+var knownOrderStatuses = []string{
+	model.OrderStatusPending,
+	model.OrderStatusPaid,
+	model.OrderStatusShipped,
+	model.OrderStatusCompleted,
+	model.OrderStatusCancelled,
+}
+
+// validateStatus rejects a status outside knownOrderStatuses, which event
+// subscribers filtering by status could never match.
+func validateStatus(status string) error {
+	if errs := statusErrors(status); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func statusErrors(status string) []string {
+	for _, s := range knownOrderStatuses {
+		if s == status {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("unknown status %q", status)}
+}
+
+// validateOrder checks a patched order before it is stored. Items that
+// already have an ID must be among existing, so a request cannot move items
+// between orders.
+func validateOrder(order model.Order, existing []model.Item) error {
+	errs := []string{}
+	if order.CustomerName == "" {
+		errs = append(errs, "customer_name is required")
+	}
+	errs = append(errs, statusErrors(order.Status)...)
+	for i, item := range order.Items {
+		if item.ItemCode == "" {
+			errs = append(errs, fmt.Sprintf("items[%d].item_code is required", i))
+		}
+		if item.Quantity == 0 {
+			errs = append(errs, fmt.Sprintf("items[%d].quantity must be at least 1", i))
+		}
+	}
+	errs = append(errs, foreignItems(order.Items, existing)...)
+	if _, err := priceOrder(order, nil); err != nil {
+		errs = append(errs, errOrderTooLarge.Errors...)
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// foreignItems reports the items that have an ID but are not among existing.
+func foreignItems(items, existing []model.Item) []string {
+	errs := []string{}
+	for i, item := range items {
+		if item.ID != 0 && !hasItem(existing, item.ID) {
+			errs = append(errs, fmt.Sprintf("items[%d].item_id %d does not belong to this order", i, item.ID))
+		}
+	}
+	return errs
+}
+
+// withUnlistedItems appends the items of existing that items does not list.
+func withUnlistedItems(items, existing []model.Item) []model.Item {
+	res := append([]model.Item{}, items...)
+	for _, item := range existing {
+		if !hasItem(items, item.ID) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// withStoredPricing gives the listed items of items that have no unit price
+// or tax category those stored in existing, so that clients that do not send
+// them neither make the items free nor change their taxes.
+func withStoredPricing(items, existing []model.Item) []model.Item {
+	res := append([]model.Item{}, items...)
+	for i, item := range res {
+		if item.ID == 0 {
+			continue
+		}
+		for _, stored := range existing {
+			if stored.ID != item.ID {
+				continue
+			}
+			if item.UnitPrice == 0 {
+				res[i].UnitPrice = stored.UnitPrice
+			}
+			if item.TaxCategory == "" {
+				res[i].TaxCategory = stored.TaxCategory
+			}
+			break
+		}
+	}
+	return res
+}
+
+// droppedItems returns the IDs of the items of existing that items does not
+// list.
+func droppedItems(items, existing []model.Item) []uint64 {
+	ids := []uint64{}
+	for _, item := range existing {
+		if !hasItem(items, item.ID) {
+			ids = append(ids, item.ID)
+		}
+	}
+	return ids
+}
+
+func hasItem(items []model.Item, id uint64) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
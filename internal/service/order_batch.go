@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// BatchMode decides what happens to a batch when one of its operations fails.
+type BatchMode string
+
+const (
+	// BatchAtomic applies every operation or none of them.
+	BatchAtomic BatchMode = "atomic"
+	// BatchPartial applies every operation that succeeds on its own.
+	BatchPartial BatchMode = "partial"
+)
+
+// ErrBatchAborted is the result of an operation that was valid but was not
+// applied because another operation of an atomic batch failed.
+var ErrBatchAborted = errors.New("not applied because another operation in the batch failed")
+
+// OrderPatch is one operation of a PatchOrders batch.
+type OrderPatch struct {
+	ID    uint64
+	Patch []byte
+	Type  PatchType
+}
+
+// BatchResult is the outcome of one batch operation. Order is set when Err is
+// nil.
+type BatchResult struct {
+	Order model.Order
+	Err   error
+}
+
+func (u *orderServiceImpl) CreateOrders(ctx context.Context, reqs []model.Order, mode BatchMode) ([]BatchResult, error) {
+	if err := u.validateBatch(len(reqs), mode); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(reqs))
+	valid := []int{}
+	for i, req := range reqs {
+		results[i].Order = newOrder(req)
+		if err := validateStatus(results[i].Order.Status); err != nil {
+			results[i] = BatchResult{Err: err}
+			continue
+		}
+		// so an order that cannot be taxed fails on its own
+		if _, err := u.totalOrder(ctx, results[i].Order, nil); err != nil {
+			results[i] = BatchResult{Err: err}
+			continue
+		}
+		valid = append(valid, i)
+	}
+	if mode == BatchAtomic && len(valid) < len(reqs) {
+		abort(results, valid, ErrBatchAborted)
+		return results, nil
+	}
+
+	// insert every valid order in one round trip
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return u.insertOrders(ctx, results, valid)
+	})
+	if err == nil {
+		return results, nil
+	}
+	if mode == BatchAtomic {
+		abort(results, valid, err)
+		return results, nil
+	}
+
+	// find out which of the orders failed the bulk insert
+	for _, i := range valid {
+		i := i
+		err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+			return u.insertOrders(ctx, results, []int{i})
+		})
+		if err != nil {
+			results[i] = BatchResult{Err: err}
+		}
+	}
+	return results, nil
+}
+
+// insertOrders stores results[i].Order for each i in indexes, together with
+// their events, and writes the stored orders back into results.
+func (u *orderServiceImpl) insertOrders(ctx context.Context, results []BatchResult, indexes []int) error {
+	orders := make([]model.Order, 0, len(indexes))
+	promoted := false
+	for _, i := range indexes {
+		order, err := u.totalOrder(ctx, results[i].Order, nil)
+		if err != nil {
+			return err
+		}
+		orders = append(orders, order)
+		promoted = promoted || order.PromoCode != ""
+	}
+	if promoted {
+		return u.storeOrders(ctx, results, indexes)
+	}
+	orders, err := u.command.CreateOrders(ctx, orders)
+	if err != nil {
+		return err
+	}
+	events := make([]event.Event, 0, len(orders))
+	entries := make([]model.AuditEntry, 0, len(orders))
+	for j, i := range indexes {
+		results[i].Order = orders[j]
+		events = append(events, event.New(event.OrderCreated, orders[j]))
+		entry, err := newAuditEntry(ctx, model.AuditCreate, nil, &orders[j])
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	if err := u.audit.Append(ctx, entries...); err != nil {
+		return err
+	}
+	return u.outbox.Append(ctx, events...)
+}
+
+// storeOrders is insertOrders for batches with promo codes. The orders are
+// stored one at a time, in the order they were given, as each redemption
+// counts against the limits checked for the orders after it.
+func (u *orderServiceImpl) storeOrders(ctx context.Context, results []BatchResult, indexes []int) error {
+	orders := make([]model.Order, len(indexes))
+	for j, i := range indexes {
+		var err error
+		if orders[j], err = u.storeOrder(ctx, results[i].Order); err != nil {
+			return err
+		}
+	}
+	for j, i := range indexes {
+		results[i].Order = orders[j]
+	}
+	return nil
+}
+
+func (u *orderServiceImpl) PatchOrders(ctx context.Context, patches []OrderPatch, mode BatchMode) ([]BatchResult, error) {
+	if err := u.validateBatch(len(patches), mode); err != nil {
+		return nil, err
+	}
+	return u.runBatch(ctx, len(patches), mode, func(ctx context.Context, i int) (model.Order, error) {
+		p := patches[i]
+		return u.PatchOrder(ctx, p.ID, p.Patch, p.Type)
+	}), nil
+}
+
+func (u *orderServiceImpl) DeleteOrders(ctx context.Context, ids []uint64, mode BatchMode) ([]BatchResult, error) {
+	if err := u.validateBatch(len(ids), mode); err != nil {
+		return nil, err
+	}
+	return u.runBatch(ctx, len(ids), mode, func(ctx context.Context, i int) (model.Order, error) {
+		return model.Order{ID: ids[i]}, u.DeleteOrder(ctx, ids[i])
+	}), nil
+}
+
+// runBatch calls op for each of the n operations. Each call runs in its own
+// transaction, or in a savepoint of one shared transaction for an atomic
+// batch, which stops at the first failure.
+func (u *orderServiceImpl) runBatch(ctx context.Context, n int, mode BatchMode, op func(ctx context.Context, i int) (model.Order, error)) []BatchResult {
+	results := make([]BatchResult, n)
+	if mode == BatchPartial {
+		for i := range results {
+			order, err := op(ctx, i)
+			results[i] = BatchResult{Order: order, Err: err}
+		}
+		return results
+	}
+
+	done := []int{}
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range results {
+			order, err := op(ctx, i)
+			if err != nil {
+				results[i] = BatchResult{Err: err}
+				return err
+			}
+			results[i] = BatchResult{Order: order}
+			done = append(done, i)
+		}
+		return nil
+	})
+	if err != nil {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BatchResult{Err: ErrBatchAborted}
+			}
+		}
+		// a failed commit is not the fault of any single operation
+		if len(done) == n {
+			abort(results, done, err)
+		}
+	}
+	return results
+}
+
+func (u *orderServiceImpl) validateBatch(n int, mode BatchMode) error {
+	errs := []string{}
+	if n == 0 {
+		errs = append(errs, "at least one operation is required")
+	}
+	if n > u.maxBatch {
+		errs = append(errs, fmt.Sprintf("at most %d operations are allowed", u.maxBatch))
+	}
+	if mode != BatchAtomic && mode != BatchPartial {
+		errs = append(errs, fmt.Sprintf("unknown mode %q", mode))
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// abort marks the operations at indexes as failed with err.
+func abort(results []BatchResult, indexes []int, err error) {
+	for _, i := range indexes {
+		results[i] = BatchResult{Err: err}
+	}
+}
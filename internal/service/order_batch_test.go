@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrders(t *testing.T) {
+	valid := model.Order{CustomerName: "alice"}
+	invalid := model.Order{CustomerName: "bob", Items: []model.Item{{ItemCode: "A1", Quantity: 2, UnitPrice: math.MaxUint64}}}
+
+	t.Run("inserts valid orders in one call", func(t *testing.T) {
+		svc, orders, outbox := newTestService()
+		res, err := svc.CreateOrders(context.Background(), []model.Order{valid, valid}, BatchAtomic)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), res[0].Order.ID)
+		assert.Equal(t, uint64(2), res[1].Order.ID)
+		assert.Equal(t, model.OrderStatusPending, res[1].Order.Status)
+		assert.Equal(t, 1, orders.inserts)
+		assert.Len(t, pendingEvents(t, outbox), 2)
+	})
+
+	t.Run("atomic batch with an invalid order stores nothing", func(t *testing.T) {
+		svc, orders, _ := newTestService()
+		res, err := svc.CreateOrders(context.Background(), []model.Order{valid, invalid}, BatchAtomic)
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(res[0].Err, ErrBatchAborted))
+		var validationErr *ValidationError
+		assert.True(t, errors.As(res[1].Err, &validationErr))
+		assert.Equal(t, 0, orders.inserts)
+	})
+
+	t.Run("partial batch retries orders one by one after a failed insert", func(t *testing.T) {
+		svc, orders, _ := newTestService()
+		res, err := svc.CreateOrders(context.Background(), []model.Order{valid, invalid, {CustomerName: "boom"}}, BatchPartial)
+		assert.NoError(t, err)
+		assert.NoError(t, res[0].Err)
+		assert.Error(t, res[1].Err)
+		assert.EqualError(t, res[2].Err, "insert failed")
+		assert.Len(t, storedOrders(t, svc), 1)
+		assert.Equal(t, 3, orders.inserts)
+	})
+
+	t.Run("rejects bad batches", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		_, err := svc.CreateOrders(context.Background(), nil, BatchAtomic)
+		assert.Error(t, err)
+		_, err = svc.CreateOrders(context.Background(), []model.Order{valid, valid, valid, valid}, BatchAtomic)
+		assert.Error(t, err)
+		_, err = svc.CreateOrders(context.Background(), []model.Order{valid}, "sometimes")
+		assert.Error(t, err)
+	})
+}
+
+// storeOrders stores an order with each of ids.
+func storeOrders(t *testing.T, svc *orderServiceImpl, ids ...uint64) {
+	for _, id := range ids {
+		_, err := svc.command.CreateOrder(context.Background(), model.Order{ID: id, CustomerName: "alice"})
+		require.NoError(t, err)
+	}
+}
+
+func TestDeleteOrders(t *testing.T) {
+	t.Run("atomic batch stops at the first failure", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		storeOrders(t, svc, 1, 3)
+
+		res, err := svc.DeleteOrders(context.Background(), []uint64{1, 2, 3}, BatchAtomic)
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(res[0].Err, ErrBatchAborted))
+		assert.True(t, errors.Is(res[1].Err, ErrOrderNotFound))
+		assert.True(t, errors.Is(res[2].Err, ErrBatchAborted))
+		assert.Len(t, storedOrders(t, svc), 2)
+	})
+
+	t.Run("partial batch reports each order", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		storeOrders(t, svc, 1, 3)
+
+		res, err := svc.DeleteOrders(context.Background(), []uint64{1, 2, 3}, BatchPartial)
+		assert.NoError(t, err)
+		assert.NoError(t, res[0].Err)
+		assert.True(t, errors.Is(res[1].Err, ErrOrderNotFound))
+		assert.NoError(t, res[2].Err)
+		assert.Empty(t, storedOrders(t, svc))
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// PatchType is the media type of a patch document.
+type PatchType string
+
+const (
+	// MergePatch is an RFC 7396 JSON Merge Patch.
+	MergePatch PatchType = "application/merge-patch+json"
+	// JSONPatch is an RFC 6902 JSON Patch.
+	JSONPatch PatchType = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch is returned when the patch document is malformed or
+	// cannot be applied to the stored order.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPatchConflict is returned when a JSON Patch test operation fails.
+	ErrPatchConflict = errors.New("patch test failed")
+)
+
+// applyPatch applies patch to the JSON form of order and decodes the result.
+// The order ID cannot be changed through a patch.
+func applyPatch(order model.Order, patch []byte, typ PatchType) (model.Order, error) {
+	doc, err := json.Marshal(order)
+	if err != nil {
+		return model.Order{}, err
+	}
+
+	switch typ {
+	case MergePatch:
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case JSONPatch:
+		var ops jsonpatch.Patch
+		ops, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			doc, err = ops.Apply(doc)
+		}
+	default:
+		return model.Order{}, fmt.Errorf("%w: unsupported patch type %q", ErrInvalidPatch, typ)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return model.Order{}, fmt.Errorf("%w: %v", ErrPatchConflict, err)
+	}
+	if err != nil {
+		return model.Order{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	res := model.Order{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&res); err != nil {
+		return model.Order{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	res.ID = order.ID
+	for i := range res.Items {
+		res.Items[i].OrderID = order.ID
+	}
+	return res, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	orderedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	order := model.Order{
+		ID:           1,
+		CustomerName: "alice",
+		Status:       model.OrderStatusPending,
+		OrderedAt:    orderedAt,
+		Items: []model.Item{
+			{ID: 10, ItemCode: "A", Quantity: 1, OrderID: 1},
+			{ID: 11, ItemCode: "B", Quantity: 2, OrderID: 1},
+		},
+	}
+
+	t.Run("merge patch keeps omitted fields", func(t *testing.T) {
+		res, err := applyPatch(order, []byte(`{"customer_name":"bob","order_id":99}`), MergePatch)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), res.ID)
+		assert.Equal(t, "bob", res.CustomerName)
+		assert.Equal(t, orderedAt, res.OrderedAt)
+		assert.Equal(t, order.Items, res.Items)
+	})
+
+	t.Run("json patch on items", func(t *testing.T) {
+		res, err := applyPatch(order, []byte(`[
+			{"op":"replace","path":"/items/0/quantity","value":5},
+			{"op":"remove","path":"/items/1"},
+			{"op":"add","path":"/items/-","value":{"item_code":"C","quantity":3}}
+		]`), JSONPatch)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Item{
+			{ID: 10, ItemCode: "A", Quantity: 5, OrderID: 1},
+			{ItemCode: "C", Quantity: 3, OrderID: 1},
+		}, res.Items)
+	})
+
+	t.Run("failed test operation is a conflict", func(t *testing.T) {
+		_, err := applyPatch(order, []byte(`[{"op":"test","path":"/status","value":"paid"}]`), JSONPatch)
+		assert.True(t, errors.Is(err, ErrPatchConflict))
+	})
+
+	t.Run("invalid patches", func(t *testing.T) {
+		_, err := applyPatch(order, []byte(`[{"op":"remove","path":"/items/5"}]`), JSONPatch)
+		assert.True(t, errors.Is(err, ErrInvalidPatch))
+		_, err = applyPatch(order, []byte(`{"unknown":1}`), MergePatch)
+		assert.True(t, errors.Is(err, ErrInvalidPatch))
+		_, err = applyPatch(order, []byte(`{"items":"none"}`), MergePatch)
+		assert.True(t, errors.Is(err, ErrInvalidPatch))
+	})
+}
+
+func TestValidateOrder(t *testing.T) {
+	existing := []model.Item{{ID: 10}}
+	err := validateOrder(model.Order{
+		Status: "lost",
+		Items:  []model.Item{{ID: 10, ItemCode: "A", Quantity: 1}, {ID: 12, Quantity: 0}},
+	}, existing)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{
+		"customer_name is required",
+		`unknown status "lost"`,
+		"items[1].item_code is required",
+		"items[1].quantity must be at least 1",
+		"items[1].item_id 12 does not belong to this order",
+	}, validationErr.Errors)
+
+	assert.NoError(t, validateOrder(model.Order{CustomerName: "a", Status: model.OrderStatusPaid, Items: existing[:0]}, existing))
+}
+
+func TestUpdateAndPatchItems(t *testing.T) {
+	ctx := context.Background()
+	order := model.Order{
+		CustomerName: "alice",
+		Items: []model.Item{
+			{ItemCode: "A", Quantity: 1, UnitPrice: 100},
+			{ItemCode: "B", Quantity: 2, UnitPrice: 100},
+		},
+	}
+	items := func(t *testing.T, svc *orderServiceImpl, id uint64) []model.Item {
+		res, err := svc.GetOrderItems(ctx, []uint64{id})
+		require.NoError(t, err)
+		return res[id]
+	}
+
+	t.Run("put keeps the items it leaves out", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		created, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+
+		res, err := svc.UpdateOrder(ctx, model.Order{CustomerName: "bob"}, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "bob", res.CustomerName)
+		assert.Len(t, items(t, svc, created.ID), 2)
+		assert.Equal(t, uint64(300), res.Total)
+	})
+
+	t.Run("put cannot take items of another order", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		created, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+		other, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+
+		_, err = svc.UpdateOrder(ctx, model.Order{Items: []model.Item{other.Items[0]}}, created.ID)
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Len(t, items(t, svc, other.ID), 2)
+	})
+
+	t.Run("patch deletes the items it removes", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		created, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+
+		res, err := svc.PatchOrder(ctx, created.ID, []byte(`[{"op":"remove","path":"/items/0"}]`), JSONPatch)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(200), res.Total)
+		remaining := items(t, svc, created.ID)
+		require.Len(t, remaining, 1)
+		assert.Equal(t, "B", remaining[0].ItemCode)
+	})
+
+	t.Run("patch is validated, creation is not", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		created, err := svc.CreateOrder(ctx, model.Order{Items: []model.Item{{ItemCode: "A"}}})
+		require.NoError(t, err)
+
+		_, err = svc.PatchOrder(ctx, created.ID, []byte(`{"status":"lost"}`), MergePatch)
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Len(t, items(t, svc, created.ID), 1)
+	})
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// storeOrder prices and taxes a new order, redeems its promo code and stores
+// it together with its event and audit entry. It must run in a transaction,
+// so a redemption is only counted when the order is stored.
+func (u *orderServiceImpl) storeOrder(ctx context.Context, order model.Order) (model.Order, error) {
+	order, promo, err := u.prepareOrder(ctx, order)
+	if err != nil {
+		return model.Order{}, err
+	}
+
+	res, err := u.command.CreateOrder(ctx, order)
+	if err != nil {
+		return model.Order{}, err
+	}
+	if promo != nil {
+		redeemed, err := u.promotions.Redeem(ctx, model.PromotionRedemption{
+			PromotionID:  promo.ID,
+			CustomerName: res.CustomerName,
+			OrderID:      res.ID,
+			Discount:     res.Discount,
+		})
+		if err != nil {
+			return model.Order{}, err
+		}
+		if !redeemed {
+			return model.Order{}, promoCodeError(order.PromoCode, "has been used up")
+		}
+	}
+	if err := u.recordChange(ctx, model.AuditCreate, nil, &res); err != nil {
+		return model.Order{}, err
+	}
+	if err := u.outbox.Append(ctx, event.New(event.OrderCreated, res)); err != nil {
+		return model.Order{}, err
+	}
+	return res, nil
+}
+
+// prepareOrder checks a new order against the rules of CreateOrder: a known
+// status, a promo code that can be redeemed and applies to the order, and
+// taxes for its region. It returns the order priced and taxed, and its
+// promotion, locked until the transaction of ctx ends.
+func (u *orderServiceImpl) prepareOrder(ctx context.Context, order model.Order) (model.Order, *model.Promotion, error) {
+	if err := validateStatus(order.Status); err != nil {
+		return model.Order{}, nil, err
+	}
+	promo, err := u.redeemablePromotion(ctx, order, time.Now())
+	if err != nil {
+		return model.Order{}, nil, err
+	}
+	if order, err = u.totalOrder(ctx, order, promo); err != nil {
+		return model.Order{}, nil, err
+	}
+	if promo != nil {
+		if err := promotionApplies(order, *promo); err != nil {
+			return model.Order{}, nil, err
+		}
+		order.PromotionID = &promo.ID
+	}
+	return order, promo, nil
+}
+
+// redeemablePromotion looks up the promotion of a new order and checks that
+// the order may redeem it at now. The promotion stays locked until the
+// transaction of ctx ends. An order without a promo code has none.
+func (u *orderServiceImpl) redeemablePromotion(ctx context.Context, order model.Order, now time.Time) (*model.Promotion, error) {
+	if order.PromoCode == "" {
+		return nil, nil
+	}
+	if u.promotions == nil {
+		return nil, &ValidationError{Errors: []string{"promo_code is not supported by this storage backend"}}
+	}
+	promo, err := u.promotions.GetPromotionByCodeForUpdate(ctx, order.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case promo.ID == 0:
+		return nil, promoCodeError(order.PromoCode, "does not exist")
+	case !promo.Active:
+		return nil, promoCodeError(order.PromoCode, "is not active")
+	case promo.StartsAt != nil && now.Before(*promo.StartsAt):
+		return nil, promoCodeError(order.PromoCode, "is not valid yet")
+	case promo.EndsAt != nil && !now.Before(*promo.EndsAt):
+		return nil, promoCodeError(order.PromoCode, "has expired")
+	case promo.MaxUses > 0 && promo.Uses >= promo.MaxUses:
+		return nil, promoCodeError(order.PromoCode, "has been used up")
+	}
+	if promo.MaxUsesPerCustomer > 0 {
+		uses, err := u.promotions.CountCustomerRedemptions(ctx, promo.ID, order.CustomerName)
+		if err != nil {
+			return nil, err
+		}
+		if uses >= promo.MaxUsesPerCustomer {
+			return nil, promoCodeError(order.PromoCode, "has already been used by this customer")
+		}
+	}
+	return &promo, nil
+}
+
+// promotionOf returns the promotion a stored order was placed with, to
+// reprice the order when it changes. Only the discount rules of the
+// promotion matter then: the order redeemed it when it was created.
+func (u *orderServiceImpl) promotionOf(ctx context.Context, order model.Order) (*model.Promotion, error) {
+	if order.PromotionID == nil || u.promotions == nil {
+		return nil, nil
+	}
+	promo, err := u.promotions.GetPromotionByID(ctx, *order.PromotionID)
+	if err != nil {
+		return nil, err
+	}
+	if promo.ID == 0 {
+		return nil, nil
+	}
+	return &promo, nil
+}
+
+// promotionApplies checks that a new order, priced with promo, qualifies for
+// it.
+func promotionApplies(order model.Order, promo model.Promotion) error {
+	if order.Subtotal < promo.MinOrderAmount {
+		return promoCodeError(order.PromoCode, fmt.Sprintf("requires an order of at least %d", promo.MinOrderAmount))
+	}
+	for _, item := range order.Items {
+		if item.UnitPrice > 0 && promotionCovers(promo, item) {
+			return nil
+		}
+	}
+	return promoCodeError(order.PromoCode, "does not apply to any item of the order")
+}
+
+func promoCodeError(code, problem string) error {
+	return &ValidationError{Errors: []string{fmt.Sprintf("promo_code %q %s", code, problem)}}
+}
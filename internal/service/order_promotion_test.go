@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPromotionService(t *testing.T) (*orderServiceImpl, *mocks.PromotionRepository) {
+	svc, _, _ := newTestService()
+	promos := mocks.NewPromotionRepository(t)
+	svc.promotions = promos
+	return svc, promos
+}
+
+func TestCreateOrderPromotion(t *testing.T) {
+	ctx := context.Background()
+	spring := model.Promotion{ID: 1, Code: "SPRING10", Kind: model.PromotionPercentage, Value: 10, Active: true}
+	order := model.Order{
+		CustomerName: "alice",
+		PromoCode:    " spring10 ",
+		Items: []model.Item{
+			{ItemCode: "A1", Quantity: 2, UnitPrice: 1250},
+			{ItemCode: "B2", Quantity: 1, UnitPrice: 999},
+		},
+	}
+
+	t.Run("applies the discount and redeems the code", func(t *testing.T) {
+		svc, promos := newPromotionService(t)
+		promos.On("GetPromotionByCodeForUpdate", mock.Anything, "SPRING10").Return(spring, nil).Once()
+		promos.On("Redeem", mock.Anything, mock.Anything).Return(true, nil).Once()
+		res, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+		assert.Equal(t, "SPRING10", res.PromoCode)
+		assert.Equal(t, uint64(1), *res.PromotionID)
+		assert.Equal(t, uint64(3499), res.Subtotal)
+		assert.Equal(t, uint64(349), res.Discount)
+		assert.Equal(t, uint64(3150), res.Total)
+		assert.Equal(t, uint64(249), res.Items[0].Discount)
+		assert.Equal(t, uint64(100), res.Items[1].Discount)
+		promos.AssertCalled(t, "Redeem", mock.Anything, model.PromotionRedemption{PromotionID: 1, CustomerName: "alice", OrderID: res.ID, Discount: 349})
+	})
+
+	t.Run("enforces the usage limits", func(t *testing.T) {
+		limited := spring
+		limited.MaxUses = 2
+		limited.MaxUsesPerCustomer = 1
+		svc, promos := newPromotionService(t)
+		// the promotion as alice, alice again, bob and carol find it
+		for _, uses := range []uint64{0, 1, 1, 2} {
+			found := limited
+			found.Uses = uses
+			promos.On("GetPromotionByCodeForUpdate", mock.Anything, "SPRING10").Return(found, nil).Once()
+		}
+		promos.On("CountCustomerRedemptions", mock.Anything, uint64(1), "alice").Return(uint64(0), nil).Once()
+		promos.On("CountCustomerRedemptions", mock.Anything, uint64(1), "alice").Return(uint64(1), nil).Once()
+		promos.On("CountCustomerRedemptions", mock.Anything, uint64(1), "bob").Return(uint64(0), nil).Once()
+		promos.On("Redeem", mock.Anything, mock.Anything).Return(true, nil).Twice()
+
+		_, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+		_, err = svc.CreateOrder(ctx, order)
+		assert.EqualError(t, err, `validation failed: promo_code "SPRING10" has already been used by this customer`)
+		bob := order
+		bob.CustomerName = "bob"
+		_, err = svc.CreateOrder(ctx, bob)
+		require.NoError(t, err)
+		carol := order
+		carol.CustomerName = "carol"
+		_, err = svc.CreateOrder(ctx, carol)
+		assert.EqualError(t, err, `validation failed: promo_code "SPRING10" has been used up`)
+	})
+
+	t.Run("rejects codes that cannot be redeemed", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		future := time.Now().Add(time.Hour)
+		tests := map[string]model.Promotion{
+			"does not exist":   {},
+			"is not active":    {ID: 1, Code: "SPRING10", Kind: model.PromotionFixed, Value: 1},
+			"is not valid yet": {ID: 1, Code: "SPRING10", Kind: model.PromotionFixed, Value: 1, Active: true, StartsAt: &future},
+			"has expired":      {ID: 1, Code: "SPRING10", Kind: model.PromotionFixed, Value: 1, Active: true, EndsAt: &past},
+			"requires an order of at least 5000": {
+				ID: 1, Code: "SPRING10", Kind: model.PromotionFixed, Value: 1, Active: true, MinOrderAmount: 5000,
+			},
+			"does not apply to any item of the order": {
+				ID: 1, Code: "SPRING10", Kind: model.PromotionFixed, Value: 1, Active: true, ItemCodes: model.StringList{"Z9"},
+			},
+		}
+		for problem, promo := range tests {
+			svc, promos := newPromotionService(t)
+			promos.On("GetPromotionByCodeForUpdate", mock.Anything, "SPRING10").Return(promo, nil).Once()
+			_, err := svc.CreateOrder(ctx, order)
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr), problem)
+			assert.Equal(t, []string{`promo_code "SPRING10" ` + problem}, validationErr.Errors)
+			promos.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("keeps the promotion when the order changes", func(t *testing.T) {
+		svc, promos := newPromotionService(t)
+		promos.On("GetPromotionByCodeForUpdate", mock.Anything, "SPRING10").Return(spring, nil).Once()
+		promos.On("Redeem", mock.Anything, mock.Anything).Return(true, nil).Once()
+		// changes are priced with the promotion the order was placed with
+		promos.On("GetPromotionByID", mock.Anything, uint64(1)).Return(spring, nil)
+		created, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+		update := created
+		update.PromoCode = "OTHER"
+		update.Items = []model.Item{{ID: created.Items[0].ID, ItemCode: "A1", Quantity: 4, UnitPrice: 1250}}
+		res, err := svc.UpdateOrder(ctx, update, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "SPRING10", res.PromoCode)
+		// B2 was left out of the update and is still counted
+		assert.Len(t, res.Items, 2)
+		assert.Equal(t, uint64(599), res.Discount)
+		assert.Equal(t, uint64(5400), res.Total)
+		promos.AssertNumberOfCalls(t, "Redeem", 1)
+	})
+
+	t.Run("batches store orders with codes one at a time", func(t *testing.T) {
+		svc, promos := newPromotionService(t)
+		promos.On("GetPromotionByCodeForUpdate", mock.Anything, "SPRING10").Return(spring, nil).Twice()
+		promos.On("Redeem", mock.Anything, mock.Anything).Return(true, nil).Twice()
+		orders := svc.command.(*testOrders)
+		bob := order
+		bob.CustomerName = "bob"
+		res, err := svc.CreateOrders(ctx, []model.Order{order, bob}, BatchAtomic)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(349), res[0].Order.Discount)
+		assert.Equal(t, uint64(349), res[1].Order.Discount)
+		assert.Equal(t, 2, orders.inserts)
+	})
+
+	t.Run("needs a backend with promotions", func(t *testing.T) {
+		svc, _, _ := newTestService()
+		_, err := svc.CreateOrder(ctx, order)
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+	})
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+)
+
+const maxSearchQueryLength = 200
+
+type OrderSearchService interface {
+	// SearchOrders finds orders by the words of q in their customer name,
+	// item codes and descriptions. When no order contains the words, it
+	// falls back to orders with similarly spelled ones.
+	SearchOrders(ctx context.Context, q string, limit int) (model.OrderSearchResponse, error)
+}
+
+type orderSearchServiceImpl struct {
+	repo     repository.OrderSearch
+	maxLimit int
+}
+
+// NewOrderSearchService returns a service that returns at most maxLimit hits
+// per search.
+func NewOrderSearchService(repo repository.OrderSearch, maxLimit int) OrderSearchService {
+	return &orderSearchServiceImpl{repo: repo, maxLimit: maxLimit}
+}
+
+func (u *orderSearchServiceImpl) SearchOrders(ctx context.Context, q string, limit int) (model.OrderSearchResponse, error) {
+	q = strings.TrimSpace(q)
+	errs := []string{}
+	if strings.IndexFunc(q, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		errs = append(errs, "q must contain a letter or digit")
+	}
+	if len(q) > maxSearchQueryLength {
+		errs = append(errs, fmt.Sprintf("q may not be longer than %d bytes", maxSearchQueryLength))
+	}
+	if limit < 1 || limit > u.maxLimit {
+		errs = append(errs, fmt.Sprintf("limit must be between 1 and %d", u.maxLimit))
+	}
+	if len(errs) > 0 {
+		return model.OrderSearchResponse{}, &ValidationError{Errors: errs}
+	}
+
+	res := model.OrderSearchResponse{Query: q}
+	hits, err := u.repo.SearchOrders(ctx, q, limit)
+	if err != nil {
+		return model.OrderSearchResponse{}, err
+	}
+	if len(hits) == 0 {
+		res.Fuzzy = true
+		hits, err = u.repo.FuzzySearchOrders(ctx, q, limit)
+		if err != nil {
+			return model.OrderSearchResponse{}, err
+		}
+	}
+	res.Hits = hits
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSearch struct {
+	hits      []model.OrderSearchHit
+	fuzzyHits []model.OrderSearchHit
+	fuzzy     bool
+}
+
+func (f *fakeSearch) SearchOrders(ctx context.Context, text string, limit int) ([]model.OrderSearchHit, error) {
+	return f.hits, nil
+}
+
+func (f *fakeSearch) FuzzySearchOrders(ctx context.Context, text string, limit int) ([]model.OrderSearchHit, error) {
+	f.fuzzy = true
+	return f.fuzzyHits, nil
+}
+
+func TestSearchOrders(t *testing.T) {
+	hit := model.OrderSearchHit{Order: model.Order{ID: 1}}
+
+	t.Run("words match", func(t *testing.T) {
+		repo := &fakeSearch{hits: []model.OrderSearchHit{hit}}
+		res, err := NewOrderSearchService(repo, 100).SearchOrders(context.Background(), " jon ", 20)
+		assert.Nil(t, err)
+		assert.Equal(t, "jon", res.Query)
+		assert.False(t, res.Fuzzy)
+		assert.False(t, repo.fuzzy)
+		assert.Equal(t, 1, len(res.Hits))
+	})
+
+	t.Run("falls back to fuzzy search", func(t *testing.T) {
+		repo := &fakeSearch{hits: []model.OrderSearchHit{}, fuzzyHits: []model.OrderSearchHit{hit}}
+		res, err := NewOrderSearchService(repo, 100).SearchOrders(context.Background(), "jonatan", 20)
+		assert.Nil(t, err)
+		assert.True(t, res.Fuzzy)
+		assert.Equal(t, 1, len(res.Hits))
+	})
+
+	t.Run("invalid search", func(t *testing.T) {
+		_, err := NewOrderSearchService(&fakeSearch{}, 100).SearchOrders(context.Background(), "&&", 101)
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, 2, len(validationErr.Errors))
+	})
+}
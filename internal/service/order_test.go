@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/repository/mocks"
+	"github.com/MidnightHelix/assignment-2/internal/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testOrders is the order command of the memory backend, counting the calls
+// that insert orders and failing those that insert customer "boom".
+type testOrders struct {
+	repository.OrderCommand
+	inserts int
+}
+
+func (c *testOrders) CreateOrders(ctx context.Context, orders []model.Order) ([]model.Order, error) {
+	c.inserts++
+	for _, o := range orders {
+		if o.CustomerName == "boom" {
+			return nil, errors.New("insert failed")
+		}
+	}
+	return c.OrderCommand.CreateOrders(ctx, orders)
+}
+
+func (c *testOrders) CreateOrder(ctx context.Context, order model.Order) (model.Order, error) {
+	orders, err := c.CreateOrders(ctx, []model.Order{order})
+	if err != nil {
+		return model.Order{}, err
+	}
+	return orders[0], nil
+}
+
+// newTestService returns an order service on the memory backend, which
+// rolls back failed transactions like the database does.
+func newTestService() (*orderServiceImpl, *testOrders, repository.OutboxRepository) {
+	store := repository.NewMemoryStore()
+	orders := &testOrders{OrderCommand: repository.NewMemoryOrderCommand(store)}
+	outbox := repository.NewMemoryOutboxRepository(store)
+	svc := &orderServiceImpl{
+		query:    repository.NewMemoryOrderQuery(store),
+		command:  orders,
+		tx:       repository.NewMemoryTxManager(store),
+		outbox:   outbox,
+		audit:    repository.NewMemoryAuditRepository(store),
+		maxBatch: 3,
+	}
+	return svc, orders, outbox
+}
+
+// storedOrders returns every order of the service.
+func storedOrders(t *testing.T, svc *orderServiceImpl) []model.Order {
+	orders, err := svc.query.GetOrders(context.Background(), model.OrderFilter{})
+	require.NoError(t, err)
+	return orders
+}
+
+// pendingEvents returns every unpublished event of outbox.
+func pendingEvents(t *testing.T, outbox repository.OutboxRepository) []model.OutboxEvent {
+	events, err := outbox.GetPending(context.Background(), time.Now().Add(time.Hour), 100)
+	require.NoError(t, err)
+	return events
+}
+
+type markedTxKey struct{}
+
+// markedTx marks the contexts of its transactions, so a repository call can
+// tell whether it was made inside one.
+type markedTx struct {
+	repository.TxManager
+}
+
+func (m markedTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, markedTxKey{}, true))
+	})
+}
+
+var inTx = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(markedTxKey{}) != nil
+})
+
+func TestOrderOutbox(t *testing.T) {
+	ctx := context.Background()
+	order := model.Order{CustomerName: "alice", Items: []model.Item{{ItemCode: "A1", Quantity: 1}}}
+	appendFailed := errors.New("append failed")
+
+	newService := func() (*orderServiceImpl, *mocks.OutboxRepository) {
+		svc, _, _ := newTestService()
+		svc.tx = markedTx{svc.tx}
+		outbox := &mocks.OutboxRepository{}
+		svc.outbox = outbox
+		return svc, outbox
+	}
+
+	t.Run("create appends its event in the transaction", func(t *testing.T) {
+		svc, outbox := newService()
+		outbox.On("Append", inTx, mock.Anything).Return(nil).Once()
+		_, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+		outbox.AssertExpectations(t)
+		assert.Len(t, storedOrders(t, svc), 1)
+	})
+
+	t.Run("failed append rolls back the create", func(t *testing.T) {
+		svc, outbox := newService()
+		outbox.On("Append", inTx, mock.Anything).Return(appendFailed).Once()
+		_, err := svc.CreateOrder(ctx, order)
+		assert.Equal(t, appendFailed, err)
+		assert.Empty(t, storedOrders(t, svc))
+		history, err := svc.audit.GetOrderHistory(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, history, "the audit entry is rolled back too")
+	})
+
+	t.Run("failed append rolls back the update", func(t *testing.T) {
+		svc, outbox := newService()
+		outbox.On("Append", inTx, mock.Anything).Return(nil).Once()
+		created, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+
+		outbox.On("Append", inTx, mock.Anything).Return(appendFailed).Once()
+		update := created
+		update.CustomerName = "bob"
+		_, err = svc.UpdateOrder(ctx, update, created.ID)
+		assert.Equal(t, appendFailed, err)
+		outbox.AssertExpectations(t)
+		stored, err := svc.GetOrdersById(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", stored.CustomerName)
+	})
+
+	t.Run("failed append rolls back the delete", func(t *testing.T) {
+		svc, outbox := newService()
+		outbox.On("Append", inTx, mock.Anything).Return(nil).Once()
+		created, err := svc.CreateOrder(ctx, order)
+		require.NoError(t, err)
+
+		outbox.On("Append", inTx, mock.Anything).Return(appendFailed).Once()
+		assert.Equal(t, appendFailed, svc.DeleteOrder(ctx, created.ID))
+		outbox.AssertExpectations(t)
+		_, err = svc.GetOrdersById(ctx, created.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestUnknownOrderStatus(t *testing.T) {
+	ctx := context.Background()
+	svc, _, outbox := newTestService()
+	lost := model.Order{CustomerName: "alice", Status: "lost"}
+	want := []string{`unknown status "lost"`}
+
+	_, err := svc.CreateOrder(ctx, lost)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, want, validationErr.Errors)
+
+	created, err := svc.CreateOrder(ctx, model.Order{CustomerName: "alice"})
+	require.NoError(t, err)
+	_, err = svc.UpdateOrder(ctx, lost, created.ID)
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, want, validationErr.Errors)
+
+	res, err := svc.CreateOrders(ctx, []model.Order{{CustomerName: "bob"}, lost}, BatchPartial)
+	require.NoError(t, err)
+	assert.NoError(t, res[0].Err)
+	require.True(t, errors.As(res[1].Err, &validationErr))
+	assert.Equal(t, want, validationErr.Errors)
+
+	for _, e := range pendingEvents(t, outbox) {
+		assert.NotEqual(t, string(event.OrderStatusChanged), e.EventType)
+	}
+	assert.Len(t, storedOrders(t, svc), 2)
+}
+
+func TestValidateOrderAppliesCreateRules(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService()
+	taxes := NewMockTaxCalculator(t)
+	svc.taxes = taxes
+	taxes.On("CalculateTax", mock.Anything, inRegion("")).Return([]model.TaxLine{}, nil).Once()
+	taxes.On("CalculateTax", mock.Anything, inRegion("XX")).
+		Return(nil, fmt.Errorf("%w: unknown region %q", tax.ErrNotTaxable, "XX")).Once()
+
+	assert.NoError(t, svc.ValidateOrder(ctx, model.Order{CustomerName: "alice"}))
+	for req, problem := range map[*model.Order]string{
+		{CustomerName: "alice", Status: "lost"}:      `unknown status "lost"`,
+		{CustomerName: "alice", PromoCode: "spring"}: "promo_code is not supported by this storage backend",
+		{CustomerName: "alice", Region: "XX"}:        `order cannot be taxed: unknown region "XX"`,
+	} {
+		var validationErr *ValidationError
+		require.True(t, errors.As(svc.ValidateOrder(ctx, *req), &validationErr), problem)
+		assert.Equal(t, []string{problem}, validationErr.Errors)
+	}
+	assert.Empty(t, storedOrders(t, svc))
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tax"
+)
+
+// errOrderTooLarge is returned when an amount of an order does not fit in
+// 64 bits.
+var errOrderTooLarge = &ValidationError{Errors: []string{"order amounts are too large"}}
+
+// totalOrder prices order with promo and adds the taxes of the result, when
+// the service has a tax calculator.
+func (u *orderServiceImpl) totalOrder(ctx context.Context, order model.Order, promo *model.Promotion) (model.Order, error) {
+	order, err := priceOrder(order, promo)
+	if err != nil || u.taxes == nil {
+		return order, err
+	}
+	lines, err := u.taxes.CalculateTax(ctx, order)
+	if errors.Is(err, tax.ErrNotTaxable) {
+		return model.Order{}, &ValidationError{Errors: []string{err.Error()}}
+	}
+	if err != nil {
+		return model.Order{}, err
+	}
+	return addTaxes(order, lines)
+}
+
+// addTaxes stores the tax lines on a priced order and adds the taxes that
+// are not included in the prices to its total.
+func addTaxes(order model.Order, lines []model.TaxLine) (model.Order, error) {
+	order.TaxLines = lines
+	for _, line := range lines {
+		var carry uint64
+		if order.Tax, carry = bits.Add64(order.Tax, line.Amount, 0); carry != 0 {
+			return model.Order{}, errOrderTooLarge
+		}
+		if line.Inclusive {
+			continue
+		}
+		if order.Total, carry = bits.Add64(order.Total, line.Amount, 0); carry != 0 {
+			return model.Order{}, errOrderTooLarge
+		}
+	}
+	return order, nil
+}
+
+// priceOrder computes the totals of order before taxes and spreads the
+// discount of promo, if any, over its eligible items. The figures only
+// depend on the items and the rules of promo: repricing an unchanged order
+// gives the same result. A promotion whose minimum amount is not reached
+// takes nothing off.
+func priceOrder(order model.Order, promo *model.Promotion) (model.Order, error) {
+	items := make([]model.Item, len(order.Items))
+	copy(items, order.Items)
+	order.Items = items
+
+	eligible := make([]uint64, len(items))
+	var subtotal, eligibleTotal uint64
+	for i, item := range items {
+		hi, amount := bits.Mul64(item.UnitPrice, item.Quantity)
+		if hi != 0 {
+			return model.Order{}, errOrderTooLarge
+		}
+		var carry uint64
+		if subtotal, carry = bits.Add64(subtotal, amount, 0); carry != 0 {
+			return model.Order{}, errOrderTooLarge
+		}
+		if promo != nil && promotionCovers(*promo, item) {
+			eligible[i] = amount
+			eligibleTotal += amount
+		}
+	}
+
+	var discount uint64
+	if promo != nil && subtotal >= promo.MinOrderAmount {
+		discount = promotionDiscount(*promo, eligibleTotal)
+	}
+	for i, share := range allocate(discount, eligible, eligibleTotal) {
+		items[i].Discount = share
+	}
+	order.Subtotal = subtotal
+	order.Discount = discount
+	order.Tax = 0
+	order.Total = subtotal - discount
+	order.TaxLines = nil
+	return order, nil
+}
+
+// promotionCovers tells whether item is eligible for promo.
+func promotionCovers(promo model.Promotion, item model.Item) bool {
+	if len(promo.ItemCodes) == 0 {
+		return true
+	}
+	for _, code := range promo.ItemCodes {
+		if code == item.ItemCode {
+			return true
+		}
+	}
+	return false
+}
+
+// promotionDiscount is what promo takes off eligible items worth amount:
+// a percentage rounded down, or a fixed amount capped at their worth.
+func promotionDiscount(promo model.Promotion, amount uint64) uint64 {
+	switch promo.Kind {
+	case model.PromotionPercentage:
+		// Value is at most 100, so the division cannot overflow
+		hi, lo := bits.Mul64(amount, min(promo.Value, 100))
+		discount, _ := bits.Div64(hi, lo, 100)
+		return discount
+	case model.PromotionFixed:
+		return min(promo.Value, amount)
+	}
+	return 0
+}
+
+// allocate splits total over lines in proportion to their weights, which add
+// up to sum. Each line gets its share rounded down; the units left over go
+// to the lines with the largest remainders, the earlier line first on a tie.
+// No line gets more than its weight as long as total is at most sum.
+func allocate(total uint64, weights []uint64, sum uint64) []uint64 {
+	shares := make([]uint64, len(weights))
+	if total == 0 || sum == 0 {
+		return shares
+	}
+	remainders := make([]uint64, len(weights))
+	left := total
+	for i, w := range weights {
+		hi, lo := bits.Mul64(total, w)
+		shares[i], remainders[i] = bits.Div64(hi, lo, sum)
+		left -= shares[i]
+	}
+	for ; left > 0; left-- {
+		best := -1
+		for i, r := range remainders {
+			if r > 0 && (best < 0 || r > remainders[best]) {
+				best = i
+			}
+		}
+		shares[best]++
+		remainders[best] = 0
+	}
+	return shares
+}
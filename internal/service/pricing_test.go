@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceOrder(t *testing.T) {
+	items := []model.Item{
+		{ItemCode: "A1", Quantity: 1, UnitPrice: 1000},
+		{ItemCode: "B2", Quantity: 2, UnitPrice: 1000},
+		{ItemCode: "C3", Quantity: 3, UnitPrice: 1000},
+	}
+	tests := []struct {
+		name      string
+		promo     *model.Promotion
+		discounts []uint64
+	}{
+		{
+			name:      "no promotion",
+			discounts: []uint64{0, 0, 0},
+		},
+		{
+			name:      "percentage of every item",
+			promo:     &model.Promotion{Kind: model.PromotionPercentage, Value: 10},
+			discounts: []uint64{100, 200, 300},
+		},
+		{
+			name:      "fixed amount with remainders going to the largest fractions",
+			promo:     &model.Promotion{Kind: model.PromotionFixed, Value: 1000},
+			discounts: []uint64{167, 333, 500},
+		},
+		{
+			name:      "a single unit goes to the largest fraction",
+			promo:     &model.Promotion{Kind: model.PromotionFixed, Value: 1, ItemCodes: model.StringList{"A1", "B2"}},
+			discounts: []uint64{0, 1, 0},
+		},
+		{
+			name:      "restricted to item codes",
+			promo:     &model.Promotion{Kind: model.PromotionPercentage, Value: 50, ItemCodes: model.StringList{"C3"}},
+			discounts: []uint64{0, 0, 1500},
+		},
+		{
+			name:      "fixed amount capped at the eligible items",
+			promo:     &model.Promotion{Kind: model.PromotionFixed, Value: 5000, ItemCodes: model.StringList{"B2"}},
+			discounts: []uint64{0, 2000, 0},
+		},
+		{
+			name:      "minimum order amount not reached",
+			promo:     &model.Promotion{Kind: model.PromotionPercentage, Value: 10, MinOrderAmount: 6001},
+			discounts: []uint64{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := priceOrder(model.Order{Items: items}, tt.promo)
+			require.NoError(t, err)
+			var discount uint64
+			for i, item := range order.Items {
+				assert.Equal(t, tt.discounts[i], item.Discount, "items[%d]", i)
+				discount += item.Discount
+			}
+			assert.Equal(t, uint64(6000), order.Subtotal)
+			assert.Equal(t, discount, order.Discount)
+			assert.Equal(t, 6000-discount, order.Total)
+			assert.Zero(t, items[0].Discount, "the items of the argument are left alone")
+		})
+	}
+}
+
+func TestPriceOrderOverflow(t *testing.T) {
+	_, err := priceOrder(model.Order{Items: []model.Item{{Quantity: 2, UnitPrice: math.MaxUint64}}}, nil)
+	assert.Equal(t, errOrderTooLarge, err)
+	_, err = priceOrder(model.Order{Items: []model.Item{
+		{Quantity: 1, UnitPrice: math.MaxUint64},
+		{Quantity: 1, UnitPrice: 1},
+	}}, nil)
+	assert.Equal(t, errOrderTooLarge, err)
+}
+
+func TestAllocate(t *testing.T) {
+	assert.Equal(t, []uint64{0, 0}, allocate(0, []uint64{1, 1}, 2))
+	// ties go to the earlier line
+	assert.Equal(t, []uint64{34, 33, 33}, allocate(100, []uint64{1, 1, 1}, 3))
+	assert.Equal(t, []uint64{0, 5, 0}, allocate(5, []uint64{0, 7, 0}, 7))
+	shares := allocate(math.MaxUint64, []uint64{math.MaxUint64 - 1, 1}, math.MaxUint64)
+	assert.Equal(t, []uint64{math.MaxUint64 - 1, 1}, shares)
+}
+
+// inRegion matches orders shipping to region.
+func inRegion(region string) any {
+	return mock.MatchedBy(func(order model.Order) bool { return order.Region == region })
+}
+
+func TestTotalOrder(t *testing.T) {
+	svc, _, _ := newTestService()
+	taxes := NewMockTaxCalculator(t)
+	svc.taxes = taxes
+	ctx := context.Background()
+	// 10% of each item after its discount, included in the prices of "IN"
+	discounted := func(region string) any {
+		return mock.MatchedBy(func(order model.Order) bool {
+			return order.Region == region && order.Items[0].Discount == 400 && order.Items[1].Discount == 100
+		})
+	}
+	taxes.On("CalculateTax", mock.Anything, discounted("")).
+		Return([]model.TaxLine{{Amount: 160}, {Amount: 40}}, nil).Once()
+	taxes.On("CalculateTax", mock.Anything, discounted("IN")).
+		Return([]model.TaxLine{{Region: "IN", Inclusive: true, Amount: 160}, {Region: "IN", Inclusive: true, Amount: 40}}, nil).Once()
+	taxes.On("CalculateTax", mock.Anything, inRegion("XX")).
+		Return(nil, fmt.Errorf("%w: unknown region %q", tax.ErrNotTaxable, "XX")).Once()
+	order := model.Order{Items: []model.Item{{Quantity: 2, UnitPrice: 1000}, {Quantity: 1, UnitPrice: 500}}}
+	promo := &model.Promotion{Kind: model.PromotionFixed, Value: 500}
+
+	exclusive, err := svc.totalOrder(ctx, order, promo)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2500), exclusive.Subtotal)
+	assert.Equal(t, uint64(500), exclusive.Discount)
+	assert.Equal(t, uint64(200), exclusive.Tax, "taxes are computed after the discount")
+	assert.Equal(t, uint64(2200), exclusive.Total)
+	assert.Len(t, exclusive.TaxLines, 2)
+
+	order.Region = "IN"
+	inclusive, err := svc.totalOrder(ctx, order, promo)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200), inclusive.Tax)
+	assert.Equal(t, uint64(2000), inclusive.Total)
+
+	order.Region = "XX"
+	_, err = svc.totalOrder(ctx, order, nil)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{`order cannot be taxed: unknown region "XX"`}, validationErr.Errors)
+}
+
+func TestCreateOrdersUntaxable(t *testing.T) {
+	svc, orders, _ := newTestService()
+	taxes := NewMockTaxCalculator(t)
+	svc.taxes = taxes
+	// the batch is taxed when it is validated and again when it is stored
+	taxes.On("CalculateTax", mock.Anything, inRegion("")).Return([]model.TaxLine{{Amount: 100}}, nil).Twice()
+	taxes.On("CalculateTax", mock.Anything, inRegion("XX")).
+		Return(nil, fmt.Errorf("%w: unknown region %q", tax.ErrNotTaxable, "XX")).Once()
+	res, err := svc.CreateOrders(context.Background(), []model.Order{
+		{CustomerName: "alice", Items: []model.Item{{ItemCode: "A1", Quantity: 1, UnitPrice: 1000}}},
+		{CustomerName: "bob", Region: "XX"},
+	}, BatchPartial)
+	require.NoError(t, err)
+	assert.NoError(t, res[0].Err)
+	assert.Equal(t, uint64(1100), res[0].Order.Total)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(res[1].Err, &validationErr))
+	assert.Equal(t, 1, orders.inserts)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+)
+
+var ErrPromotionNotFound = errors.New("promotion not found")
+
+var knownPromotionKinds = []string{
+	model.PromotionPercentage,
+	model.PromotionFixed,
+}
+
+// maxPromoCodeLength is the longest promo code accepted.
+const maxPromoCodeLength = 64
+
+// PromotionService manages promo codes. Promotions are never deleted:
+// orders keep referring to them and are repriced with their rules when the
+// orders change, so a promotion is retired by deactivating it.
+type PromotionService interface {
+	GetPromotions(ctx context.Context) ([]model.Promotion, error)
+	GetPromotionByID(ctx context.Context, id uint64) (model.Promotion, error)
+	CreatePromotion(ctx context.Context, promo model.Promotion) (model.Promotion, error)
+	// UpdatePromotion replaces every field but the code and the use count.
+	// Once a promotion was redeemed its discount rules are fixed; only its
+	// limits, validity window and active flag may change.
+	UpdatePromotion(ctx context.Context, promo model.Promotion, id uint64) (model.Promotion, error)
+}
+
+type promotionServiceImpl struct {
+	repo repository.PromotionRepository
+}
+
+func NewPromotionService(repo repository.PromotionRepository) PromotionService {
+	return &promotionServiceImpl{repo: repo}
+}
+
+func (u *promotionServiceImpl) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	return u.repo.GetPromotions(ctx)
+}
+
+func (u *promotionServiceImpl) GetPromotionByID(ctx context.Context, id uint64) (model.Promotion, error) {
+	promo, err := u.repo.GetPromotionByID(ctx, id)
+	if err != nil {
+		return model.Promotion{}, err
+	}
+	if promo.ID == 0 {
+		return model.Promotion{}, ErrPromotionNotFound
+	}
+	return promo, nil
+}
+
+func (u *promotionServiceImpl) CreatePromotion(ctx context.Context, req model.Promotion) (model.Promotion, error) {
+	promo := newPromotion(req)
+	promo.Code = normalizePromoCode(req.Code)
+	if err := validatePromotion(promo); err != nil {
+		return model.Promotion{}, err
+	}
+	taken, err := u.repo.GetPromotionByCodeForUpdate(ctx, promo.Code)
+	if err != nil {
+		return model.Promotion{}, err
+	}
+	if taken.ID != 0 {
+		return model.Promotion{}, &ValidationError{Errors: []string{fmt.Sprintf("code %q is already taken", promo.Code)}}
+	}
+	return u.repo.CreatePromotion(ctx, promo)
+}
+
+func (u *promotionServiceImpl) UpdatePromotion(ctx context.Context, req model.Promotion, id uint64) (model.Promotion, error) {
+	existing, err := u.GetPromotionByID(ctx, id)
+	if err != nil {
+		return model.Promotion{}, err
+	}
+	promo := newPromotion(req)
+	promo.ID = existing.ID
+	promo.Code = existing.Code
+	promo.CreatedAt = existing.CreatedAt
+	if err := validatePromotion(promo); err != nil {
+		return model.Promotion{}, err
+	}
+	if existing.Uses > 0 && !sameDiscountRules(existing, promo) {
+		return model.Promotion{}, &ValidationError{Errors: []string{
+			"kind, value, min_order_amount and item_codes cannot change once the promotion was redeemed",
+		}}
+	}
+	return u.repo.UpdatePromotion(ctx, promo)
+}
+
+// newPromotion copies the client supplied fields of req, except the code,
+// into a new promotion.
+func newPromotion(req model.Promotion) model.Promotion {
+	return model.Promotion{
+		Kind:               req.Kind,
+		Value:              req.Value,
+		MinOrderAmount:     req.MinOrderAmount,
+		ItemCodes:          req.ItemCodes,
+		MaxUses:            req.MaxUses,
+		MaxUsesPerCustomer: req.MaxUsesPerCustomer,
+		StartsAt:           req.StartsAt,
+		EndsAt:             req.EndsAt,
+		Active:             req.Active,
+	}
+}
+
+// normalizePromoCode makes codes case-insensitive.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validatePromotion(promo model.Promotion) error {
+	errs := []string{}
+	if promo.Code == "" {
+		errs = append(errs, "code is required")
+	} else if len(promo.Code) > maxPromoCodeLength || strings.Trim(promo.Code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-") != "" {
+		errs = append(errs, fmt.Sprintf("code must be at most %d letters, digits, '_' or '-'", maxPromoCodeLength))
+	}
+	known := false
+	for _, k := range knownPromotionKinds {
+		if k == promo.Kind {
+			known = true
+		}
+	}
+	if !known {
+		errs = append(errs, fmt.Sprintf("unknown kind %q", promo.Kind))
+	}
+	if promo.Value == 0 {
+		errs = append(errs, "value must be at least 1")
+	}
+	if promo.Kind == model.PromotionPercentage && promo.Value > 100 {
+		errs = append(errs, "value of a percentage promotion must be at most 100")
+	}
+	for i, code := range promo.ItemCodes {
+		if code == "" {
+			errs = append(errs, fmt.Sprintf("item_codes[%d] must not be empty", i))
+		}
+	}
+	if promo.StartsAt != nil && promo.EndsAt != nil && !promo.EndsAt.After(*promo.StartsAt) {
+		errs = append(errs, "ends_at must be after starts_at")
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// sameDiscountRules tells whether a and b take the same amount off any
+// order.
+func sameDiscountRules(a, b model.Promotion) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || a.MinOrderAmount != b.MinOrderAmount || len(a.ItemCodes) != len(b.ItemCodes) {
+		return false
+	}
+	for i := range a.ItemCodes {
+		if a.ItemCodes[i] != b.ItemCodes[i] {
+			return false
+		}
+	}
+	return true
+}
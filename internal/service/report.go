@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	// resolve report time zones without relying on the zoneinfo of the host
+	_ "time/tzdata"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+)
+
+const (
+	defaultReportRange = 30 * 24 * time.Hour
+	defaultReportLimit = 10
+	maxReportLimit     = 100
+	// maxReportPeriods keeps a day-by-day report over years from being
+	// requested by accident.
+	maxReportPeriods = 1000
+)
+
+type ReportService interface {
+	// OrderVolumes counts orders per period. Zero fields of req default to
+	// the last 30 days, daily periods and the default time zone.
+	OrderVolumes(ctx context.Context, req model.ReportRequest) (model.OrderVolumeReport, error)
+	TopItems(ctx context.Context, req model.ReportRequest) (model.TopItemsReport, error)
+	OrderSizes(ctx context.Context, req model.ReportRequest) (model.OrderSizeReport, error)
+	CustomerOrders(ctx context.Context, req model.ReportRequest) (model.CustomerOrdersReport, error)
+}
+
+type reportServiceImpl struct {
+	repo     repository.ReportRepository
+	timeZone string
+	now      func() time.Time
+}
+
+// NewReportService returns a service that buckets periods in timeZone
+// unless a request names another IANA time zone.
+func NewReportService(repo repository.ReportRepository, timeZone string) ReportService {
+	return &reportServiceImpl{repo: repo, timeZone: timeZone, now: time.Now}
+}
+
+func (u *reportServiceImpl) OrderVolumes(ctx context.Context, req model.ReportRequest) (model.OrderVolumeReport, error) {
+	if req.Interval == "" {
+		req.Interval = model.ReportDay
+	}
+	req, loc, err := u.normalize(req)
+	if err != nil {
+		return model.OrderVolumeReport{}, err
+	}
+
+	found, err := u.repo.OrderVolumes(ctx, req.Interval, req.TimeZone, req.From, req.To)
+	if err != nil {
+		return model.OrderVolumeReport{}, err
+	}
+	byStart := map[int64]model.OrderVolume{}
+	for _, v := range found {
+		byStart[v.PeriodStart.Unix()] = v
+	}
+	rep := model.OrderVolumeReport{
+		From:     req.From,
+		To:       req.To,
+		Interval: req.Interval,
+		TimeZone: req.TimeZone,
+		Periods:  []model.OrderVolume{},
+	}
+	for start := periodStart(req.From.In(loc), req.Interval); start.Before(req.To); start = nextPeriod(start, req.Interval) {
+		v := byStart[start.Unix()]
+		v.PeriodStart = start
+		rep.Periods = append(rep.Periods, v)
+	}
+	return rep, nil
+}
+
+func (u *reportServiceImpl) TopItems(ctx context.Context, req model.ReportRequest) (model.TopItemsReport, error) {
+	req, _, err := u.normalize(req)
+	if err != nil {
+		return model.TopItemsReport{}, err
+	}
+	items, err := u.repo.TopItems(ctx, req.From, req.To, req.Limit)
+	if err != nil {
+		return model.TopItemsReport{}, err
+	}
+	return model.TopItemsReport{From: req.From, To: req.To, Items: items}, nil
+}
+
+func (u *reportServiceImpl) OrderSizes(ctx context.Context, req model.ReportRequest) (model.OrderSizeReport, error) {
+	req, _, err := u.normalize(req)
+	if err != nil {
+		return model.OrderSizeReport{}, err
+	}
+	rep, err := u.repo.OrderSizes(ctx, req.From, req.To)
+	if err != nil {
+		return model.OrderSizeReport{}, err
+	}
+	rep.From, rep.To = req.From, req.To
+	return rep, nil
+}
+
+func (u *reportServiceImpl) CustomerOrders(ctx context.Context, req model.ReportRequest) (model.CustomerOrdersReport, error) {
+	req, _, err := u.normalize(req)
+	if err != nil {
+		return model.CustomerOrdersReport{}, err
+	}
+	customers, err := u.repo.CustomerOrders(ctx, req.From, req.To, req.Limit)
+	if err != nil {
+		return model.CustomerOrdersReport{}, err
+	}
+	return model.CustomerOrdersReport{From: req.From, To: req.To, Customers: customers}, nil
+}
+
+// normalize fills in the defaults of req and checks it.
+func (u *reportServiceImpl) normalize(req model.ReportRequest) (model.ReportRequest, *time.Location, error) {
+	if req.To.IsZero() {
+		req.To = u.now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-defaultReportRange)
+	}
+	if req.TimeZone == "" {
+		req.TimeZone = u.timeZone
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultReportLimit
+	}
+
+	errs := []string{}
+	if !req.From.Before(req.To) {
+		errs = append(errs, "from must be before to")
+	}
+	loc, err := time.LoadLocation(req.TimeZone)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("unknown time zone %q", req.TimeZone))
+	}
+	if req.Limit < 1 || req.Limit > maxReportLimit {
+		errs = append(errs, fmt.Sprintf("limit must be between 1 and %d", maxReportLimit))
+	}
+	switch req.Interval {
+	case "":
+	case model.ReportDay, model.ReportWeek, model.ReportMonth:
+		if len(errs) == 0 && countPeriods(req.From.In(loc), req.To, req.Interval) > maxReportPeriods {
+			errs = append(errs, fmt.Sprintf("the range spans more than %d periods; use a longer interval", maxReportPeriods))
+		}
+	default:
+		errs = append(errs, "interval must be day, week or month")
+	}
+	if len(errs) > 0 {
+		return model.ReportRequest{}, nil, &ValidationError{Errors: errs}
+	}
+	return req, loc, nil
+}
+
+// periodStart returns the start of the day, ISO week or month containing t,
+// in the location of t. It agrees with date_trunc in Postgres.
+func periodStart(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case model.ReportWeek:
+		// weeks start on Monday
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case model.ReportMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func nextPeriod(start time.Time, interval string) time.Time {
+	switch interval {
+	case model.ReportWeek:
+		return start.AddDate(0, 0, 7)
+	case model.ReportMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func countPeriods(from, to time.Time, interval string) int {
+	n := 0
+	for start := periodStart(from, interval); start.Before(to) && n <= maxReportPeriods; start = nextPeriod(start, interval) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReports struct {
+	repository.ReportRepository
+	volumes  []model.OrderVolume
+	interval string
+	tz       string
+	from, to time.Time
+}
+
+func (f *fakeReports) OrderVolumes(ctx context.Context, interval, tz string, from, to time.Time) ([]model.OrderVolume, error) {
+	f.interval, f.tz, f.from, f.to = interval, tz, from, to
+	return f.volumes, nil
+}
+
+func TestOrderVolumes(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+
+	t.Run("fills empty periods", func(t *testing.T) {
+		repo := &fakeReports{volumes: []model.OrderVolume{
+			{PeriodStart: time.Date(2024, 3, 2, 17, 0, 0, 0, time.UTC), Orders: 2, Items: 3, Quantity: 4},
+		}}
+		svc := NewReportService(repo, "UTC")
+		rep, err := svc.OrderVolumes(context.Background(), model.ReportRequest{
+			From:     time.Date(2024, 3, 1, 12, 0, 0, 0, jakarta),
+			To:       time.Date(2024, 3, 4, 0, 0, 0, 0, jakarta),
+			TimeZone: "Asia/Jakarta",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, model.ReportDay, repo.interval)
+		assert.Equal(t, "Asia/Jakarta", repo.tz)
+		assert.Equal(t, 3, len(rep.Periods))
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, jakarta), rep.Periods[0].PeriodStart)
+		assert.Equal(t, uint64(0), rep.Periods[0].Orders)
+		assert.Equal(t, uint64(2), rep.Periods[2].Orders)
+		assert.Equal(t, time.Date(2024, 3, 3, 0, 0, 0, 0, jakarta), rep.Periods[2].PeriodStart)
+	})
+
+	t.Run("weeks start on monday", func(t *testing.T) {
+		repo := &fakeReports{}
+		rep, err := NewReportService(repo, "UTC").OrderVolumes(context.Background(), model.ReportRequest{
+			From:     time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2024, 3, 19, 0, 0, 0, 0, time.UTC),
+			Interval: model.ReportWeek,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(rep.Periods))
+		assert.Equal(t, time.Monday, rep.Periods[0].PeriodStart.Weekday())
+		assert.Equal(t, 4, rep.Periods[0].PeriodStart.Day())
+	})
+
+	t.Run("months across daylight saving time", func(t *testing.T) {
+		repo := &fakeReports{}
+		rep, err := NewReportService(repo, "UTC").OrderVolumes(context.Background(), model.ReportRequest{
+			From:     time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			Interval: model.ReportMonth,
+			TimeZone: "Europe/Berlin",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(rep.Periods))
+		_, offset := rep.Periods[0].PeriodStart.Zone()
+		assert.Equal(t, 3600, offset)
+		_, offset = rep.Periods[2].PeriodStart.Zone()
+		assert.Equal(t, 7200, offset)
+	})
+
+	t.Run("defaults to the last 30 days", func(t *testing.T) {
+		repo := &fakeReports{}
+		now := time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC)
+		svc := &reportServiceImpl{repo: repo, timeZone: "UTC", now: func() time.Time { return now }}
+		_, err := svc.OrderVolumes(context.Background(), model.ReportRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, now, repo.to)
+		assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), repo.from)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := NewReportService(&fakeReports{}, "UTC").OrderVolumes(context.Background(), model.ReportRequest{
+			From:     time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			To:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Interval: "year",
+			TimeZone: "Mars/Olympus",
+		})
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, 3, len(validationErr.Errors))
+	})
+
+	t.Run("too many periods", func(t *testing.T) {
+		_, err := NewReportService(&fakeReports{}, "UTC").OrderVolumes(context.Background(), model.ReportRequest{
+			From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+	})
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// TaxCalculator computes the taxes of an order once its discounts are
+// known. tax.TableCalculator is the built-in implementation.
+type TaxCalculator interface {
+	// CalculateTax returns the tax lines of order. Orders that cannot be
+	// taxed, such as ones shipping to a region without rates, are reported
+	// with an error wrapping tax.ErrNotTaxable.
+	CalculateTax(ctx context.Context, order model.Order) ([]model.TaxLine, error)
+}
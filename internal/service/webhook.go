@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/webhook"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+var knownEventTypes = []event.Type{
+	event.OrderCreated,
+	event.OrderUpdated,
+	event.OrderStatusChanged,
+	event.OrderDeleted,
+}
+
+type WebhookService interface {
+	GetWebhooks(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetWebhookByID(ctx context.Context, id uint64) (model.WebhookSubscription, error)
+	// CreateWebhook generates a secret when none is given. The returned
+	// subscription is the only place the secret is ever shown.
+	CreateWebhook(ctx context.Context, sub model.WebhookSubscription) (model.WebhookSubscription, error)
+	// UpdateWebhook replaces URL, event types and active flag. Reactivating
+	// a disabled subscription clears its failure streak.
+	UpdateWebhook(ctx context.Context, sub model.WebhookSubscription, id uint64) (model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uint64) error
+	GetDeliveries(ctx context.Context, webhookID uint64) ([]model.WebhookDelivery, error)
+	GetDeliveryByID(ctx context.Context, webhookID, deliveryID uint64) (model.WebhookDelivery, error)
+	// Redeliver queues a fresh copy of an earlier delivery.
+	Redeliver(ctx context.Context, webhookID, deliveryID uint64) (model.WebhookDelivery, error)
+}
+
+type webhookServiceImpl struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookServiceImpl{repo: repo}
+}
+
+func (u *webhookServiceImpl) GetWebhooks(ctx context.Context) ([]model.WebhookSubscription, error) {
+	subs, err := u.repo.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (u *webhookServiceImpl) GetWebhookByID(ctx context.Context, id uint64) (model.WebhookSubscription, error) {
+	sub, err := u.getWebhook(ctx, id)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (u *webhookServiceImpl) CreateWebhook(ctx context.Context, req model.WebhookSubscription) (model.WebhookSubscription, error) {
+	if err := validateWebhook(ctx, req); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	sub := model.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		Active:     true,
+	}
+	if sub.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return model.WebhookSubscription{}, err
+		}
+		sub.Secret = secret
+	}
+	return u.repo.CreateSubscription(ctx, sub)
+}
+
+func (u *webhookServiceImpl) UpdateWebhook(ctx context.Context, req model.WebhookSubscription, id uint64) (model.WebhookSubscription, error) {
+	if err := validateWebhook(ctx, req); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	sub, err := u.getWebhook(ctx, id)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	sub.URL = req.URL
+	sub.EventTypes = req.EventTypes
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if req.Active && !sub.Active {
+		sub.ConsecutiveFailures = 0
+		sub.DisabledAt = nil
+	}
+	sub.Active = req.Active
+
+	res, err := u.repo.UpdateSubscription(ctx, sub)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	res.Secret = ""
+	return res, nil
+}
+
+func (u *webhookServiceImpl) DeleteWebhook(ctx context.Context, id uint64) error {
+	if _, err := u.getWebhook(ctx, id); err != nil {
+		return err
+	}
+	return u.repo.DeleteSubscription(ctx, id)
+}
+
+func (u *webhookServiceImpl) GetDeliveries(ctx context.Context, webhookID uint64) ([]model.WebhookDelivery, error) {
+	if _, err := u.getWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return u.repo.GetDeliveries(ctx, webhookID)
+}
+
+func (u *webhookServiceImpl) GetDeliveryByID(ctx context.Context, webhookID, deliveryID uint64) (model.WebhookDelivery, error) {
+	delivery, err := u.repo.GetDeliveryByID(ctx, webhookID, deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if delivery.ID == 0 {
+		return model.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (u *webhookServiceImpl) Redeliver(ctx context.Context, webhookID, deliveryID uint64) (model.WebhookDelivery, error) {
+	original, err := u.GetDeliveryByID(ctx, webhookID, deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	redelivery := model.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		RedeliveryOf:   &original.ID,
+		Status:         model.DeliveryStatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+	deliveries := []model.WebhookDelivery{redelivery}
+	if err := u.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return deliveries[0], nil
+}
+
+func (u *webhookServiceImpl) getWebhook(ctx context.Context, id uint64) (model.WebhookSubscription, error) {
+	sub, err := u.repo.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if sub.ID == 0 {
+		return model.WebhookSubscription{}, ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+// validateWebhook checks a subscription before it is stored. URLs that
+// reach into a private network are refused, so that partners cannot make
+// the deliverer post to internal services.
+func validateWebhook(ctx context.Context, sub model.WebhookSubscription) error {
+	errs := []string{}
+	if u, err := url.Parse(sub.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, "url must be an absolute http or https URL")
+	} else if err := webhook.CheckTarget(ctx, u.Hostname()); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, t := range sub.EventTypes {
+		known := false
+		for _, k := range knownEventTypes {
+			if string(k) == t {
+				known = true
+			}
+		}
+		if !known {
+			errs = append(errs, fmt.Sprintf("unknown event type %q", t))
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhookRejectsPrivateTargets(t *testing.T) {
+	svc := NewWebhookService(mocks.NewWebhookRepository(t))
+	for _, url := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1:6060/debug/vars",
+		"https://10.0.0.8/hooks",
+		"http://[::1]/hooks",
+		"http://localhost:3000/hooks",
+	} {
+		_, err := svc.CreateWebhook(context.Background(), model.WebhookSubscription{URL: url})
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr), url)
+		assert.Equal(t, []string{"url must not point to a loopback, private or link-local address"}, validationErr.Errors, url)
+	}
+}
@@ -0,0 +1,247 @@
+// Package tax computes the taxes of orders from a table of rates per region
+// and tax category.
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+)
+
+// ErrNotTaxable is wrapped by the errors about orders the table has no
+// rates for.
+var ErrNotTaxable = errors.New("order cannot be taxed")
+
+// StandardCategory is the tax category of items that name none.
+const StandardCategory = "standard"
+
+// Rounding decides what happens to the fraction of a minor unit a tax
+// amount ends with.
+type Rounding string
+
+const (
+	RoundHalfUp   Rounding = "half_up"
+	RoundHalfEven Rounding = "half_even"
+	RoundUp       Rounding = "up"
+	RoundDown     Rounding = "down"
+)
+
+// Table is the tax configuration of the regions orders ship to, usually
+// read from a JSON file with LoadTable.
+type Table struct {
+	// DefaultRegion taxes the orders that name no region. When empty, such
+	// orders are not taxed.
+	DefaultRegion string            `json:"default_region"`
+	Regions       map[string]Region `json:"regions"`
+}
+
+// Region holds the rates of one region, such as "DE" or "US-CA".
+type Region struct {
+	// Inclusive tells that item prices include the tax, as is usual for
+	// VAT. Otherwise the tax is added to them.
+	Inclusive bool `json:"inclusive"`
+	// Rounding defaults to RoundHalfUp.
+	Rounding Rounding `json:"rounding"`
+	// PerLine rounds the tax of every item. Otherwise the tax of each rate
+	// is rounded once, over all the items it applies to.
+	PerLine bool `json:"per_line"`
+	// Rates maps tax categories to percentages with up to four decimals,
+	// such as "19" or "8.875".
+	Rates map[string]string `json:"rates"`
+}
+
+// LoadTable reads a Table from a JSON file.
+func LoadTable(path string) (Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Table{}, err
+	}
+	defer f.Close()
+	table := Table{}
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&table); err != nil {
+		return Table{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// TableCalculator taxes orders with the rates of a Table. The items of an
+// order are grouped by tax category, and each group becomes a tax line in
+// the order the categories first appear.
+type TableCalculator struct {
+	defaultRegion string
+	regions       map[string]region
+}
+
+type region struct {
+	Region
+	rates map[string]rate
+}
+
+type rate struct {
+	percent string
+	// ppm is the rate in parts per million.
+	ppm uint64
+}
+
+// NewTableCalculator checks table and returns a calculator for it. Region
+// codes are matched case-insensitively.
+func NewTableCalculator(table Table) (*TableCalculator, error) {
+	c := &TableCalculator{
+		defaultRegion: strings.ToUpper(table.DefaultRegion),
+		regions:       make(map[string]region, len(table.Regions)),
+	}
+	for code, r := range table.Regions {
+		switch r.Rounding {
+		case "":
+			r.Rounding = RoundHalfUp
+		case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+		default:
+			return nil, fmt.Errorf("region %q: unknown rounding %q", code, r.Rounding)
+		}
+		rates := make(map[string]rate, len(r.Rates))
+		for category, percent := range r.Rates {
+			if category == "" {
+				return nil, fmt.Errorf("region %q: empty tax category", code)
+			}
+			ppm, err := parseRate(percent)
+			if err != nil {
+				return nil, fmt.Errorf("region %q: rate of %q: %w", code, category, err)
+			}
+			rates[category] = rate{percent: formatRate(ppm), ppm: ppm}
+		}
+		c.regions[strings.ToUpper(code)] = region{Region: r, rates: rates}
+	}
+	if _, ok := c.regions[c.defaultRegion]; c.defaultRegion != "" && !ok {
+		return nil, fmt.Errorf("default region %q is not in the table", table.DefaultRegion)
+	}
+	return c, nil
+}
+
+// CalculateTax returns the tax lines of order, whose items carry their
+// discounts. An order without a region and without a default region has
+// none.
+func (c *TableCalculator) CalculateTax(ctx context.Context, order model.Order) ([]model.TaxLine, error) {
+	code := strings.ToUpper(order.Region)
+	if code == "" {
+		code = c.defaultRegion
+	}
+	if code == "" {
+		return nil, nil
+	}
+	r, ok := c.regions[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown region %q", ErrNotTaxable, order.Region)
+	}
+
+	type group struct {
+		category string
+		rate     rate
+		base     uint64
+		tax      uint64
+	}
+	groups := []*group{}
+	byCategory := map[string]*group{}
+	for i, item := range order.Items {
+		category := item.TaxCategory
+		if category == "" {
+			category = StandardCategory
+		}
+		g, ok := byCategory[category]
+		if !ok {
+			rt, ok := r.rates[category]
+			if !ok {
+				return nil, fmt.Errorf("%w: items[%d].tax_category %q has no rate in region %q", ErrNotTaxable, i, category, code)
+			}
+			g = &group{category: category, rate: rt}
+			byCategory[category] = g
+			groups = append(groups, g)
+		}
+		base := item.UnitPrice*item.Quantity - item.Discount
+		g.base += base
+		if r.PerLine {
+			g.tax += r.tax(base, g.rate.ppm)
+		}
+	}
+
+	lines := make([]model.TaxLine, 0, len(groups))
+	for _, g := range groups {
+		if !r.PerLine {
+			g.tax = r.tax(g.base, g.rate.ppm)
+		}
+		taxable := g.base
+		if r.Inclusive {
+			taxable -= g.tax
+		}
+		lines = append(lines, model.TaxLine{
+			Region:    code,
+			Category:  g.category,
+			Rate:      g.rate.percent,
+			Inclusive: r.Inclusive,
+			Taxable:   taxable,
+			Amount:    g.tax,
+		})
+	}
+	return lines, nil
+}
+
+// tax is the tax at ppm over amount, which includes it when the prices of
+// the region do.
+func (r region) tax(amount, ppm uint64) uint64 {
+	if r.Inclusive {
+		return r.Rounding.divide(amount, ppm, 1_000_000+ppm)
+	}
+	return r.Rounding.divide(amount, ppm, 1_000_000)
+}
+
+// divide returns n*m/d rounded. m must be at most d.
+func (r Rounding) divide(n, m, d uint64) uint64 {
+	hi, lo := bits.Mul64(n, m)
+	q, rem := bits.Div64(hi, lo, d)
+	switch r {
+	case RoundUp:
+		if rem > 0 {
+			q++
+		}
+	case RoundDown:
+	case RoundHalfEven:
+		if 2*rem > d || (2*rem == d && q%2 == 1) {
+			q++
+		}
+	default:
+		if 2*rem >= d {
+			q++
+		}
+	}
+	return q
+}
+
+// parseRate turns a percentage such as "8.875" into parts per million.
+func parseRate(percent string) (uint64, error) {
+	whole, frac, _ := strings.Cut(percent, ".")
+	if whole == "" || len(frac) > 4 || strings.Trim(whole+frac, "0123456789") != "" {
+		return 0, fmt.Errorf("%q is not a percentage with up to four decimals", percent)
+	}
+	n, err := strconv.ParseUint(whole+frac+strings.Repeat("0", 4-len(frac)), 10, 64)
+	if err != nil || n > 1_000_000 {
+		return 0, fmt.Errorf("%q is not between 0 and 100", percent)
+	}
+	return n, nil
+}
+
+// formatRate is the inverse of parseRate, without trailing zeros.
+func formatRate(ppm uint64) string {
+	s := strconv.FormatUint(ppm/10_000, 10)
+	if frac := ppm % 10_000; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%04d", frac), "0")
+	}
+	return s
+}
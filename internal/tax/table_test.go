@@ -0,0 +1,165 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateTax(t *testing.T) {
+	calc, err := NewTableCalculator(Table{
+		DefaultRegion: "de",
+		Regions: map[string]Region{
+			"DE":    {Inclusive: true, Rounding: RoundHalfEven, Rates: map[string]string{"standard": "19", "reduced": "7"}},
+			"US-CA": {Rates: map[string]string{"standard": "7.25", "food": "0"}},
+			"US-NY": {PerLine: true, Rates: map[string]string{"standard": "8.875"}},
+			"XX":    {Rounding: RoundUp, Rates: map[string]string{"standard": "10"}},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		order model.Order
+		lines []model.TaxLine
+	}{
+		{
+			name: "inclusive prices in the default region",
+			order: model.Order{Items: []model.Item{
+				{Quantity: 1, UnitPrice: 1190},
+				{Quantity: 2, UnitPrice: 1070, TaxCategory: "reduced"},
+				{Quantity: 1, UnitPrice: 500, Discount: 100, TaxCategory: "standard"},
+			}},
+			lines: []model.TaxLine{
+				{Region: "DE", Category: "standard", Rate: "19", Inclusive: true, Taxable: 1336, Amount: 254},
+				{Region: "DE", Category: "reduced", Rate: "7", Inclusive: true, Taxable: 2000, Amount: 140},
+			},
+		},
+		{
+			name: "exclusive prices rounded over the order",
+			order: model.Order{Region: "us-ca", Items: []model.Item{
+				{Quantity: 1, UnitPrice: 1000},
+				{Quantity: 3, UnitPrice: 999},
+				{Quantity: 1, UnitPrice: 450, TaxCategory: "food"},
+			}},
+			lines: []model.TaxLine{
+				{Region: "US-CA", Category: "standard", Rate: "7.25", Taxable: 3997, Amount: 290},
+				{Region: "US-CA", Category: "food", Rate: "0", Taxable: 450, Amount: 0},
+			},
+		},
+		{
+			name: "exclusive prices rounded per line",
+			order: model.Order{Region: "US-NY", Items: []model.Item{
+				{Quantity: 1, UnitPrice: 100},
+				{Quantity: 1, UnitPrice: 100},
+			}},
+			// 8.875 rounds to 9 on each line, where 17.75 would round to 18
+			lines: []model.TaxLine{
+				{Region: "US-NY", Category: "standard", Rate: "8.875", Taxable: 200, Amount: 18},
+			},
+		},
+		{
+			name:  "rounding up",
+			order: model.Order{Region: "XX", Items: []model.Item{{Quantity: 1, UnitPrice: 101}}},
+			lines: []model.TaxLine{
+				{Region: "XX", Category: "standard", Rate: "10", Taxable: 101, Amount: 11},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := calc.CalculateTax(context.Background(), tt.order)
+			require.NoError(t, err)
+			assert.Equal(t, tt.lines, lines)
+		})
+	}
+}
+
+func TestCalculateTaxNotTaxable(t *testing.T) {
+	calc, err := NewTableCalculator(Table{Regions: map[string]Region{
+		"DE": {Inclusive: true, Rates: map[string]string{"standard": "19"}},
+	}})
+	require.NoError(t, err)
+
+	lines, err := calc.CalculateTax(context.Background(), model.Order{Items: []model.Item{{Quantity: 1, UnitPrice: 100}}})
+	assert.NoError(t, err)
+	assert.Nil(t, lines, "without a default region, orders naming none are not taxed")
+
+	_, err = calc.CalculateTax(context.Background(), model.Order{Region: "FR"})
+	assert.True(t, errors.Is(err, ErrNotTaxable))
+	assert.EqualError(t, err, `order cannot be taxed: unknown region "FR"`)
+
+	_, err = calc.CalculateTax(context.Background(), model.Order{Region: "DE", Items: []model.Item{{TaxCategory: "books"}}})
+	assert.True(t, errors.Is(err, ErrNotTaxable))
+	assert.EqualError(t, err, `order cannot be taxed: items[0].tax_category "books" has no rate in region "DE"`)
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		rounding Rounding
+		// 25/10, 35/10, 21/10 and 29/10
+		want []uint64
+	}{
+		{RoundHalfUp, []uint64{3, 4, 2, 3}},
+		{RoundHalfEven, []uint64{2, 4, 2, 3}},
+		{RoundUp, []uint64{3, 4, 3, 3}},
+		{RoundDown, []uint64{2, 3, 2, 2}},
+	}
+	for _, tt := range tests {
+		got := []uint64{}
+		for _, n := range []uint64{25, 35, 21, 29} {
+			got = append(got, tt.rounding.divide(n, 1, 10))
+		}
+		assert.Equal(t, tt.want, got, tt.rounding)
+	}
+}
+
+func TestNewTableCalculatorInvalid(t *testing.T) {
+	tests := map[string]Table{
+		`default region "FR" is not in the table`: {DefaultRegion: "FR"},
+		`region "DE": unknown rounding "bankers"`: {Regions: map[string]Region{"DE": {Rounding: "bankers"}}},
+		`region "DE": rate of "standard": "19,5" is not a percentage with up to four decimals`: {
+			Regions: map[string]Region{"DE": {Rates: map[string]string{"standard": "19,5"}}},
+		},
+		`region "DE": rate of "standard": "120" is not between 0 and 100`: {
+			Regions: map[string]Region{"DE": {Rates: map[string]string{"standard": "120"}}},
+		},
+	}
+	for want, table := range tests {
+		_, err := NewTableCalculator(table)
+		assert.EqualError(t, err, want)
+	}
+}
+
+func TestRate(t *testing.T) {
+	for percent, ppm := range map[string]uint64{"19": 190000, "7.25": 72500, "8.875": 88750, "0.0001": 1, "100": 1000000} {
+		got, err := parseRate(percent)
+		require.NoError(t, err, percent)
+		assert.Equal(t, ppm, got, percent)
+		assert.Equal(t, percent, formatRate(got))
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tax.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"default_region": "DE",
+		"regions": {"DE": {"inclusive": true, "rounding": "half_even", "rates": {"standard": "19"}}}
+	}`), 0o600))
+	table, err := LoadTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, Table{
+		DefaultRegion: "DE",
+		Regions:       map[string]Region{"DE": {Inclusive: true, Rounding: RoundHalfEven, Rates: map[string]string{"standard": "19"}}},
+	}, table)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"regions": {"DE": {"vat": "19"}}}`), 0o600))
+	_, err = LoadTable(path)
+	assert.Error(t, err)
+}
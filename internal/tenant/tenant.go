@@ -0,0 +1,137 @@
+// Package tenant carries the tenant, one of the storefronts sharing the
+// deployment, that a request acts for. Repositories keep every read and
+// write within it.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// Default owns the rows written before tenants existed.
+const Default = "default"
+
+var (
+	ErrMissing  = errors.New("tenant is required")
+	ErrInvalid  = errors.New("tenant must be 1 to 63 lowercase letters, digits or hyphens")
+	ErrMismatch = errors.New("credentials belong to another tenant")
+	ErrUnbound  = errors.New("credentials are not bound to this tenant")
+	ErrUnscoped = errors.New("no tenant is bound and the work is not marked for every tenant")
+)
+
+type (
+	key           struct{}
+	allTenantsKey struct{}
+)
+
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, key{}, id)
+}
+
+// FromContext returns the tenant stored by the tenant middleware. Background
+// work such as the outbox dispatcher runs without one, marked by
+// WithAllTenants, and sees every tenant.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(key{}).(string)
+	return id, ok
+}
+
+// WithAllTenants marks ctx as background work that acts for every tenant.
+// The database refuses statements whose context has no tenant unless they
+// are marked.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// AllTenants reports whether ctx was marked by WithAllTenants.
+func AllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+// Scope returns the tenant the statements of ctx are kept to, or "" when
+// ctx was marked by WithAllTenants. A context with neither gets
+// ErrUnscoped: a caller that forgot to bind a tenant must not reach the
+// rows of every tenant.
+func Scope(ctx context.Context) (string, error) {
+	if id, ok := FromContext(ctx); ok {
+		return id, nil
+	}
+	if AllTenants(ctx) {
+		return "", nil
+	}
+	return "", ErrUnscoped
+}
+
+// Valid reports whether id can name a tenant. IDs are DNS labels, so that
+// each tenant can have its own subdomain.
+func Valid(id string) bool {
+	if id == "" || len(id) > 63 || id[0] == '-' || id[len(id)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolver picks the tenant of a request.
+type Resolver struct {
+	// BaseDomain, when set, makes the first label of hosts under it the
+	// tenant: shop-a.orders.example.com belongs to shop-a.
+	BaseDomain string
+	// Default is the tenant of callers whose credentials are bound to none.
+	// When empty, they are rejected.
+	Default string
+	// AllowUnbound lets callers whose credentials are bound to no tenant,
+	// every caller when authentication is off, act for the tenant named by
+	// header or host. Only set it behind a gateway that controls both.
+	AllowUnbound bool
+}
+
+// Resolve returns the tenant bound to the credentials of the caller, claim,
+// or else the default. A request that names another tenant by header or
+// host is rejected, unless the caller is unbound and AllowUnbound is set:
+// then the tenant named is used, or else the default.
+func (r Resolver) Resolve(claim, header, host string) (string, error) {
+	named := header
+	if named == "" {
+		named = r.subdomain(host)
+	}
+	if named != "" && !Valid(named) {
+		return "", ErrInvalid
+	}
+	switch {
+	case claim != "":
+		if named != "" && named != claim {
+			return "", ErrMismatch
+		}
+		return claim, nil
+	case r.AllowUnbound && named != "":
+		return named, nil
+	case r.Default == "":
+		return "", ErrMissing
+	case named != "" && named != r.Default:
+		return "", ErrUnbound
+	}
+	return r.Default, nil
+}
+
+func (r Resolver) subdomain(host string) string {
+	if r.BaseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(r.BaseDomain))
+	if !found || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
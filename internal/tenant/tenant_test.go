@@ -0,0 +1,80 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	r := Resolver{BaseDomain: "orders.example.com", Default: Default, AllowUnbound: true}
+
+	tests := []struct {
+		name   string
+		claim  string
+		header string
+		host   string
+		want   string
+		err    error
+	}{
+		{"default", "", "", "localhost:3000", Default, nil},
+		{"header", "", "shop-a", "localhost:3000", "shop-a", nil},
+		{"subdomain", "", "", "Shop-B.orders.example.com:443", "shop-b", nil},
+		{"header before subdomain", "", "shop-a", "shop-b.orders.example.com", "shop-a", nil},
+		{"nested subdomain", "", "", "x.shop-b.orders.example.com", Default, nil},
+		{"claim", "shop-c", "", "localhost", "shop-c", nil},
+		{"claim and matching header", "shop-c", "shop-c", "localhost", "shop-c", nil},
+		{"claim and other header", "shop-c", "shop-a", "localhost", "", ErrMismatch},
+		{"claim and other subdomain", "shop-c", "", "shop-b.orders.example.com", "", ErrMismatch},
+		{"invalid header", "", "Shop_A", "localhost", "", ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(tt.claim, tt.header, tt.host)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Resolver{}.Resolve("", "", "shop-b.orders.example.com")
+	assert.Equal(t, ErrMissing, err)
+
+	// unbound callers are pinned to the default unless allowed to pick
+	r.AllowUnbound = false
+	for _, tt := range []struct {
+		name   string
+		header string
+		host   string
+		want   string
+		err    error
+	}{
+		{"default", "", "localhost:3000", Default, nil},
+		{"default named", Default, "localhost:3000", Default, nil},
+		{"header", "shop-a", "localhost:3000", "", ErrUnbound},
+		{"subdomain", "", "shop-b.orders.example.com", "", ErrUnbound},
+	} {
+		t.Run("unbound "+tt.name, func(t *testing.T) {
+			got, err := r.Resolve("", tt.header, tt.host)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	_, err = Resolver{BaseDomain: "orders.example.com"}.Resolve("", "", "shop-b.orders.example.com")
+	assert.Equal(t, ErrMissing, err)
+}
+
+func TestAllTenants(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, AllTenants(ctx))
+	assert.True(t, AllTenants(WithAllTenants(ctx)))
+}
+
+func TestValid(t *testing.T) {
+	for _, id := range []string{"a", "shop-1", "0"} {
+		assert.True(t, Valid(id), id)
+	}
+	for _, id := range []string{"", "-shop", "shop-", "Shop", "shop.a", "shop_a", string(make([]byte, 64))} {
+		assert.False(t, Valid(id), id)
+	}
+}
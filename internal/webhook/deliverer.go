@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/pkg"
+)
+
+// maxResponseBody caps how much of a receiver's response is kept in the
+// delivery log.
+const maxResponseBody = 1024
+
+// Deliverer sends queued deliveries to subscribers, retrying failures with
+// exponential backoff.
+type Deliverer interface {
+	// Run delivers due webhooks every interval until ctx is done.
+	Run(ctx context.Context)
+	// DeliverOnce sends one batch of due deliveries.
+	DeliverOnce(ctx context.Context) error
+}
+
+type Config struct {
+	Interval  time.Duration
+	BatchSize int
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed.
+	MaxAttempts int
+	// DisableAfter deactivates a subscription after this many consecutive
+	// failed attempts.
+	DisableAfter int
+	Backoff      pkg.Backoff
+}
+
+type delivererImpl struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewDeliverer(repo repository.WebhookRepository, client *http.Client, cfg Config) Deliverer {
+	return &delivererImpl{repo: repo, client: client, cfg: cfg, now: time.Now}
+}
+
+func (d *delivererImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := d.DeliverOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhook: delivery failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *delivererImpl) DeliverOnce(ctx context.Context) error {
+	due, err := d.repo.GetDueDeliveries(ctx, d.now(), d.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range due {
+		if err := d.deliver(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *delivererImpl) deliver(ctx context.Context, delivery model.WebhookDelivery) error {
+	start := d.now()
+	attempt := model.WebhookDeliveryAttempt{Attempt: delivery.Attempts + 1, CreatedAt: start}
+
+	code, body, err := d.send(ctx, delivery, start)
+	attempt.DurationMs = d.now().Sub(start).Milliseconds()
+	attempt.ResponseCode = code
+	attempt.ResponseBody = body
+
+	success := err == nil
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	switch {
+	case success:
+		delivery.Status = model.DeliveryStatusSucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &start
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		attempt.Error = err.Error()
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.LastError = err.Error()
+	default:
+		attempt.Error = err.Error()
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = start.Add(d.cfg.Backoff.Duration(delivery.Attempts - 1))
+	}
+
+	if err := d.repo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		return err
+	}
+	return d.repo.RecordSubscriptionResult(ctx, delivery.SubscriptionID, success, d.cfg.DisableAfter, start)
+}
+
+// send posts the signed payload and reports the response code and a prefix
+// of the response body. Non-2xx responses are returned as errors.
+func (d *delivererImpl) send(ctx context.Context, delivery model.WebhookDelivery, at time.Time) (int, string, error) {
+	body := []byte(delivery.Payload)
+	ts := at.Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Subscription.Secret, ts, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, string(resBody), &StatusError{Code: res.StatusCode}
+	}
+	return res.StatusCode, string(resBody), nil
+}
+
+// StatusError reports a non-2xx response from a subscriber.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return "receiver responded with status " + strconv.Itoa(e.Code)
+}
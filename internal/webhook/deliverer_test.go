@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository/mocks"
+	"github.com/MidnightHelix/assignment-2/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is a local webhook endpoint answering with status.
+func receiver(t *testing.T, status *int) (*httptest.Server, *[]receivedRequest) {
+	var mu sync.Mutex
+	received := []receivedRequest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(*status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &received
+}
+
+func TestPublish(t *testing.T) {
+	repo := mocks.NewWebhookRepository(t)
+	repo.On("GetActiveSubscriptions", mock.Anything).Return([]model.WebhookSubscription{
+		{ID: 1, Active: true, EventTypes: model.StringList{"order.created"}},
+		{ID: 2, Active: true, EventTypes: model.StringList{"order.deleted"}},
+		{ID: 3, Active: true},
+	}, nil)
+	var queued [][]model.WebhookDelivery
+	repo.On("CreateDeliveries", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { queued = append(queued, args.Get(1).([]model.WebhookDelivery)) }).
+		Return(nil)
+
+	e := event.New(event.OrderCreated, model.Order{ID: 9, CustomerName: "testing"})
+	e.ID = 42
+	pub := NewPublisher(repo)
+	assert.Nil(t, pub.Publish(context.Background(), e))
+	// the outbox may publish the same event again
+	assert.Nil(t, pub.Publish(context.Background(), e))
+
+	assert.Equal(t, 2, len(queued))
+	assert.Equal(t, 2, len(queued[0]))
+	assert.Equal(t, uint64(1), queued[0][0].SubscriptionID)
+	assert.Equal(t, uint64(3), queued[0][1].SubscriptionID)
+	assert.Equal(t, model.DeliveryStatusPending, queued[0][0].Status)
+	// republished events get the same keys, so the second batch is dropped
+	assert.Equal(t, "1:42", *queued[0][0].DedupKey)
+	assert.Equal(t, *queued[0][0].DedupKey, *queued[1][0].DedupKey)
+	assert.Equal(t, *queued[0][1].DedupKey, *queued[1][1].DedupKey)
+}
+
+func TestDeliver(t *testing.T) {
+	cfg := Config{
+		BatchSize:    10,
+		MaxAttempts:  3,
+		DisableAfter: 5,
+		Backoff:      pkg.Backoff{Initial: time.Minute, Max: time.Minute},
+	}
+
+	t.Run("signed delivery to subscribed receiver", func(t *testing.T) {
+		status := http.StatusOK
+		srv, received := receiver(t, &status)
+		sub := model.WebhookSubscription{ID: 1, URL: srv.URL, Secret: "s3cret", Active: true}
+
+		repo := mocks.NewWebhookRepository(t)
+		repo.On("GetDueDeliveries", mock.Anything, mock.Anything, 10).Return([]model.WebhookDelivery{
+			{ID: 1, SubscriptionID: 1, EventType: "order.created", Payload: `{"type":"order.created"}`, Status: model.DeliveryStatusPending, Subscription: sub},
+		}, nil)
+		var delivery model.WebhookDelivery
+		var attempt model.WebhookDeliveryAttempt
+		repo.On("RecordAttempt", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				delivery = args.Get(1).(model.WebhookDelivery)
+				attempt = args.Get(2).(model.WebhookDeliveryAttempt)
+			}).
+			Return(nil)
+		repo.On("RecordSubscriptionResult", mock.Anything, uint64(1), true, 5, mock.Anything).Return(nil)
+
+		d := NewDeliverer(repo, srv.Client(), cfg)
+		assert.Nil(t, d.DeliverOnce(context.Background()))
+
+		assert.Equal(t, 1, len(*received))
+		req := (*received)[0]
+		assert.Equal(t, "order.created", req.header.Get(HeaderEvent))
+		assert.Equal(t, "1", req.header.Get(HeaderDelivery))
+		err := Verify("s3cret", req.header.Get(HeaderSignature), req.header.Get(HeaderTimestamp), req.body, 5*time.Minute, time.Now())
+		assert.Nil(t, err)
+
+		assert.Equal(t, model.DeliveryStatusSucceeded, delivery.Status)
+		assert.NotNil(t, delivery.DeliveredAt)
+		assert.Equal(t, 1, attempt.Attempt)
+		assert.Equal(t, http.StatusOK, attempt.ResponseCode)
+	})
+
+	t.Run("retries with backoff then fails", func(t *testing.T) {
+		status := http.StatusInternalServerError
+		srv, received := receiver(t, &status)
+		sub := model.WebhookSubscription{ID: 1, URL: srv.URL, Secret: "s3cret", Active: true}
+
+		now := time.Now()
+		repo := mocks.NewWebhookRepository(t)
+		d := &delivererImpl{repo: repo, client: srv.Client(), cfg: cfg, now: func() time.Time { return now }}
+
+		delivery := model.WebhookDelivery{ID: 1, SubscriptionID: 1, EventType: "order.created", Payload: "{}", Status: model.DeliveryStatusPending, Subscription: sub}
+		for i := 1; i <= 3; i++ {
+			repo.On("GetDueDeliveries", mock.Anything, now, 10).Return([]model.WebhookDelivery{delivery}, nil).Once()
+			repo.On("RecordAttempt", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					delivery = args.Get(1).(model.WebhookDelivery)
+					assert.Equal(t, i, args.Get(2).(model.WebhookDeliveryAttempt).Attempt)
+				}).
+				Return(nil).Once()
+			repo.On("RecordSubscriptionResult", mock.Anything, uint64(1), false, 5, now).Return(nil).Once()
+
+			assert.Nil(t, d.DeliverOnce(context.Background()))
+			assert.Equal(t, i, delivery.Attempts)
+			assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+			if i < 3 {
+				assert.Equal(t, model.DeliveryStatusPending, delivery.Status)
+				assert.True(t, delivery.NextAttemptAt.After(now))
+			}
+			now = now.Add(2 * time.Minute)
+		}
+		assert.Equal(t, 3, len(*received))
+		assert.Equal(t, model.DeliveryStatusFailed, delivery.Status)
+	})
+
+	t.Run("stops the batch when recording fails", func(t *testing.T) {
+		status := http.StatusOK
+		srv, received := receiver(t, &status)
+		sub := model.WebhookSubscription{ID: 1, URL: srv.URL, Secret: "s3cret", Active: true}
+
+		repo := mocks.NewWebhookRepository(t)
+		repo.On("GetDueDeliveries", mock.Anything, mock.Anything, 10).Return([]model.WebhookDelivery{
+			{ID: 1, SubscriptionID: 1, Payload: "{}", Status: model.DeliveryStatusPending, Subscription: sub},
+			{ID: 2, SubscriptionID: 1, Payload: "{}", Status: model.DeliveryStatusPending, Subscription: sub},
+		}, nil)
+		repo.On("RecordAttempt", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("some error")).Once()
+
+		d := NewDeliverer(repo, srv.Client(), cfg)
+		assert.NotNil(t, d.DeliverOnce(context.Background()))
+		assert.Equal(t, 1, len(*received))
+	})
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"type":"order.created"}`)
+	sig := Sign("s3cret", now.Unix(), body)
+
+	assert.Nil(t, Verify("s3cret", sig, ts, body, time.Minute, now))
+	assert.ErrorIs(t, Verify("wrong", sig, ts, body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", sig, ts, []byte(`{}`), time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", sig, ts, body, time.Minute, now.Add(time.Hour)), ErrInvalidSignature)
+}
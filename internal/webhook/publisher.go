@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/MidnightHelix/assignment-2/internal/repository"
+	"github.com/MidnightHelix/assignment-2/internal/tenant"
+)
+
+type publisherImpl struct {
+	repo repository.WebhookRepository
+}
+
+// NewPublisher returns an event.Publisher that queues a delivery for every
+// active subscription interested in the event. Queueing is idempotent, so
+// events republished by the outbox do not produce duplicate deliveries.
+func NewPublisher(repo repository.WebhookRepository) event.Publisher {
+	return &publisherImpl{repo: repo}
+}
+
+func (p *publisherImpl) Publish(ctx context.Context, e event.Event) error {
+	// only the subscriptions of the tenant of the order hear of it
+	if e.TenantID != "" {
+		ctx = tenant.With(ctx, e.TenantID)
+	}
+	subs, err := p.repo.GetActiveSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := []model.WebhookDelivery{}
+	for _, sub := range subs {
+		if len(sub.EventTypes) > 0 && !sub.EventTypes.Contains(string(e.Type)) {
+			continue
+		}
+		key := fmt.Sprintf("%d:%d", sub.ID, e.ID)
+		deliveries = append(deliveries, model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        e.ID,
+			EventType:      string(e.Type),
+			Payload:        string(payload),
+			DedupKey:       &key,
+			Status:         model.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	return p.repo.CreateDeliveries(ctx, deliveries)
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateTarget is returned for subscription URLs, and connections, that
+// reach into a private network.
+var ErrPrivateTarget = errors.New("url must not point to a loopback, private or link-local address")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in all but name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddress reports whether deliveries may be sent to ip. Loopback,
+// private, link-local, cloud metadata services such as 169.254.169.254
+// included, unspecified and multicast addresses are refused, so that a
+// subscription cannot reach the network of the deployment.
+func PublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// CheckTarget refuses a subscription host that is, or resolves to, an
+// address that is not public. A host that does not resolve is let through,
+// as partners may register before their DNS is in place: the client of
+// NewClient checks every connection anyway.
+func CheckTarget(ctx context.Context, host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !PublicAddress(ip) {
+			return ErrPrivateTarget
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !PublicAddress(addr) {
+			return ErrPrivateTarget
+		}
+	}
+	return nil
+}
+
+// NewClient returns the client deliveries are sent with. It refuses to
+// connect to addresses that are not public, checked after the host name is
+// resolved, so that a subscription cannot be turned inward by changing its
+// DNS after it was registered. Redirects are checked the same way. No proxy
+// is used, as the address checked would be that of the proxy.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refusePrivate,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// refusePrivate runs before each connection is made, with the address it is
+// made to.
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !PublicAddress(addr.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, addr.Addr())
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAddress(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::1":   true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00:ec2::254":        false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"224.0.0.1":            false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.216.34": true,
+	} {
+		assert.Equal(t, public, PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	ctx := context.Background()
+	for _, host := range []string{"localhost", "api.localhost", "127.0.0.1", "169.254.169.254", "::1", "10.0.0.5"} {
+		assert.ErrorIs(t, CheckTarget(ctx, host), ErrPrivateTarget, host)
+	}
+	assert.NoError(t, CheckTarget(ctx, "93.184.216.34"))
+}
+
+func TestNewClientRefusesPrivateAddresses(t *testing.T) {
+	status := http.StatusOK
+	srv, received := receiver(t, &status)
+
+	_, err := NewClient(time.Second).Post(srv.URL, "application/json", nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrPrivateTarget), err.Error())
+	assert.Empty(t, *received)
+}
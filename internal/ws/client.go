@@ -0,0 +1,169 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/gorilla/websocket"
+)
+
+// maxMessageSize bounds what a client may send in one message.
+const maxMessageSize = 4096
+
+type client struct {
+	conn      *websocket.Conn
+	principal auth.Principal
+	tenant    string
+	cfg       Config
+
+	out       chan []byte
+	closeOnce sync.Once
+	done      chan struct{}
+
+	mu        sync.Mutex
+	orderIDs  map[uint64]bool
+	customers map[string]bool
+}
+
+func newClient(conn *websocket.Conn, principal auth.Principal, tenantID string, cfg Config) *client {
+	return &client{
+		conn:      conn,
+		principal: principal,
+		tenant:    tenantID,
+		cfg:       cfg,
+		out:       make(chan []byte, cfg.SendBuffer),
+		done:      make(chan struct{}),
+		orderIDs:  map[uint64]bool{},
+		customers: map[string]bool{},
+	}
+}
+
+func (c *client) follows(e event.Event) bool {
+	if c.tenant != "" && e.TenantID != c.tenant {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.orderIDs[e.OrderID] || c.customers[strings.ToLower(e.Order.CustomerName)]
+}
+
+// send queues msg without blocking. A client whose queue is full is too slow
+// to keep up and gets disconnected.
+func (c *client) send(msg []byte) {
+	select {
+	case <-c.done:
+	case c.out <- msg:
+	default:
+		c.close()
+	}
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *client) readLoop() {
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(2 * c.cfg.PingInterval))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * c.cfg.PingInterval))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		req := Request{}
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.reply(Message{Type: TypeError, Error: "malformed message: " + err.Error()})
+			continue
+		}
+		c.reply(c.handle(req))
+	}
+}
+
+func (c *client) handle(req Request) Message {
+	switch req.Action {
+	case ActionPing:
+		return Message{Type: TypePong, RequestID: req.RequestID}
+	case ActionSubscribe, ActionUnsubscribe:
+	default:
+		return Message{Type: TypeError, RequestID: req.RequestID, Error: fmt.Sprintf("unknown action %q", req.Action)}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if req.Action == ActionSubscribe {
+		if len(c.orderIDs)+len(c.customers)+len(req.OrderIDs)+len(req.Customers) > c.cfg.MaxSubscriptions {
+			return Message{Type: TypeError, RequestID: req.RequestID, Error: fmt.Sprintf("at most %d subscriptions per connection", c.cfg.MaxSubscriptions)}
+		}
+		for _, id := range req.OrderIDs {
+			c.orderIDs[id] = true
+		}
+		for _, name := range req.Customers {
+			c.customers[strings.ToLower(name)] = true
+		}
+	} else {
+		for _, id := range req.OrderIDs {
+			delete(c.orderIDs, id)
+		}
+		for _, name := range req.Customers {
+			delete(c.customers, strings.ToLower(name))
+		}
+	}
+
+	res := Message{Type: TypeSubscribed, RequestID: req.RequestID}
+	if req.Action == ActionUnsubscribe {
+		res.Type = TypeUnsubscribed
+	}
+	for id := range c.orderIDs {
+		res.OrderIDs = append(res.OrderIDs, id)
+	}
+	for name := range c.customers {
+		res.Customers = append(res.Customers, name)
+	}
+	sort.Slice(res.OrderIDs, func(i, j int) bool { return res.OrderIDs[i] < res.OrderIDs[j] })
+	sort.Strings(res.Customers)
+	return res
+}
+
+func (c *client) reply(m Message) {
+	msg, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	c.send(msg)
+}
+
+func (c *client) writeLoop() {
+	ping := time.NewTicker(c.cfg.PingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.close()
+				return
+			}
+		case <-ping.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/gorilla/websocket"
+)
+
+type Config struct {
+	// SendBuffer is how many messages a client may fall behind before it is
+	// disconnected.
+	SendBuffer int
+	// MaxSubscriptions caps order IDs plus customers followed by one client.
+	MaxSubscriptions int
+	PingInterval     time.Duration
+	WriteTimeout     time.Duration
+}
+
+// Hub fans order events out to connected WebSocket clients according to
+// what each of them subscribed to.
+type Hub interface {
+	// Run forwards events from the broker until ctx is done, then
+	// disconnects every client.
+	Run(ctx context.Context)
+	// Serve handles one upgraded connection until it closes. The client
+	// only hears of the orders of tenantID.
+	Serve(conn *websocket.Conn, principal auth.Principal, tenantID string)
+}
+
+type hubImpl struct {
+	broker *event.Broker
+	cfg    Config
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+}
+
+func NewHub(broker *event.Broker, cfg Config) Hub {
+	return &hubImpl{broker: broker, cfg: cfg, clients: map[*client]struct{}{}}
+}
+
+func (h *hubImpl) Run(ctx context.Context) {
+	defer h.closeAll()
+	for {
+		sub := h.broker.Subscribe()
+		if done := h.forward(ctx, sub); done {
+			sub.Close()
+			return
+		}
+		// the broker dropped the hub for falling behind or shut down
+		if !sub.Lagged() {
+			return
+		}
+		log.Printf("ws: hub fell behind, asking clients to resync")
+		h.broadcast(Message{Type: TypeResync})
+	}
+}
+
+// forward reports true when ctx ended, false when the subscription closed.
+func (h *hubImpl) forward(ctx context.Context, sub *event.Subscription) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case e, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			h.dispatch(e)
+		}
+	}
+}
+
+func (h *hubImpl) dispatch(e event.Event) {
+	msg, err := json.Marshal(Message{Type: TypeEvent, Event: &e})
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if c.follows(e) {
+			c.send(msg)
+		}
+	}
+}
+
+func (h *hubImpl) broadcast(m Message) {
+	msg, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		c.send(msg)
+	}
+}
+
+func (h *hubImpl) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		c.close()
+	}
+}
+
+func (h *hubImpl) Serve(conn *websocket.Conn, principal auth.Principal, tenantID string) {
+	c := newClient(conn, principal, tenantID, h.cfg)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.Close()
+		return
+	}
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+		c.close()
+	}()
+
+	go c.writeLoop()
+	c.readLoop()
+}
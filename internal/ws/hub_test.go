@@ -0,0 +1,105 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MidnightHelix/assignment-2/internal/auth"
+	"github.com/MidnightHelix/assignment-2/internal/event"
+	"github.com/MidnightHelix/assignment-2/internal/model"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func startHub(t *testing.T) (*event.Broker, *websocket.Conn) {
+	broker := event.NewBroker(16)
+	hub := NewHub(broker, Config{SendBuffer: 16, MaxSubscriptions: 3, PingInterval: time.Minute, WriteTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go hub.Run(ctx)
+	t.Cleanup(cancel)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.Serve(conn, auth.Principal{Name: "tester"}, "shop-a")
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return broker, conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) Message {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	m := Message{}
+	assert.NoError(t, conn.ReadJSON(&m))
+	return m
+}
+
+func TestHub(t *testing.T) {
+	t.Run("delivers events of followed orders and customers", func(t *testing.T) {
+		broker, conn := startHub(t)
+
+		assert.NoError(t, conn.WriteJSON(Request{Action: ActionSubscribe, RequestID: "1", OrderIDs: []uint64{1}, Customers: []string{"Alice"}}))
+		res := readMessage(t, conn)
+		assert.Equal(t, TypeSubscribed, res.Type)
+		assert.Equal(t, "1", res.RequestID)
+		assert.Equal(t, []uint64{1}, res.OrderIDs)
+		assert.Equal(t, []string{"alice"}, res.Customers)
+
+		broker.Publish(context.Background(), event.New(event.OrderUpdated, model.Order{ID: 2, CustomerName: "bob", TenantID: "shop-a"}))
+		broker.Publish(context.Background(), event.New(event.OrderUpdated, model.Order{ID: 1, CustomerName: "bob", TenantID: "shop-a"}))
+		// the same customer name in another tenant is someone else
+		broker.Publish(context.Background(), event.New(event.OrderCreated, model.Order{ID: 4, CustomerName: "alice", TenantID: "shop-b"}))
+		broker.Publish(context.Background(), event.New(event.OrderCreated, model.Order{ID: 3, CustomerName: "alice", TenantID: "shop-a"}))
+
+		first := readMessage(t, conn)
+		assert.Equal(t, TypeEvent, first.Type)
+		assert.Equal(t, uint64(1), first.Event.OrderID)
+		second := readMessage(t, conn)
+		assert.Equal(t, uint64(3), second.Event.OrderID)
+	})
+
+	t.Run("unsubscribe stops notifications", func(t *testing.T) {
+		broker, conn := startHub(t)
+
+		conn.WriteJSON(Request{Action: ActionSubscribe, OrderIDs: []uint64{1, 2}})
+		readMessage(t, conn)
+		conn.WriteJSON(Request{Action: ActionUnsubscribe, OrderIDs: []uint64{1}})
+		res := readMessage(t, conn)
+		assert.Equal(t, TypeUnsubscribed, res.Type)
+		assert.Equal(t, []uint64{2}, res.OrderIDs)
+
+		broker.Publish(context.Background(), event.New(event.OrderDeleted, model.Order{ID: 1, TenantID: "shop-a"}))
+		broker.Publish(context.Background(), event.New(event.OrderDeleted, model.Order{ID: 2, TenantID: "shop-a"}))
+		assert.Equal(t, uint64(2), readMessage(t, conn).Event.OrderID)
+	})
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		_, conn := startHub(t)
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"action":`))
+		assert.Equal(t, TypeError, readMessage(t, conn).Type)
+
+		conn.WriteJSON(Request{Action: "dance"})
+		assert.Equal(t, TypeError, readMessage(t, conn).Type)
+
+		conn.WriteJSON(Request{Action: ActionSubscribe, OrderIDs: []uint64{1, 2, 3, 4}})
+		assert.Equal(t, TypeError, readMessage(t, conn).Type)
+
+		conn.WriteJSON(Request{Action: ActionPing, RequestID: "p"})
+		pong := readMessage(t, conn)
+		assert.Equal(t, TypePong, pong.Type)
+		assert.Equal(t, "p", pong.RequestID)
+	})
+}
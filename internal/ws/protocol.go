@@ -0,0 +1,43 @@
+package ws
+
+import "github.com/MidnightHelix/assignment-2/internal/event"
+
+// Client actions.
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+	ActionPing        = "ping"
+)
+
+// Server message types.
+const (
+	TypeSubscribed   = "subscribed"
+	TypeUnsubscribed = "unsubscribed"
+	TypeEvent        = "event"
+	TypeError        = "error"
+	TypePong         = "pong"
+	// TypeResync tells clients that events may have been missed and that
+	// they should refetch the orders they follow.
+	TypeResync = "resync"
+)
+
+// Request is a message sent by a client, for example
+//
+//	{"action":"subscribe","request_id":"1","order_ids":[42],"customers":["alice"]}
+type Request struct {
+	Action    string   `json:"action"`
+	RequestID string   `json:"request_id,omitempty"`
+	OrderIDs  []uint64 `json:"order_ids,omitempty"`
+	Customers []string `json:"customers,omitempty"`
+}
+
+// Message is sent by the server. For subscribed and unsubscribed it lists
+// everything the client follows afterwards.
+type Message struct {
+	Type      string       `json:"type"`
+	RequestID string       `json:"request_id,omitempty"`
+	OrderIDs  []uint64     `json:"order_ids,omitempty"`
+	Customers []string     `json:"customers,omitempty"`
+	Event     *event.Event `json:"event,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// DefaultInitialBackoff is the first delay of a Backoff that sets none.
+const DefaultInitialBackoff = 100 * time.Millisecond
+
+// Backoff computes exponentially growing delays with jitter.
+type Backoff struct {
+	// Initial defaults to DefaultInitialBackoff, so that retries never spin.
+	Initial time.Duration
+	// Max below Initial, unset included, keeps every delay at Initial.
+	Max time.Duration
+}
+
+// Duration returns the delay before retry number attempt (starting at 0).
+// The result is picked uniformly from the upper half of the exponential
+// window so that concurrent callers do not retry in lockstep.
+func (b Backoff) Duration(attempt int) time.Duration {
+	if b.Initial <= 0 {
+		b.Initial = DefaultInitialBackoff
+	}
+	if b.Max < b.Initial {
+		b.Max = b.Initial
+	}
+	d := b.Initial
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Retry calls fn until it succeeds or ctx is done, sleeping according to b
+// between attempts. When ctx ends first the last error from fn is returned.
+func Retry(ctx context.Context, b Backoff, fn func(attempt int) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+
+		wait := b.Duration(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt+1, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("gave up after %d attempts: %w", attempt+1, err)
+		case <-timer.C:
+		}
+	}
+}
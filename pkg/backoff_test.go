@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDuration(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+
+	t.Run("grows exponentially", func(t *testing.T) {
+		for attempt, window := range []time.Duration{100, 200, 400, 800} {
+			d := b.Duration(attempt)
+			assert.GreaterOrEqual(t, d, window*time.Millisecond/2)
+			assert.LessOrEqual(t, d, window*time.Millisecond)
+		}
+	})
+
+	t.Run("capped at max", func(t *testing.T) {
+		d := b.Duration(30)
+		assert.GreaterOrEqual(t, d, b.Max/2)
+		assert.LessOrEqual(t, d, b.Max)
+	})
+
+	t.Run("never zero", func(t *testing.T) {
+		for _, b := range []Backoff{{}, {Max: time.Second}, {Initial: time.Second}} {
+			d := b.Duration(3)
+			assert.Greater(t, d, time.Duration(0), "%+v", b)
+			assert.LessOrEqual(t, d, max(b.Max, b.Initial, DefaultInitialBackoff), "%+v", b)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	b := Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond}
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), b, func(int) error {
+			calls++
+			if calls < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up at deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		someErr := errors.New("connection refused")
+		err := Retry(ctx, b, func(int) error { return someErr })
+		assert.ErrorIs(t, err, someErr)
+	})
+}